@@ -0,0 +1,117 @@
+package dem
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// DefaultMaxGrids is the default max number of grids which a directory keeps in memory
+const DefaultMaxGrids = 4
+
+// directoryFile is a DEM file of a directory with the bounds of its grid
+type directoryFile struct {
+	fileName string
+	bounds   Grid // The grid without values
+	load     func(fileName string) (*Grid, error)
+}
+
+// Directory provides the elevation of the DEM files in a local directory and implements the geo.ElevationProvider interface:
+// SRTM .hgt tiles named by their south-west corner (e.g. "N50E008.hgt", see HGTName) and single-band GeoTIFF files (.tif / .tiff, see ReadGeoTIFF).
+// The files are read on demand; the last used MaxGrids grids are kept in memory (an SRTM1 tile has about 50 MB).
+type Directory struct {
+	MaxGrids int // The max number of grids in memory
+
+	files []*directoryFile
+	mutex sync.Mutex
+	grids map[*directoryFile]*Grid
+	used  []*directoryFile // The files of the grids in memory; the last used file is the last element
+}
+
+// NewDirectory returns the provider of the DEM files in the directory; the bounds of the GeoTIFF files are read from their headers
+func NewDirectory(path string) (*Directory, error) {
+	infos, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	d := &Directory{
+		MaxGrids: DefaultMaxGrids,
+		grids:    make(map[*directoryFile]*Grid),
+	}
+	for _, info := range infos {
+		if info.IsDir() {
+			continue
+		}
+		fileName := filepath.Join(path, info.Name())
+		switch strings.ToLower(filepath.Ext(info.Name())) {
+		case ".hgt":
+			south, west, err := parseHGTName(fileName)
+			if err != nil {
+				return nil, err
+			}
+			d.files = append(d.files, &directoryFile{
+				fileName: fileName,
+				bounds:   Grid{Rows: 2, Columns: 2, North: float64(south + 1), West: float64(west), LatitudeSpacing: 1, LongitudeSpacing: 1},
+				load:     LoadHGT,
+			})
+		case ".tif", ".tiff":
+			f, err := os.Open(fileName)
+			if err != nil {
+				return nil, err
+			}
+			bounds, err := readGeoTIFF(f, false)
+			f.Close()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", fileName, err)
+			}
+			d.files = append(d.files, &directoryFile{fileName: fileName, bounds: *bounds, load: LoadGeoTIFF})
+		}
+	}
+	if len(d.files) == 0 {
+		return nil, fmt.Errorf("No DEM files in %s", path)
+	}
+	return d, nil
+}
+
+// grid returns the grid of the file; the grid is read if it is not in memory
+func (d *Directory) grid(file *directoryFile) (*Grid, error) {
+	for index, used := range d.used {
+		if used == file {
+			d.used = append(append(d.used[:index:index], d.used[index+1:]...), file)
+			return d.grids[file], nil
+		}
+	}
+	grid, err := file.load(file.fileName)
+	if err != nil {
+		return nil, err
+	}
+	for len(d.used) > 0 && len(d.used) >= d.MaxGrids {
+		delete(d.grids, d.used[0])
+		d.used = d.used[1:]
+	}
+	d.grids[file] = grid
+	d.used = append(d.used, file)
+	return grid, nil
+}
+
+// Elevation returns the bilinearly interpolated elevation (m) at the latitude / longitude of the first file which has data at the location
+func (d *Directory) Elevation(latitude float64, longitude float64) (float64, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for _, file := range d.files {
+		if !file.bounds.Contains(latitude, longitude) {
+			continue
+		}
+		grid, err := d.grid(file)
+		if err != nil {
+			return 0, err
+		}
+		if elevation, err := grid.Elevation(latitude, longitude); err == nil {
+			return elevation, nil
+		}
+	}
+	return 0, fmt.Errorf("No elevation data at %f, %f", latitude, longitude)
+}
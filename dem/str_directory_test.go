@@ -0,0 +1,68 @@
+package dem
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirectory(t *testing.T) {
+	dir := t.TempDir()
+	tt := newTestTIFF()
+	tt.north, tt.west = 10.4, 20
+	files := map[string][]byte{"N50E008.hgt": testHGT(), "dem.tif": writeTestTIFF(tt), "readme.txt": []byte("Not a DEM")}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d, err := NewDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.MaxGrids = 1
+	testCases := []struct {
+		latitude, longitude float64
+		elevation           float64
+	}{
+		{50.25, 8.25, 1200},
+		{10.25, 20.35, 118.5},
+		{50.75, 8.5, 900},
+	}
+	for _, tc := range testCases {
+		if elevation, err := d.Elevation(tc.latitude, tc.longitude); err != nil || math.Abs(elevation-tc.elevation) > 1e-6 {
+			t.Errorf("Elevation(%v, %v): %f (%v), want %f", tc.latitude, tc.longitude, elevation, err, tc.elevation)
+		}
+		if len(d.used) != 1 || len(d.grids) != 1 {
+			t.Errorf("Elevation(%v, %v): %d grids in memory, want 1", tc.latitude, tc.longitude, len(d.grids))
+		}
+	}
+	for _, location := range [][2]float64{{50.5, 8.5}, {0, 0}} {
+		if _, err := d.Elevation(location[0], location[1]); err == nil {
+			t.Errorf("Elevation(%v, %v): want an error", location[0], location[1])
+		}
+	}
+}
+
+func TestNewDirectoryErrors(t *testing.T) {
+	testCases := map[string]map[string][]byte{
+		"No DEM files":     {"readme.txt": []byte("Not a DEM")},
+		"Invalid HGT name": {"tile.hgt": testHGT()},
+		"Invalid GeoTIFF":  {"dem.tif": []byte("Not a TIFF")},
+	}
+	for name, files := range testCases {
+		dir := t.TempDir()
+		for fileName, data := range files {
+			if err := os.WriteFile(filepath.Join(dir, fileName), data, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := NewDirectory(dir); err == nil {
+			t.Errorf("%s: want an error", name)
+		}
+	}
+	if _, err := NewDirectory(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Missing directory: want an error")
+	}
+}
@@ -0,0 +1,166 @@
+package dem
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Geoid is a geoid model of a global grid of geoid heights, e.g. EGM96 or EGM2008, and implements the geo.GeoidModel interface
+type Geoid struct {
+	Grid Grid // The heights (m) of the geoid above the WGS84 ellipsoid; the grid covers 360 degree of longitude
+}
+
+// GeoidHeight returns the bilinearly interpolated height (m) of the geoid above the WGS84 ellipsoid at the latitude / longitude
+func (g *Geoid) GeoidHeight(latitude float64, longitude float64) (float64, error) {
+	// The longitude is wrapped into the longitudes of the grid, e.g. -10° is 350° of a grid from 0° to 360°
+	longitude = g.Grid.West + math.Mod(math.Mod(longitude-g.Grid.West, 360)+360, 360)
+	return g.Grid.Elevation(latitude, longitude)
+}
+
+// LoadGeoid reads the geoid grid file by its extension: An NGA grid (.grd, e.g. "WW15MGH.GRD" of EGM96; see ReadGeoidGRD)
+// or a GeographicLib grid (.pgm, e.g. "egm96-5.pgm" or "egm2008-2_5.pgm"; see ReadGeoidPGM)
+func LoadGeoid(fileName string) (*Geoid, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".grd":
+		return ReadGeoidGRD(f)
+	case ".pgm":
+		return ReadGeoidPGM(f)
+	}
+	return nil, fmt.Errorf("Unsupported geoid file %s", fileName)
+}
+
+// ReadGeoidGRD reads a geoid grid in the ASCII format of the NGA, e.g. "WW15MGH.GRD" of EGM96 with 15' spacing:
+// The header (south, north, west, east, latitude spacing, longitude spacing in degree) is followed by the geoid heights (m) row by row from north to south
+func ReadGeoidGRD(r io.Reader) (*Geoid, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanWords)
+	var values []float64
+	for scanner.Scan() {
+		value, err := strconv.ParseFloat(scanner.Text(), 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid value %s of the geoid grid", scanner.Text())
+		}
+		values = append(values, value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(values) < 6 {
+		return nil, errors.New("Invalid header of the geoid grid")
+	}
+
+	south, north, west, east, latitudeSpacing, longitudeSpacing := values[0], values[1], values[2], values[3], values[4], values[5]
+	if latitudeSpacing <= 0 || longitudeSpacing <= 0 {
+		return nil, fmt.Errorf("Invalid spacing %f / %f of the geoid grid", latitudeSpacing, longitudeSpacing)
+	}
+	rows := int(math.Round((north-south)/latitudeSpacing)) + 1
+	columns := int(math.Round((east-west)/longitudeSpacing)) + 1
+	heights := values[6:]
+	if rows < 2 || columns < 2 || len(heights) != rows*columns {
+		return nil, fmt.Errorf("Invalid number %d of geoid heights (%d x %d)", len(heights), rows, columns)
+	}
+
+	geoid := &Geoid{Grid: Grid{
+		Rows:             rows,
+		Columns:          columns,
+		North:            north,
+		West:             west,
+		LatitudeSpacing:  latitudeSpacing,
+		LongitudeSpacing: longitudeSpacing,
+		Values:           make([]float32, len(heights)),
+	}}
+	for index, height := range heights {
+		geoid.Grid.Values[index] = float32(height)
+	}
+	return geoid, nil
+}
+
+// ReadGeoidPGM reads a geoid grid in the PGM format of GeographicLib, e.g. "egm96-5.pgm" (5' spacing) or "egm2008-2_5.pgm" (2.5' spacing):
+// 16 bit samples from north to south and from 0° eastwards; the height (m) is "Offset" + "Scale" * sample with the offset and scale of the header comments.
+// The grids with a smaller spacing (e.g. "egm2008-1.pgm") are supported but need several GB of memory.
+func ReadGeoidPGM(r io.Reader) (*Geoid, error) {
+	br := bufio.NewReader(r)
+	offset, scale := math.NaN(), math.NaN()
+
+	// The header: "P5", the width, the height and the max value separated by whitespace; comments (#) until the end of the line
+	var fields []string
+	var field []byte
+	for len(fields) < 4 {
+		c, err := br.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("Invalid header of the geoid grid: %v", err)
+		}
+		switch {
+		case c == '#':
+			comment, err := br.ReadString('\n')
+			if err != nil {
+				return nil, fmt.Errorf("Invalid header of the geoid grid: %v", err)
+			}
+			words := strings.Fields(comment)
+			if len(words) == 2 {
+				if value, err := strconv.ParseFloat(words[1], 64); err == nil {
+					switch words[0] {
+					case "Offset":
+						offset = value
+					case "Scale":
+						scale = value
+					}
+				}
+			}
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if len(field) > 0 {
+				fields = append(fields, string(field))
+				field = nil
+			}
+		default:
+			field = append(field, c)
+		}
+	}
+	if fields[0] != "P5" {
+		return nil, fmt.Errorf("Unsupported PGM format %s", fields[0])
+	}
+	if math.IsNaN(offset) || math.IsNaN(scale) {
+		return nil, errors.New("Geoid grid without offset and scale")
+	}
+	width, errWidth := strconv.Atoi(fields[1])
+	height, errHeight := strconv.Atoi(fields[2])
+	if errWidth != nil || errHeight != nil || width < 2 || height < 2 || fields[3] != "65535" {
+		return nil, fmt.Errorf("Invalid size %s x %s or max value %s of the geoid grid", fields[1], fields[2], fields[3])
+	}
+
+	// The first column (0°) is repeated as the last column (360°) that the grid covers all longitudes
+	geoid := &Geoid{Grid: Grid{
+		Rows:             height,
+		Columns:          width + 1,
+		North:            90,
+		West:             0,
+		LatitudeSpacing:  180 / float64(height-1),
+		LongitudeSpacing: 360 / float64(width),
+		Values:           make([]float32, height*(width+1)),
+	}}
+	row := make([]byte, 2*width)
+	for rowNo := 0; rowNo < height; rowNo++ {
+		if _, err := io.ReadFull(br, row); err != nil {
+			return nil, err
+		}
+		values := geoid.Grid.Values[rowNo*(width+1) : (rowNo+1)*(width+1)]
+		for column := 0; column < width; column++ {
+			values[column] = float32(offset + scale*float64(binary.BigEndian.Uint16(row[2*column:])))
+		}
+		values[width] = values[0]
+	}
+	return geoid, nil
+}
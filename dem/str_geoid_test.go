@@ -0,0 +1,125 @@
+package dem
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testGeoidGRD returns an NGA geoid grid with 90 degree spacing and the height 10 * row + column
+func testGeoidGRD() string {
+	var sb strings.Builder
+	sb.WriteString("-90 90 0 360 90 90\n")
+	for row := 0; row < 3; row++ {
+		for column := 0; column < 5; column++ {
+			fmt.Fprintf(&sb, " %d", 10*row+column)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// testGeoidPGM returns a GeographicLib geoid grid with 90 degree spacing and the height -100 + 0.5 * (10 * row + column)
+func testGeoidPGM() []byte {
+	var buffer bytes.Buffer
+	buffer.WriteString("P5\n# Geoid test\n# Offset -100\n# Scale 0.5\n4 3\n65535\n")
+	for row := 0; row < 3; row++ {
+		for column := 0; column < 4; column++ {
+			binary.Write(&buffer, binary.BigEndian, uint16(10*row+column))
+		}
+	}
+	return buffer.Bytes()
+}
+
+func TestReadGeoidGRD(t *testing.T) {
+	geoid, err := ReadGeoidGRD(strings.NewReader(testGeoidGRD()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		latitude, longitude, height float64
+	}{
+		{45, 45, 5.5},
+		{45, -45, 8.5}, // 315°
+		{-90, 180, 22},
+		{0, 360, 10}, // Wrapped to 0°
+	}
+	for _, tc := range testCases {
+		if height, err := geoid.GeoidHeight(tc.latitude, tc.longitude); err != nil || math.Abs(height-tc.height) > 1e-6 {
+			t.Errorf("GeoidHeight(%v, %v): %f (%v), want %f", tc.latitude, tc.longitude, height, err, tc.height)
+		}
+	}
+
+	for name, grd := range map[string]string{
+		"Header":  "-90 90 0 360",
+		"Spacing": "-90 90 0 360 0 90 1 2",
+		"Size":    "-90 90 0 360 90 90 1 2 3",
+		"Value":   "-90 90 0 360 90 90 x",
+	} {
+		if _, err := ReadGeoidGRD(strings.NewReader(grd)); err == nil {
+			t.Errorf("%s: want an error", name)
+		}
+	}
+}
+
+func TestReadGeoidPGM(t *testing.T) {
+	geoid, err := ReadGeoidPGM(bytes.NewReader(testGeoidPGM()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if grid := geoid.Grid; grid.Rows != 3 || grid.Columns != 5 || grid.LatitudeSpacing != 90 || grid.LongitudeSpacing != 90 {
+		t.Errorf("Grid: %d x %d with %v / %v spacing, want 3 x 5 with 90 / 90", grid.Rows, grid.Columns, grid.LatitudeSpacing, grid.LongitudeSpacing)
+	}
+	testCases := []struct {
+		latitude, longitude, height float64
+	}{
+		{0, 45, -94.75},
+		{0, -45, -94.25}, // Between 270° and the repeated first column
+		{90, 0, -100},
+		{-90, 270, -88.5},
+	}
+	for _, tc := range testCases {
+		if height, err := geoid.GeoidHeight(tc.latitude, tc.longitude); err != nil || math.Abs(height-tc.height) > 1e-6 {
+			t.Errorf("GeoidHeight(%v, %v): %f (%v), want %f", tc.latitude, tc.longitude, height, err, tc.height)
+		}
+	}
+
+	pgm := string(testGeoidPGM())
+	for name, data := range map[string]string{
+		"Format":     strings.Replace(pgm, "P5", "P2", 1),
+		"No offset":  strings.Replace(pgm, "# Offset -100\n", "", 1),
+		"Max value":  strings.Replace(pgm, "65535", "255", 1),
+		"Size":       strings.Replace(pgm, "4 3", "1 3", 1),
+		"Short data": pgm[:len(pgm)-2],
+	} {
+		if _, err := ReadGeoidPGM(strings.NewReader(data)); err == nil {
+			t.Errorf("%s: want an error", name)
+		}
+	}
+}
+
+func TestLoadGeoid(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string][]byte{"WW15MGH.GRD": []byte(testGeoidGRD()), "egm96-5.pgm": testGeoidPGM(), "geoid.txt": []byte(testGeoidGRD())}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if geoid, err := LoadGeoid(filepath.Join(dir, "WW15MGH.GRD")); err != nil || geoid.Grid.Columns != 5 {
+		t.Errorf("LoadGeoid(WW15MGH.GRD): %v, want a grid of 5 columns", err)
+	}
+	if geoid, err := LoadGeoid(filepath.Join(dir, "egm96-5.pgm")); err != nil || geoid.Grid.Columns != 5 {
+		t.Errorf("LoadGeoid(egm96-5.pgm): %v, want a grid of 5 columns", err)
+	}
+	for _, name := range []string{"geoid.txt", "missing.pgm"} {
+		if _, err := LoadGeoid(filepath.Join(dir, name)); err == nil {
+			t.Errorf("LoadGeoid(%s): want an error", name)
+		}
+	}
+}
@@ -0,0 +1,341 @@
+package dem
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// The TIFF / GeoTIFF tags which are used to read a DEM
+const (
+	tiffImageWidth      = 256
+	tiffImageLength     = 257
+	tiffBitsPerSample   = 258
+	tiffCompression     = 259
+	tiffStripOffsets    = 273
+	tiffSamplesPerPixel = 277
+	tiffRowsPerStrip    = 278
+	tiffStripByteCounts = 279
+	tiffPredictor       = 317
+	tiffTileWidth       = 322
+	tiffTileLength      = 323
+	tiffTileOffsets     = 324
+	tiffTileByteCounts  = 325
+	tiffSampleFormat    = 339
+	tiffModelPixelScale = 33550
+	tiffModelTiepoint   = 33922
+	tiffGeoKeyDirectory = 34735
+	tiffGDALNoData      = 42113
+
+	geoKeyModelType  = 1024 // 1 == projected, 2 == geographic
+	geoKeyRasterType = 1025 // 1 == pixel is area, 2 == pixel is point
+)
+
+// tiffTypeSizes are the sizes (bytes) of the TIFF field types
+var tiffTypeSizes = map[uint16]uint32{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8}
+
+// tiffReader reads the fields of a TIFF file
+type tiffReader struct {
+	r      io.ReaderAt
+	order  binary.ByteOrder
+	fields map[uint16][]byte // The raw data of the fields by tag
+	types  map[uint16]uint16 // The field type by tag
+}
+
+// readIFD reads the fields of the first image file directory (IFD)
+func (tr *tiffReader) readIFD() error {
+	header := make([]byte, 8)
+	if _, err := tr.r.ReadAt(header, 0); err != nil {
+		return err
+	}
+	switch string(header[:2]) {
+	case "II":
+		tr.order = binary.LittleEndian
+	case "MM":
+		tr.order = binary.BigEndian
+	default:
+		return errors.New("Not a TIFF file")
+	}
+	if version := tr.order.Uint16(header[2:]); version != 42 {
+		return fmt.Errorf("Unsupported TIFF version %d (BigTIFF is not supported)", version)
+	}
+
+	offset := int64(tr.order.Uint32(header[4:]))
+	count := make([]byte, 2)
+	if _, err := tr.r.ReadAt(count, offset); err != nil {
+		return err
+	}
+	entries := make([]byte, 12*int(tr.order.Uint16(count)))
+	if _, err := tr.r.ReadAt(entries, offset+2); err != nil {
+		return err
+	}
+	tr.fields = make(map[uint16][]byte)
+	tr.types = make(map[uint16]uint16)
+	for entry := entries; len(entry) >= 12; entry = entry[12:] {
+		tag, fieldType := tr.order.Uint16(entry), tr.order.Uint16(entry[2:])
+		size, ok := tiffTypeSizes[fieldType]
+		if !ok {
+			continue
+		}
+		data := make([]byte, size*tr.order.Uint32(entry[4:]))
+		if len(data) <= 4 {
+			copy(data, entry[8:])
+		} else if _, err := tr.r.ReadAt(data, int64(tr.order.Uint32(entry[8:]))); err != nil {
+			return err
+		}
+		tr.fields[tag] = data
+		tr.types[tag] = fieldType
+	}
+	return nil
+}
+
+// values returns the numeric values of the field; nil if the image does not have the field
+func (tr *tiffReader) values(tag uint16) []float64 {
+	data, ok := tr.fields[tag]
+	if !ok {
+		return nil
+	}
+	fieldType := tr.types[tag]
+	size := int(tiffTypeSizes[fieldType])
+	var values []float64
+	for ; len(data) >= size; data = data[size:] {
+		switch fieldType {
+		case 1, 7:
+			values = append(values, float64(data[0]))
+		case 6:
+			values = append(values, float64(int8(data[0])))
+		case 3:
+			values = append(values, float64(tr.order.Uint16(data)))
+		case 8:
+			values = append(values, float64(int16(tr.order.Uint16(data))))
+		case 4:
+			values = append(values, float64(tr.order.Uint32(data)))
+		case 9:
+			values = append(values, float64(int32(tr.order.Uint32(data))))
+		case 5:
+			values = append(values, float64(tr.order.Uint32(data))/float64(tr.order.Uint32(data[4:])))
+		case 10:
+			values = append(values, float64(int32(tr.order.Uint32(data)))/float64(int32(tr.order.Uint32(data[4:]))))
+		case 11:
+			values = append(values, float64(math.Float32frombits(tr.order.Uint32(data))))
+		case 12:
+			values = append(values, math.Float64frombits(tr.order.Uint64(data)))
+		default:
+			return nil
+		}
+	}
+	return values
+}
+
+// value returns the first numeric value of the field or the default value
+func (tr *tiffReader) value(tag uint16, defaultValue int) int {
+	if values := tr.values(tag); len(values) > 0 {
+		return int(values[0])
+	}
+	return defaultValue
+}
+
+// geoKey returns the value of the GeoTIFF key or the default value
+func (tr *tiffReader) geoKey(key int, defaultValue int) int {
+	directory := tr.values(tiffGeoKeyDirectory)
+	// The header (version, revision, minor revision, number of keys) is followed by the keys (key, location, count, value)
+	for index := 4; index+3 < len(directory); index += 4 {
+		if int(directory[index]) == key && directory[index+1] == 0 {
+			return int(directory[index+3])
+		}
+	}
+	return defaultValue
+}
+
+// ReadGeoTIFF reads a single-band GeoTIFF DEM in geographic coordinates (WGS84), e.g. of SRTM, ASTER or Copernicus:
+// The samples are 8 / 16 / 32 bit integers or 32 / 64 bit floats in strips or tiles, uncompressed or compressed with Deflate (optionally with the horizontal predictor);
+// the GDAL nodata value is a void
+func ReadGeoTIFF(r io.ReaderAt) (*Grid, error) {
+	return readGeoTIFF(r, true)
+}
+
+// LoadGeoTIFF reads the GeoTIFF file (see ReadGeoTIFF)
+func LoadGeoTIFF(fileName string) (*Grid, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadGeoTIFF(f)
+}
+
+// readGeoTIFF reads the GeoTIFF; the values are only read with withValues, e.g. to get the bounds of the grid
+func readGeoTIFF(r io.ReaderAt, withValues bool) (*Grid, error) {
+	tr := &tiffReader{r: r}
+	if err := tr.readIFD(); err != nil {
+		return nil, err
+	}
+
+	width, height := tr.value(tiffImageWidth, 0), tr.value(tiffImageLength, 0)
+	if width < 2 || height < 2 {
+		return nil, fmt.Errorf("Invalid image size %d x %d", width, height)
+	}
+	if samplesPerPixel := tr.value(tiffSamplesPerPixel, 1); samplesPerPixel != 1 {
+		return nil, fmt.Errorf("Unsupported number of bands %d", samplesPerPixel)
+	}
+	if modelType := tr.geoKey(geoKeyModelType, 2); modelType != 2 {
+		return nil, errors.New("Unsupported projected GeoTIFF; only geographic coordinates are supported")
+	}
+	scale, tiepoint := tr.values(tiffModelPixelScale), tr.values(tiffModelTiepoint)
+	if len(scale) < 2 || len(tiepoint) < 6 || scale[0] <= 0 || scale[1] <= 0 {
+		return nil, errors.New("GeoTIFF without pixel scale and tiepoint")
+	}
+
+	// The tiepoint is the raster position (i, j) of the model position (x == longitude, y == latitude)
+	grid := &Grid{
+		Rows:             height,
+		Columns:          width,
+		North:            tiepoint[4] + tiepoint[1]*scale[1],
+		West:             tiepoint[3] - tiepoint[0]*scale[0],
+		LatitudeSpacing:  scale[1],
+		LongitudeSpacing: scale[0],
+	}
+	if tr.geoKey(geoKeyRasterType, 1) == 1 {
+		// Pixel is area: The position is the upper left corner of the pixel instead of its center
+		grid.North -= scale[1] / 2
+		grid.West += scale[0] / 2
+	}
+	if !withValues {
+		return grid, nil
+	}
+
+	if err := tr.readValues(grid); err != nil {
+		return nil, err
+	}
+	return grid, nil
+}
+
+// readValues reads the samples of the strips or tiles into the grid
+func (tr *tiffReader) readValues(grid *Grid) error {
+	bits, format := tr.value(tiffBitsPerSample, 1), tr.value(tiffSampleFormat, 1)
+	switch {
+	case (format == 1 || format == 2) && (bits == 8 || bits == 16 || bits == 32):
+	case format == 3 && (bits == 32 || bits == 64):
+	default:
+		return fmt.Errorf("Unsupported sample format %d with %d bits", format, bits)
+	}
+	compression, predictor := tr.value(tiffCompression, 1), tr.value(tiffPredictor, 1)
+	if compression != 1 && compression != 8 && compression != 32946 {
+		return fmt.Errorf("Unsupported compression %d", compression)
+	}
+	if predictor != 1 && !(predictor == 2 && format != 3) {
+		return fmt.Errorf("Unsupported predictor %d", predictor)
+	}
+	noData := math.NaN()
+	if data, ok := tr.fields[tiffGDALNoData]; ok {
+		if value, err := strconv.ParseFloat(strings.Trim(string(data), "\x00 "), 64); err == nil {
+			noData = value
+		}
+	}
+
+	// The chunks are either strips (all columns of rowsPerStrip rows) or tiles
+	chunkWidth, chunkHeight := grid.Columns, tr.value(tiffRowsPerStrip, grid.Rows)
+	offsets, byteCounts := tr.values(tiffStripOffsets), tr.values(tiffStripByteCounts)
+	if _, ok := tr.fields[tiffTileOffsets]; ok {
+		chunkWidth, chunkHeight = tr.value(tiffTileWidth, 0), tr.value(tiffTileLength, 0)
+		offsets, byteCounts = tr.values(tiffTileOffsets), tr.values(tiffTileByteCounts)
+	}
+	if chunkWidth <= 0 || chunkHeight <= 0 || len(offsets) == 0 || len(offsets) != len(byteCounts) {
+		return errors.New("Invalid strips or tiles")
+	}
+	chunksAcross := (grid.Columns + chunkWidth - 1) / chunkWidth
+
+	grid.Values = make([]float32, grid.Rows*grid.Columns)
+	for index := range grid.Values {
+		grid.Values[index] = float32(math.NaN())
+	}
+	bytesPerSample := bits / 8
+	raw := make([]uint64, chunkWidth)
+	for chunk := range offsets {
+		data := make([]byte, int(byteCounts[chunk]))
+		if _, err := tr.r.ReadAt(data, int64(offsets[chunk])); err != nil {
+			return err
+		}
+		if compression != 1 {
+			zr, err := zlib.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return err
+			}
+			data, err = ioutil.ReadAll(zr)
+			if err != nil {
+				return err
+			}
+		}
+
+		firstRow, firstColumn := (chunk/chunksAcross)*chunkHeight, (chunk%chunksAcross)*chunkWidth
+		for chunkRow := 0; chunkRow < chunkHeight; chunkRow++ {
+			row := firstRow + chunkRow
+			rowData := data[min(len(data), chunkRow*chunkWidth*bytesPerSample):]
+			if row >= grid.Rows || len(rowData) < chunkWidth*bytesPerSample {
+				break
+			}
+			for column := range raw {
+				sample := rowData[column*bytesPerSample:]
+				switch bits {
+				case 8:
+					raw[column] = uint64(sample[0])
+				case 16:
+					raw[column] = uint64(tr.order.Uint16(sample))
+				case 32:
+					raw[column] = uint64(tr.order.Uint32(sample))
+				case 64:
+					raw[column] = tr.order.Uint64(sample)
+				}
+				if predictor == 2 && column > 0 {
+					// Horizontal differencing: The sample is the difference to the previous sample (modulo the sample size)
+					raw[column] = (raw[column] + raw[column-1]) & (1<<uint(bits) - 1)
+				}
+			}
+			for chunkColumn, value := range raw {
+				column := firstColumn + chunkColumn
+				if column >= grid.Columns {
+					break
+				}
+				elevation := sampleValue(value, bits, format)
+				if elevation == noData {
+					elevation = math.NaN()
+				}
+				grid.Values[row*grid.Columns+column] = float32(elevation)
+			}
+		}
+	}
+	return nil
+}
+
+// sampleValue returns the value of the raw sample with the size (bits) and the TIFF sample format (1 == unsigned, 2 == signed integer, 3 == float)
+func sampleValue(raw uint64, bits int, format int) float64 {
+	switch {
+	case format == 3 && bits == 32:
+		return float64(math.Float32frombits(uint32(raw)))
+	case format == 3:
+		return math.Float64frombits(raw)
+	case format == 2 && bits == 8:
+		return float64(int8(raw))
+	case format == 2 && bits == 16:
+		return float64(int16(raw))
+	case format == 2:
+		return float64(int32(raw))
+	}
+	return float64(raw)
+}
+
+// min returns the smaller integer
+func min(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
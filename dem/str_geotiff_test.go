@@ -0,0 +1,297 @@
+package dem
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"math"
+	"sort"
+	"testing"
+)
+
+// testTIFF defines a GeoTIFF DEM written by writeTestTIFF
+type testTIFF struct {
+	order        binary.ByteOrder
+	width        int
+	height       int
+	bits         int
+	format       int     // 1 == unsigned, 2 == signed integer, 3 == float
+	deflate      bool    // Compress the chunks with Deflate
+	predictor    bool    // Use the horizontal predictor
+	tileSize     int     // The width / height of the tiles; 0 writes strips of 2 rows
+	noData       string  // The GDAL nodata value
+	pixelIsPoint bool    // The raster type
+	north        float64 // The latitude of the center of the north-west pixel
+	west         float64 // The longitude of the center of the north-west pixel
+	spacing      float64 // The latitude / longitude spacing (degree)
+	value        func(row int, column int) float64
+}
+
+// sample returns the bytes of the value
+func (tt *testTIFF) sample(value float64) []byte {
+	data := make([]byte, tt.bits/8)
+	var raw uint64
+	switch {
+	case tt.format == 3 && tt.bits == 32:
+		raw = uint64(math.Float32bits(float32(value)))
+	case tt.format == 3:
+		raw = math.Float64bits(value)
+	default:
+		raw = uint64(int64(value))
+	}
+	switch tt.bits {
+	case 8:
+		data[0] = byte(raw)
+	case 16:
+		tt.order.PutUint16(data, uint16(raw))
+	case 32:
+		tt.order.PutUint32(data, uint32(raw))
+	case 64:
+		tt.order.PutUint64(data, raw)
+	}
+	return data
+}
+
+// chunk returns the bytes of the chunk of the rows / columns; samples outside of the image are 0
+func (tt *testTIFF) chunk(firstRow int, firstColumn int, rows int, columns int) []byte {
+	var data []byte
+	mask := uint64(1)<<uint(tt.bits) - 1
+	for row := firstRow; row < firstRow+rows; row++ {
+		var previous uint64
+		for column := firstColumn; column < firstColumn+columns; column++ {
+			value := 0.0
+			if row < tt.height && column < tt.width {
+				value = tt.value(row, column)
+			}
+			sample := tt.sample(value)
+			if tt.predictor {
+				// Horizontal differencing of the integer samples
+				raw := uint64(int64(value)) & mask
+				difference := tt.sample(float64(int64((raw - previous) & mask)))
+				previous = raw
+				sample = difference
+			}
+			data = append(data, sample...)
+		}
+	}
+	if tt.deflate {
+		var buffer bytes.Buffer
+		zw := zlib.NewWriter(&buffer)
+		zw.Write(data)
+		zw.Close()
+		data = buffer.Bytes()
+	}
+	return data
+}
+
+// writeTestTIFF returns the GeoTIFF
+func writeTestTIFF(tt *testTIFF) []byte {
+	type field struct {
+		fieldType uint16
+		values    []uint32  // Of the integer types
+		doubles   []float64 // Of the type 12 (double)
+		ascii     string
+	}
+	fields := map[uint16]field{
+		tiffImageWidth:      {fieldType: 4, values: []uint32{uint32(tt.width)}},
+		tiffImageLength:     {fieldType: 4, values: []uint32{uint32(tt.height)}},
+		tiffBitsPerSample:   {fieldType: 3, values: []uint32{uint32(tt.bits)}},
+		tiffSampleFormat:    {fieldType: 3, values: []uint32{uint32(tt.format)}},
+		tiffSamplesPerPixel: {fieldType: 3, values: []uint32{1}},
+		tiffCompression:     {fieldType: 3, values: []uint32{1}},
+		tiffModelPixelScale: {fieldType: 12, doubles: []float64{tt.spacing, tt.spacing, 0}},
+	}
+	if tt.deflate {
+		fields[tiffCompression] = field{fieldType: 3, values: []uint32{8}}
+	}
+	if tt.predictor {
+		fields[tiffPredictor] = field{fieldType: 3, values: []uint32{2}}
+	}
+	if tt.noData != "" {
+		fields[tiffGDALNoData] = field{fieldType: 2, ascii: tt.noData + "\x00"}
+	}
+	rasterType := uint32(1)
+	north, west := tt.north+tt.spacing/2, tt.west-tt.spacing/2
+	if tt.pixelIsPoint {
+		rasterType, north, west = 2, tt.north, tt.west
+	}
+	fields[tiffModelTiepoint] = field{fieldType: 12, doubles: []float64{0, 0, 0, west, north, 0}}
+	fields[tiffGeoKeyDirectory] = field{fieldType: 3, values: []uint32{1, 1, 0, 2, geoKeyModelType, 0, 1, 2, geoKeyRasterType, 0, 1, rasterType}}
+
+	// The chunks follow the 8 byte header
+	var chunks [][]byte
+	offsetTag, countTag := uint16(tiffStripOffsets), uint16(tiffStripByteCounts)
+	if tt.tileSize > 0 {
+		offsetTag, countTag = tiffTileOffsets, tiffTileByteCounts
+		fields[tiffTileWidth] = field{fieldType: 3, values: []uint32{uint32(tt.tileSize)}}
+		fields[tiffTileLength] = field{fieldType: 3, values: []uint32{uint32(tt.tileSize)}}
+		for row := 0; row < tt.height; row += tt.tileSize {
+			for column := 0; column < tt.width; column += tt.tileSize {
+				chunks = append(chunks, tt.chunk(row, column, tt.tileSize, tt.tileSize))
+			}
+		}
+	} else {
+		fields[tiffRowsPerStrip] = field{fieldType: 3, values: []uint32{2}}
+		for row := 0; row < tt.height; row += 2 {
+			chunks = append(chunks, tt.chunk(row, 0, int(math.Min(2, float64(tt.height-row))), tt.width))
+		}
+	}
+	var data bytes.Buffer
+	data.Write(make([]byte, 8))
+	var offsets, counts []uint32
+	for _, chunk := range chunks {
+		offsets = append(offsets, uint32(data.Len()))
+		counts = append(counts, uint32(len(chunk)))
+		data.Write(chunk)
+	}
+	fields[offsetTag] = field{fieldType: 4, values: offsets}
+	fields[countTag] = field{fieldType: 4, values: counts}
+
+	// The IFD with the tags in ascending order; values longer than 4 bytes follow the IFD
+	var tags []int
+	for tag := range fields {
+		tags = append(tags, int(tag))
+	}
+	sort.Ints(tags)
+	ifdOffset := data.Len()
+	valuesOffset := ifdOffset + 2 + 12*len(tags) + 4
+	var ifd, values bytes.Buffer
+	binary.Write(&ifd, tt.order, uint16(len(tags)))
+	for _, tag := range tags {
+		f := fields[uint16(tag)]
+		var buffer bytes.Buffer
+		count := len(f.values)
+		switch f.fieldType {
+		case 2:
+			buffer.WriteString(f.ascii)
+			count = len(f.ascii)
+		case 3:
+			for _, value := range f.values {
+				binary.Write(&buffer, tt.order, uint16(value))
+			}
+		case 4:
+			binary.Write(&buffer, tt.order, f.values)
+		case 12:
+			binary.Write(&buffer, tt.order, f.doubles)
+			count = len(f.doubles)
+		}
+		raw := buffer.Bytes()
+		binary.Write(&ifd, tt.order, uint16(tag))
+		binary.Write(&ifd, tt.order, f.fieldType)
+		binary.Write(&ifd, tt.order, uint32(count))
+		if len(raw) <= 4 {
+			ifd.Write(append(raw, make([]byte, 4-len(raw))...))
+		} else {
+			binary.Write(&ifd, tt.order, uint32(valuesOffset+values.Len()))
+			values.Write(raw)
+		}
+	}
+	binary.Write(&ifd, tt.order, uint32(0))
+
+	result := data.Bytes()
+	if tt.order == binary.LittleEndian {
+		copy(result, "II")
+	} else {
+		copy(result, "MM")
+	}
+	tt.order.PutUint16(result[2:], 42)
+	tt.order.PutUint32(result[4:], uint32(ifdOffset))
+	return append(append(result, ifd.Bytes()...), values.Bytes()...)
+}
+
+// newTestTIFF returns a GeoTIFF of 5 x 7 pixels with 0.1 degree spacing from 50.4 N / 8 E and the elevation 100 + 10 * row + column
+func newTestTIFF() *testTIFF {
+	return &testTIFF{
+		order:   binary.LittleEndian,
+		width:   7,
+		height:  5,
+		bits:    16,
+		format:  2,
+		north:   50.4,
+		west:    8,
+		spacing: 0.1,
+		value:   func(row int, column int) float64 { return 100 + 10*float64(row) + float64(column) },
+	}
+}
+
+func TestReadGeoTIFF(t *testing.T) {
+	testCases := map[string]func(tt *testTIFF){
+		"Int16":                 func(tt *testTIFF) {},
+		"Big-endian":            func(tt *testTIFF) { tt.order = binary.BigEndian },
+		"UInt8":                 func(tt *testTIFF) { tt.bits, tt.format = 8, 1 },
+		"Int32":                 func(tt *testTIFF) { tt.bits = 32 },
+		"Float32":               func(tt *testTIFF) { tt.bits, tt.format = 32, 3 },
+		"Float64":               func(tt *testTIFF) { tt.bits, tt.format = 64, 3 },
+		"Deflate":               func(tt *testTIFF) { tt.deflate = true },
+		"Deflate and predictor": func(tt *testTIFF) { tt.deflate, tt.predictor = true, true },
+		"Tiles":                 func(tt *testTIFF) { tt.tileSize = 16 },
+		"Deflate tiles":         func(tt *testTIFF) { tt.tileSize, tt.deflate = 16, true },
+		"Pixel is point":        func(tt *testTIFF) { tt.pixelIsPoint = true },
+	}
+	for name, modify := range testCases {
+		tt := newTestTIFF()
+		modify(tt)
+		grid, err := ReadGeoTIFF(bytes.NewReader(writeTestTIFF(tt)))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if grid.Rows != 5 || grid.Columns != 7 || math.Abs(grid.North-50.4) > 1e-9 || math.Abs(grid.West-8) > 1e-9 || math.Abs(grid.East()-8.6) > 1e-9 || math.Abs(grid.South()-50) > 1e-9 {
+			t.Errorf("%s: %d x %d / %v - %v / %v - %v, want 5 x 7 / 50 - 50.4 / 8 - 8.6", name, grid.Rows, grid.Columns, grid.South(), grid.North, grid.West, grid.East())
+			continue
+		}
+		for index, value := range grid.Values {
+			if want := tt.value(index/7, index%7); float64(value) != want {
+				t.Errorf("%s: Value %d: %v, want %v", name, index, value, want)
+				break
+			}
+		}
+		// Between the rows 1 / 2 and the columns 3 / 4
+		if elevation, err := grid.Elevation(50.25, 8.35); err != nil || math.Abs(elevation-118.5) > 1e-6 {
+			t.Errorf("%s: Elevation: %f (%v), want 118.5", name, elevation, err)
+		}
+	}
+}
+
+func TestReadGeoTIFFNoData(t *testing.T) {
+	tt := newTestTIFF()
+	tt.noData = "-9999"
+	tt.value = func(row int, column int) float64 {
+		if row == 2 && column == 3 {
+			return -9999
+		}
+		return 100
+	}
+	grid, err := ReadGeoTIFF(bytes.NewReader(writeTestTIFF(tt)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value := grid.Values[2*7+3]; !math.IsNaN(float64(value)) {
+		t.Errorf("NoData: %v, want NaN", value)
+	}
+	// The void is skipped by the interpolation
+	if elevation, err := grid.Elevation(50.25, 8.35); err != nil || math.Abs(elevation-100) > 1e-6 {
+		t.Errorf("Elevation next to the void: %f (%v), want 100", elevation, err)
+	}
+}
+
+func TestReadGeoTIFFErrors(t *testing.T) {
+	testCases := map[string][]byte{
+		"Not a TIFF": []byte("PK\x03\x04 not a tiff"),
+		"BigTIFF":    {'I', 'I', 43, 0, 8, 0, 0, 0},
+	}
+	tt := newTestTIFF()
+	tt.bits = 12
+	testCases["Bits"] = writeTestTIFF(&testTIFF{order: tt.order, width: 7, height: 5, bits: 16, format: 3, north: 50, west: 8, spacing: 0.1, value: tt.value})
+	tt = newTestTIFF()
+	tt.width = 1
+	testCases["Size"] = writeTestTIFF(tt)
+	tt = newTestTIFF()
+	tt.spacing = 0
+	testCases["Scale"] = writeTestTIFF(tt)
+	for name, data := range testCases {
+		if _, err := ReadGeoTIFF(bytes.NewReader(data)); err == nil {
+			t.Errorf("%s: want an error", name)
+		}
+	}
+}
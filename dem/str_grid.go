@@ -0,0 +1,71 @@
+// Package dem reads digital elevation models (DEM) from local files, e.g. SRTM .hgt tiles and GeoTIFF files, and provides the terrain elevation of locations (see geo.ElevationProvider)
+package dem
+
+import (
+	"errors"
+	"math"
+)
+
+// Grid is a raster of elevations with equally spaced samples in latitude / longitude (WGS84); the samples are in rows from north to south and the first sample is the north-west sample
+type Grid struct {
+	Rows             int
+	Columns          int
+	North            float64   // The latitude of the first row
+	West             float64   // The longitude of the first column
+	LatitudeSpacing  float64   // The latitude difference (degree) of two rows
+	LongitudeSpacing float64   // The longitude difference (degree) of two columns
+	Values           []float32 // The elevations (m) row by row; voids (no data) are NaN
+}
+
+// South returns the latitude of the last row
+func (g *Grid) South() float64 {
+	return g.North - float64(g.Rows-1)*g.LatitudeSpacing
+}
+
+// East returns the longitude of the last column
+func (g *Grid) East() float64 {
+	return g.West + float64(g.Columns-1)*g.LongitudeSpacing
+}
+
+// Contains returns if the latitude / longitude is inside the grid
+func (g *Grid) Contains(latitude float64, longitude float64) bool {
+	return latitude >= g.South() && latitude <= g.North && longitude >= g.West && longitude <= g.East()
+}
+
+// Elevation returns the elevation (m) at the latitude / longitude bilinearly interpolated from the four surrounding samples; voids are left out of the interpolation
+func (g *Grid) Elevation(latitude float64, longitude float64) (float64, error) {
+	if g.Rows < 2 || g.Columns < 2 || len(g.Values) != g.Rows*g.Columns {
+		return 0, errors.New("Invalid grid")
+	}
+	if !g.Contains(latitude, longitude) {
+		return 0, errors.New("Location is outside of the grid")
+	}
+	y := (g.North - latitude) / g.LatitudeSpacing
+	x := (longitude - g.West) / g.LongitudeSpacing
+	row := int(math.Min(math.Floor(y), float64(g.Rows-2)))
+	column := int(math.Min(math.Floor(x), float64(g.Columns-2)))
+	dy, dx := y-float64(row), x-float64(column)
+
+	var sum, weightSum float64
+	for _, sample := range []struct {
+		row    int
+		column int
+		weight float64
+	}{
+		{row, column, (1 - dy) * (1 - dx)},
+		{row, column + 1, (1 - dy) * dx},
+		{row + 1, column, dy * (1 - dx)},
+		{row + 1, column + 1, dy * dx},
+	} {
+		value := float64(g.Values[sample.row*g.Columns+sample.column])
+		if sample.weight == 0 || math.IsNaN(value) {
+			continue
+		}
+		sum += sample.weight * value
+		weightSum += sample.weight
+	}
+	if weightSum == 0 {
+		return 0, errors.New("No elevation data (void) at the location")
+	}
+	return sum / weightSum, nil
+}
@@ -0,0 +1,108 @@
+package dem
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// SRTM1Size is the number of rows / columns of an SRTM1 tile (1 arc second)
+	SRTM1Size = 3601
+	// SRTM3Size is the number of rows / columns of an SRTM3 tile (3 arc seconds)
+	SRTM3Size = 1201
+
+	hgtVoid = -32768 // The value of a void sample
+)
+
+// HGTName returns the name of the .hgt tile which contains the latitude / longitude; the name is the south-west corner of the tile, e.g. "N50E008.hgt"
+func HGTName(latitude float64, longitude float64) string {
+	south, west := int(math.Floor(latitude)), int(math.Floor(longitude))
+	northSouth, eastWest := 'N', 'E'
+	if south < 0 {
+		northSouth, south = 'S', -south
+	}
+	if west < 0 {
+		eastWest, west = 'W', -west
+	}
+	return fmt.Sprintf("%c%02d%c%03d.hgt", northSouth, south, eastWest, west)
+}
+
+// parseHGTName returns the latitude / longitude of the south-west corner of the tile by the file name, e.g. "N50E008.hgt"
+func parseHGTName(fileName string) (int, int, error) {
+	name := strings.ToUpper(filepath.Base(fileName))
+	var northSouth, eastWest byte
+	var south, west int
+	if _, err := fmt.Sscanf(name, "%c%2d%c%3d.HGT", &northSouth, &south, &eastWest, &west); err != nil {
+		return 0, 0, fmt.Errorf("Invalid name of the hgt file %s", fileName)
+	}
+	switch {
+	case northSouth == 'S':
+		south = -south
+	case northSouth != 'N':
+		return 0, 0, fmt.Errorf("Invalid name of the hgt file %s", fileName)
+	}
+	switch {
+	case eastWest == 'W':
+		west = -west
+	case eastWest != 'E':
+		return 0, 0, fmt.Errorf("Invalid name of the hgt file %s", fileName)
+	}
+	return south, west, nil
+}
+
+// ReadHGT reads an SRTM .hgt tile with the south-west corner at the latitude / longitude:
+// SRTM1 (3601 x 3601) or SRTM3 (1201 x 1201) big-endian 16 bit samples from north to south; the samples of the border overlap with the neighbouring tiles
+func ReadHGT(r io.Reader, south int, west int) (*Grid, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var size int
+	switch len(data) {
+	case SRTM1Size * SRTM1Size * 2:
+		size = SRTM1Size
+	case SRTM3Size * SRTM3Size * 2:
+		size = SRTM3Size
+	default:
+		return nil, fmt.Errorf("Invalid size %d of the hgt tile", len(data))
+	}
+
+	grid := &Grid{
+		Rows:             size,
+		Columns:          size,
+		North:            float64(south + 1),
+		West:             float64(west),
+		LatitudeSpacing:  1 / float64(size-1),
+		LongitudeSpacing: 1 / float64(size-1),
+		Values:           make([]float32, size*size),
+	}
+	for index := range grid.Values {
+		value := int16(binary.BigEndian.Uint16(data[index*2:]))
+		if value == hgtVoid {
+			grid.Values[index] = float32(math.NaN())
+		} else {
+			grid.Values[index] = float32(value)
+		}
+	}
+	return grid, nil
+}
+
+// LoadHGT reads the .hgt file; the south-west corner of the tile is given by the file name, e.g. "N50E008.hgt"
+func LoadHGT(fileName string) (*Grid, error) {
+	south, west, err := parseHGTName(fileName)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadHGT(f, south, west)
+}
@@ -0,0 +1,104 @@
+package dem
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testHGT returns an SRTM3 tile with the elevation row + column (the row from north to south) and a void at the row 600, column 600
+func testHGT() []byte {
+	data := make([]byte, SRTM3Size*SRTM3Size*2)
+	for row := 0; row < SRTM3Size; row++ {
+		for column := 0; column < SRTM3Size; column++ {
+			value := int16(row + column)
+			if row == 600 && column == 600 {
+				value = hgtVoid
+			}
+			binary.BigEndian.PutUint16(data[(row*SRTM3Size+column)*2:], uint16(value))
+		}
+	}
+	return data
+}
+
+func TestHGTName(t *testing.T) {
+	testCases := []struct {
+		latitude, longitude float64
+		name                string
+	}{
+		{50.5, 8.5, "N50E008.hgt"},
+		{-0.5, -0.5, "S01W001.hgt"},
+		{-33.9, 151.2, "S34E151.hgt"},
+		{19.7, -155.1, "N19W156.hgt"},
+	}
+	for _, tc := range testCases {
+		name := HGTName(tc.latitude, tc.longitude)
+		if name != tc.name {
+			t.Errorf("HGTName(%v, %v): %s, want %s", tc.latitude, tc.longitude, name, tc.name)
+		}
+		south, west, err := parseHGTName(filepath.Join("tiles", name))
+		if err != nil || south != int(math.Floor(tc.latitude)) || west != int(math.Floor(tc.longitude)) {
+			t.Errorf("parseHGTName(%s): %d, %d (%v)", name, south, west, err)
+		}
+	}
+	for _, name := range []string{"X50E008.hgt", "N50E008.tif", "N50.hgt"} {
+		if _, _, err := parseHGTName(name); err == nil {
+			t.Errorf("parseHGTName(%s): want an error", name)
+		}
+	}
+}
+
+func TestReadHGT(t *testing.T) {
+	grid, err := ReadHGT(bytes.NewReader(testHGT()), 50, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if grid.Rows != SRTM3Size || grid.North != 51 || grid.West != 8 || grid.South() != 50 || grid.East() != 9 {
+		t.Errorf("ReadHGT: %d rows / %v - %v / %v - %v, want 1201 / 50 - 51 / 8 - 9", grid.Rows, grid.South(), grid.North, grid.West, grid.East())
+	}
+	step := 1.0 / 1200
+	testCases := []struct {
+		latitude, longitude, elevation float64
+	}{
+		{51, 8, 0},
+		{50, 9, 2400},
+		{51 - 10*step, 8 + 20*step, 30},
+		{51 - 10.5*step, 8 + 20.25*step, 30.75}, // Interpolated
+		{51 - 600.5*step, 8 + 600*step, 1201},   // Next to the void: Only the sample to the south is used
+	}
+	for _, tc := range testCases {
+		if elevation, err := grid.Elevation(tc.latitude, tc.longitude); err != nil || math.Abs(elevation-tc.elevation) > 1e-6 {
+			t.Errorf("Elevation(%v, %v): %f (%v), want %f", tc.latitude, tc.longitude, elevation, err, tc.elevation)
+		}
+	}
+	if _, err := grid.Elevation(51-600*step, 8+600*step); err == nil {
+		t.Error("Elevation at the void: want an error")
+	}
+	if _, err := grid.Elevation(49.9, 8.5); err == nil {
+		t.Error("Elevation outside of the tile: want an error")
+	}
+	if _, err := ReadHGT(bytes.NewReader(make([]byte, 100)), 50, 8); err == nil {
+		t.Error("ReadHGT of an invalid size: want an error")
+	}
+}
+
+func TestLoadHGT(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "S01W002.hgt")
+	if err := os.WriteFile(fileName, testHGT(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	grid, err := LoadHGT(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if grid.North != 0 || grid.West != -2 {
+		t.Errorf("LoadHGT: North %v / west %v, want 0 / -2", grid.North, grid.West)
+	}
+	if _, err := LoadHGT(filepath.Join(dir, "N00E000.hgt")); err == nil {
+		t.Error("LoadHGT of a missing file: want an error")
+	}
+}
@@ -26,6 +26,11 @@ func (c *CustomAlgorithm) Sigma() float64 {
 	return 0
 }
 
+// GradeWindow (CustomAlgorithm) returns 0; the grade and vertical speed are not smoothed
+func (c *CustomAlgorithm) GradeWindow() float64 {
+	return 0
+}
+
 // Duration (CustomAlgorithm) returns the time.Duration from point p1 to previousPoint in sec
 func (c *CustomAlgorithm) Duration(p1 *geo.Point, previousPoint *geo.Point) (float64, error) {
 	if previousPoint.Timestamp.Valid && p1.Timestamp.Valid {
@@ -54,6 +59,11 @@ func (c *CustomAlgorithm) Distance(p1 *geo.Point, previousPoint *geo.Point) (flo
 	return 101, nil
 }
 
+// Bearing (CustomAlgorithm) returns the initial bearing on a sphere
+func (c *CustomAlgorithm) Bearing(p1 *geo.Point, previousPoint *geo.Point) (float64, error) {
+	return geo.InitialBearing(previousPoint.Latitude, previousPoint.Longitude, p1.Latitude, p1.Longitude), nil
+}
+
 // Speed (CustomAlgorithm) returns the speed in m/s
 func (c *CustomAlgorithm) Speed(distance float64, duration float64) (float64, error) {
 	return 101.9, nil
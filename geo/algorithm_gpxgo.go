@@ -11,10 +11,24 @@ type AlgorithmGpxgo struct {
 	Name                          string
 	ShouldStandardDeviationBeUsed bool // Should the standard deviation be used to determine which points are used for calculation
 	SigmaMultiplier               float64
+	GradeSmoothingWindow          float64 // Distance (m) of the window to smooth the grade and vertical speed; 0 disables the smoothing
 	ShouldHaversine               bool // Should the formula of Haversine be used to calculate the distance between two points
 	OneDegree                     float64
-	EarthRadius                   float64
-	Should3D                      bool // Should the distance be calculated with the elevation different of each point
+	Ellipsoid                     Ellipsoid // Reference ellipsoid; the equatorial radius is used as the earth radius for Haversine; WGS-84 if not set
+	Should3D                      bool      // Should the distance be calculated with the elevation different of each point
+}
+
+// NewAlgorithmGpxgo returns the gpxgo algorithm for the ellipsoid (WGS-84 if the ellipsoid is not set) with default values
+func NewAlgorithmGpxgo(name string, ellipsoid Ellipsoid) *AlgorithmGpxgo {
+	return &AlgorithmGpxgo{
+		Name:                          name,
+		ShouldStandardDeviationBeUsed: false,
+		SigmaMultiplier:               DefaultSigmaMultiplier,
+		ShouldHaversine:               false,
+		OneDegree:                     DefaultOneDegree,
+		Ellipsoid:                     ellipsoid.orDefault(),
+		Should3D:                      false,
+	}
 }
 
 // String returns the name of the algorithm
@@ -32,6 +46,11 @@ func (alg *AlgorithmGpxgo) Sigma() float64 {
 	return alg.SigmaMultiplier
 }
 
+// GradeWindow (AlgorithmGpxgo) returns the distance (m) of the window to smooth the grade and vertical speed
+func (alg *AlgorithmGpxgo) GradeWindow() float64 {
+	return alg.GradeSmoothingWindow
+}
+
 // Duration (AlgorithmGpxgo) returns the time.Duration from point p1 to previousPoint in sec
 func (alg *AlgorithmGpxgo) Duration(p1 *Point, previousPoint *Point) (float64, error) {
 	if previousPoint.Timestamp.Valid && p1.Timestamp.Valid {
@@ -61,14 +80,18 @@ func (alg *AlgorithmGpxgo) Distance(p1 *Point, previousPoint *Point) (float64, e
 	absLat := math.Abs(p1.Latitude - previousPoint.Latitude)
 	absLon := math.Abs(p1.Longitude - previousPoint.Longitude)
 	if alg.ShouldHaversine || absLat > 0.2 || absLon > 0.2 {
-		return HaversineDistance(p1.Latitude, p1.Longitude, previousPoint.Latitude, previousPoint.Longitude, alg.EarthRadius), nil
+		return HaversineDistance(p1.Latitude, p1.Longitude, previousPoint.Latitude, previousPoint.Longitude, alg.Ellipsoid.orDefault().SemiMajorAxisA), nil
 	}
 
 	coef := math.Cos(ToRad(p1.Latitude))
 	x := p1.Latitude - previousPoint.Latitude
 	y := (p1.Longitude - previousPoint.Longitude) * coef
 
-	distance2d := math.Sqrt(x*x+y*y) * alg.OneDegree
+	oneDegree := alg.OneDegree
+	if oneDegree == 0 {
+		oneDegree = DefaultOneDegree
+	}
+	distance2d := math.Sqrt(x*x+y*y) * oneDegree
 
 	if !alg.Should3D || p1.Elevation == previousPoint.Elevation {
 		return distance2d, nil
@@ -82,6 +105,14 @@ func (alg *AlgorithmGpxgo) Distance(p1 *Point, previousPoint *Point) (float64, e
 	return math.Sqrt(math.Pow(distance2d, 2) + math.Pow(eleDiff, 2)), nil
 }
 
+// Bearing (AlgorithmGpxgo) returns the initial bearing on a sphere from the previousPoint to the point p1
+func (alg *AlgorithmGpxgo) Bearing(p1 *Point, previousPoint *Point) (float64, error) {
+	if p1.Latitude == previousPoint.Latitude && p1.Longitude == previousPoint.Longitude {
+		return 0, errors.New("Points are equal")
+	}
+	return InitialBearing(previousPoint.Latitude, previousPoint.Longitude, p1.Latitude, p1.Longitude), nil
+}
+
 // Speed (Vincenty) returns the speed in m/s
 func (alg *AlgorithmGpxgo) Speed(distance float64, duration float64) (float64, error) {
 	if duration == 0 {
@@ -141,6 +172,32 @@ func HaversineDistance(lat1, lon1, lat2, lon2 float64, earthRadius float64) floa
 	return d
 }
 
+// InitialBearing returns the initial bearing (degree, 0 <= bearing < 360) on a great circle from point 1 to point 2.
+//
+// Implemented from http://www.movable-type.co.uk/scripts/latlong.html
+func InitialBearing(lat1, lon1, lat2, lon2 float64) float64 {
+	thisLat1 := ToRad(lat1)
+	thisLat2 := ToRad(lat2)
+	dLon := ToRad(lon2 - lon1)
+
+	y := math.Sin(dLon) * math.Cos(thisLat2)
+	x := math.Cos(thisLat1)*math.Sin(thisLat2) - math.Sin(thisLat1)*math.Cos(thisLat2)*math.Cos(dLon)
+
+	return NormalizeBearing(math.Atan2(y, x) * 180 / math.Pi)
+}
+
+// NormalizeBearing returns the bearing in the range 0 <= bearing < 360
+func NormalizeBearing(bearing float64) float64 {
+	bearing = math.Mod(bearing, 360)
+	if bearing < 0 {
+		bearing += 360
+	}
+	if bearing >= 360 {
+		bearing = 0
+	}
+	return bearing
+}
+
 // CheckActivityType returns the activity type (as a string number) based on my experience with strava, garmin, runkeeeper, ...
 func (alg *AlgorithmGpxgo) CheckActivityType(lowerCaseName string) (string, error) {
 
@@ -0,0 +1,949 @@
+package geo
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"sync"
+)
+
+// Karney implements the geodesic algorithms by Charles F. F. Karney to calculate the distance and azimuths.
+// The distance is accurate to a few nanometres on the WGS-84 ellipsoid and the calculation converges for all pairs of points (also nearly antipodal points).
+//
+// See: C. F. F. Karney, Algorithms for geodesics, J. Geodesy 87, 43–55 (2013); https://doi.org/10.1007/s00190-012-0578-z
+// Ported from GeographicLib (https://geographiclib.sourceforge.io/)
+type Karney struct {
+	ShouldStandardDeviationBeUsed bool      // Should the standard deviation be used to determine which points are used for calculation
+	SigmaMultiplier               float64   // Define the sima standard deviation
+	GradeSmoothingWindow          float64   // Distance (m) of the window to smooth the grade and vertical speed; 0 disables the smoothing
+	Ellipsoid                     Ellipsoid // Reference ellipsoid; WGS-84 if not set
+	Name                          string
+}
+
+// NewKarney returns the Karney algorithm for the ellipsoid (WGS-84 if the ellipsoid is not set) with default values
+func NewKarney(name string, ellipsoid Ellipsoid) *Karney {
+	return &Karney{
+		ShouldStandardDeviationBeUsed: false,
+		SigmaMultiplier:               DefaultSigmaMultiplier,
+		Ellipsoid:                     ellipsoid.orDefault(),
+		Name:                          name,
+	}
+}
+
+// String returns the name of the algorithm
+func (k *Karney) String() string {
+	return k.Name
+}
+
+// ShouldStandardDeviation (Karney) returns if the standard deviation should be used or not
+func (k *Karney) ShouldStandardDeviation() bool {
+	return k.ShouldStandardDeviationBeUsed
+}
+
+// Sigma (Karney) returns the sigma for the standard deviation
+func (k *Karney) Sigma() float64 {
+	return k.SigmaMultiplier
+}
+
+// GradeWindow (Karney) returns the distance (m) of the window to smooth the grade and vertical speed
+func (k *Karney) GradeWindow() float64 {
+	return k.GradeSmoothingWindow
+}
+
+// Duration (Karney) returns the time.Duration from point p1 to previousPoint in sec
+func (k *Karney) Duration(p1 *Point, previousPoint *Point) (float64, error) {
+	if p1.Timestamp.Valid && previousPoint.Timestamp.Valid {
+		return p1.Timestamp.Time.Sub(*previousPoint.Timestamp.Time).Seconds(), nil
+	}
+	return 0, errors.New("Point or previous point does not have a timestamp")
+}
+
+// CustomMovingPoints (Karney) defines which points should be used for "Moving"Time/Distance and if the it's set the new gpxPoint.Point Data
+func (k *Karney) CustomMovingPoints(gpxPoint *GPXPoint, previousGPXPoint *GPXPoint, algorithm Algorithm) error {
+
+	/* 	Define which points should be used; if a point should be used for calculation then set it's new values like Duration, Distance, Speed, etc.
+	Here we use the set the new value for the points which used for "Moving"Time/Distanc
+	*/
+
+	// speed < 1 m/s
+	if gpxPoint.Speed < 1.0 {
+		return errors.New("Point Speed below threshold")
+	}
+	gpxPoint.Point.SetPointData(&previousGPXPoint.Point, algorithm)
+	return nil
+}
+
+// Distance (Karney) returns the geodesic distance in m from the previousPoint to the point p1
+func (k *Karney) Distance(p1 *Point, previousPoint *Point) (float64, error) {
+	if p1.Latitude == previousPoint.Latitude && p1.Longitude == previousPoint.Longitude {
+		return 0, nil
+	}
+	distance, _, _ := k.Inverse(previousPoint.Latitude, previousPoint.Longitude, p1.Latitude, p1.Longitude)
+	if math.IsNaN(distance) {
+		return 0, errors.New("Latitude or longitude is not valid")
+	}
+	return distance, nil
+}
+
+// Bearing (Karney) returns the forward azimuth at the previousPoint of the geodesic from the previousPoint to the point p1
+func (k *Karney) Bearing(p1 *Point, previousPoint *Point) (float64, error) {
+	if p1.Latitude == previousPoint.Latitude && p1.Longitude == previousPoint.Longitude {
+		return 0, errors.New("Points are equal")
+	}
+	_, azimuth1, _ := k.Inverse(previousPoint.Latitude, previousPoint.Longitude, p1.Latitude, p1.Longitude)
+	if math.IsNaN(azimuth1) {
+		return 0, errors.New("Latitude or longitude is not valid")
+	}
+	return NormalizeBearing(azimuth1), nil
+}
+
+// Inverse (Karney) solves the inverse geodesic problem from point 1 (lat1, lon1) to point 2 (lat2, lon2).
+// It returns the distance in m, the forward azimuth at point 1 and the forward azimuth at point 2 in degree (clockwise from north).
+// The back azimuth at point 2 (pointing to point 1) is azimuth2 ± 180°.
+func (k *Karney) Inverse(lat1, lon1, lat2, lon2 float64) (distance float64, azimuth1 float64, azimuth2 float64) {
+	ellipsoid := k.Ellipsoid.orDefault()
+	return KarneyInverse(lat1, lon1, lat2, lon2, ellipsoid.SemiMajorAxisA, ellipsoid.Flattening)
+}
+
+// Speed (Karney) returns the speed in m/s
+func (k *Karney) Speed(distance float64, duration float64) (float64, error) {
+	if duration == 0 {
+		return 0, errors.New("Duration is zero")
+	}
+	speed := distance / duration
+	if math.IsInf(speed, 1) {
+		return 0, errors.New("Duration is +Inf")
+	}
+	if math.IsNaN(speed) {
+		return 0, errors.New("Duration IsNaN")
+	}
+
+	return distance / duration, nil
+}
+
+// Pace (Karney) returns the pace in s/m
+func (k *Karney) Pace(distance float64, duration float64) (float64, error) {
+	if math.IsInf(distance, 1) || math.IsInf(distance, -1) || math.IsNaN(distance) || math.IsInf(duration, 1) || math.IsNaN(duration) {
+		return 0, errors.New("Distance is +INf or NaN")
+	}
+	if distance == 0 {
+		return 0, errors.New("Distance is zero")
+	}
+
+	pace := duration / distance
+	if math.IsInf(pace, 1) {
+		return 0, errors.New("Duration is +Inf")
+	}
+	if math.IsNaN(pace) {
+		return 0, errors.New("Duration IsNaN")
+	}
+
+	return pace, nil
+}
+
+// CheckActivityType returns the activity type (as a string number) based on my experience with strava, garmin, runkeeeper, ...
+func (k *Karney) CheckActivityType(lowerCaseName string) (string, error) {
+
+	var activityTpesName map[string]string
+	activityTpesName = make(map[string]string)
+	activityTpesName["running"] = "9"
+	activityTpesName["lauf"] = "9"
+	activityTpesName["cycling"] = "1"
+	activityTpesName["rad"] = "1"
+	activityTpesName["walking"] = "4"
+	activityTpesName["hiking"] = "4"
+	activityTpesName["spaziergang"] = "4"
+
+	result := activityTpesName[lowerCaseName]
+	if len(result) > 0 {
+		return result, nil
+	}
+
+	for key, value := range activityTpesName {
+		if strings.Contains(lowerCaseName, key) {
+			return value, nil
+		}
+	}
+
+	return "", errors.New("No activity type found")
+
+}
+
+/* Karney internal methods */
+
+// KarneyInverse solves the inverse geodesic problem on the ellipsoid defined by the equatorial radius a and the flattening f.
+// It returns the distance in m and the forward azimuths (degree) at point 1 and point 2.
+func KarneyInverse(lat1, lon1, lat2, lon2 float64, a float64, f float64) (distance float64, azimuth1 float64, azimuth2 float64) {
+	g := cachedGeodesic(a, f)
+	s12, salp1, calp1, salp2, calp2 := g.inverse(lat1, lon1, lat2, lon2)
+	return s12, atan2d(salp1, calp1), atan2d(salp2, calp2)
+}
+
+const (
+	geodesicOrder = 6
+	nA1           = geodesicOrder
+	nC1           = geodesicOrder
+	nA2           = geodesicOrder
+	nC2           = geodesicOrder
+	nA3           = geodesicOrder
+	nC3           = geodesicOrder
+	maxit1        = 20
+	maxit2        = maxit1 + 53 + 10
+)
+
+var (
+	geodesicTiny    = math.Sqrt(math.SmallestNonzeroFloat64 * (1 << 52)) // sqrt of the smallest normalized float64
+	geodesicTol0    = math.Nextafter(1, 2) - 1
+	geodesicTol1    = 200 * geodesicTol0
+	geodesicTol2    = math.Sqrt(geodesicTol0)
+	geodesicTolb    = geodesicTol0 * geodesicTol2
+	geodesicXthresh = 1000 * geodesicTol2
+
+	geodesicCache sync.Map
+)
+
+// geodesic contains the constants of an ellipsoid which are needed for the geodesic calculations
+type geodesic struct {
+	a, f, f1, e2, ep2, n, b, etol2 float64
+
+	a3x [nA3]float64
+	c3x [(nC3 * (nC3 - 1)) / 2]float64
+}
+
+// cachedGeodesic returns the (cached) geodesic constants for the ellipsoid
+func cachedGeodesic(a float64, f float64) *geodesic {
+	key := [2]float64{a, f}
+	if g, ok := geodesicCache.Load(key); ok {
+		return g.(*geodesic)
+	}
+	g := newGeodesic(a, f)
+	geodesicCache.Store(key, g)
+	return g
+}
+
+func newGeodesic(a float64, f float64) *geodesic {
+	g := &geodesic{a: a, f: f}
+	g.f1 = 1 - f
+	g.e2 = f * (2 - f)
+	g.ep2 = g.e2 / (g.f1 * g.f1)
+	g.n = f / (2 - f)
+	g.b = a * g.f1
+	g.etol2 = 0.1 * geodesicTol2 / math.Sqrt(math.Max(0.001, math.Abs(f))*math.Min(1, 1-f/2)/2)
+	g.setA3Coeff()
+	g.setC3Coeff()
+	return g
+}
+
+func (g *geodesic) setA3Coeff() {
+	coeff := []float64{
+		-3, 128,
+		-2, -3, 64,
+		-1, -3, -1, 16,
+		3, -1, -2, 8,
+		1, -1, 2,
+		1, 1,
+	}
+	o, k := 0, 0
+	for j := nA3 - 1; j >= 0; j-- {
+		m := minInt(nA3-j-1, j)
+		g.a3x[k] = polyval(m, coeff[o:], g.n) / coeff[o+m+1]
+		k++
+		o += m + 2
+	}
+}
+
+func (g *geodesic) setC3Coeff() {
+	coeff := []float64{
+		3, 128,
+		2, 5, 128,
+		-1, 3, 3, 64,
+		-1, 0, 1, 8,
+		-1, 1, 4,
+		5, 256,
+		1, 3, 128,
+		-3, -2, 3, 64,
+		1, -3, 2, 32,
+		7, 512,
+		-10, 9, 384,
+		5, -9, 5, 192,
+		7, 512,
+		-14, 7, 512,
+		21, 2560,
+	}
+	o, k := 0, 0
+	for l := 1; l < nC3; l++ {
+		for j := nC3 - 1; j >= l; j-- {
+			m := minInt(nC3-j-1, j)
+			g.c3x[k] = polyval(m, coeff[o:], g.n) / coeff[o+m+1]
+			k++
+			o += m + 2
+		}
+	}
+}
+
+func (g *geodesic) a3f(eps float64) float64 {
+	return polyval(nA3-1, g.a3x[:], eps)
+}
+
+func (g *geodesic) c3f(eps float64, c []float64) {
+	mult := 1.0
+	o := 0
+	for l := 1; l < nC3; l++ {
+		m := nC3 - l - 1
+		mult *= eps
+		c[l] = mult * polyval(m, g.c3x[o:], eps)
+		o += m + 1
+	}
+}
+
+// inverse returns the distance and the sine/cosine of the azimuths at point 1 and point 2
+func (g *geodesic) inverse(lat1, lon1, lat2, lon2 float64) (s12, salp1, calp1, salp2, calp2 float64) {
+	lon12, lon12s := angDiff(lon1, lon2)
+	// Make longitude difference positive.
+	lonsign := 1.0
+	if lon12 < 0 {
+		lonsign = -1
+	}
+	// If very close to being on the same half-meridian, then make it so.
+	lon12 = lonsign * angRound(lon12)
+	lon12s = angRound((180 - lon12) - lonsign*lon12s)
+	lam12 := lon12 * math.Pi / 180
+	var slam12, clam12 float64
+	if lon12 > 90 {
+		slam12, clam12 = sincosd(lon12s)
+		clam12 = -clam12
+	} else {
+		slam12, clam12 = sincosd(lon12)
+	}
+
+	// If really close to the equator, treat as on equator.
+	lat1 = angRound(latFix(lat1))
+	lat2 = angRound(latFix(lat2))
+	// Swap points so that point with higher (abs) latitude is point 1
+	swapp := 1.0
+	if math.Abs(lat1) < math.Abs(lat2) {
+		swapp = -1
+		lonsign *= -1
+		lat1, lat2 = lat2, lat1
+	}
+	// Make lat1 <= 0
+	latsign := -1.0
+	if lat1 < 0 {
+		latsign = 1
+	}
+	lat1 *= latsign
+	lat2 *= latsign
+	// Now we have: 0 <= lon12 <= 180, -90 <= lat1 <= 0, lat1 <= lat2 <= -lat1
+
+	sbet1, cbet1 := sincosd(lat1)
+	sbet1 *= g.f1
+	// Ensure cbet1 = +epsilon at poles
+	sbet1, cbet1 = norm2(sbet1, cbet1)
+	cbet1 = math.Max(geodesicTiny, cbet1)
+
+	sbet2, cbet2 := sincosd(lat2)
+	sbet2 *= g.f1
+	// Ensure cbet2 = +epsilon at poles
+	sbet2, cbet2 = norm2(sbet2, cbet2)
+	cbet2 = math.Max(geodesicTiny, cbet2)
+
+	// If cbet1 < -sbet1, then cbet2 - cbet1 is a sensitive measure of |bet1| - |bet2|.
+	// Sometimes these quantities vanish and in that case we force bet2 = +/- bet1 exactly.
+	if cbet1 < -sbet1 {
+		if cbet2 == cbet1 {
+			sbet2 = math.Copysign(sbet1, sbet2)
+		}
+	} else {
+		if math.Abs(sbet2) == -sbet1 {
+			cbet2 = cbet1
+		}
+	}
+
+	dn1 := math.Sqrt(1 + g.ep2*sbet1*sbet1)
+	dn2 := math.Sqrt(1 + g.ep2*sbet2*sbet2)
+
+	var c1a [nC1 + 1]float64
+	var c2a [nC2 + 1]float64
+	var c3a [nC3]float64
+
+	var sig12, s12x, m12x float64
+	salp2, calp2 = math.NaN(), math.NaN()
+
+	meridian := lat1 == -90 || slam12 == 0
+	if meridian {
+		// Endpoints are on a single full meridian, so the geodesic might lie on a meridian.
+		calp1, salp1 = clam12, slam12 // Head to the target longitude
+		calp2, salp2 = 1, 0           // At the target we're heading north
+
+		// tan(bet) = tan(sig) * cos(alp)
+		ssig1, csig1 := sbet1, calp1*cbet1
+		ssig2, csig2 := sbet2, calp2*cbet2
+
+		// sig12 = sig2 - sig1
+		sig12 = math.Atan2(math.Max(0, csig1*ssig2-ssig1*csig2), csig1*csig2+ssig1*ssig2)
+		s12x, m12x, _ = g.lengths(g.n, sig12, ssig1, csig1, dn1, ssig2, csig2, dn2, c1a[:], c2a[:])
+
+		// Add the check for sig12 since zero length geodesics might yield m12 < 0.
+		if sig12 < 1 || m12x >= 0 {
+			if sig12 < 3*geodesicTiny || (sig12 < geodesicTol0 && (s12x < 0 || m12x < 0)) {
+				sig12, m12x, s12x = 0, 0, 0
+			}
+			s12x *= g.b
+		} else {
+			// m12 < 0, i.e., prolate and too close to anti-podal
+			meridian = false
+		}
+	}
+
+	if !meridian && sbet1 == 0 && (g.f <= 0 || lon12s >= g.f*180) {
+		// Geodesic runs along equator
+		calp1, calp2 = 0, 0
+		salp1, salp2 = 1, 1
+		s12x = g.a * lam12
+	} else if !meridian {
+		// Now point1 and point2 belong within a hemisphere bounded by a meridian and geodesic is neither meridional or equatorial.
+		// Figure a starting point for Newton's method
+		var dnm float64
+		sig12, salp1, calp1, salp2, calp2, dnm = g.inverseStart(sbet1, cbet1, dn1, sbet2, cbet2, dn2, lam12, slam12, clam12, c1a[:], c2a[:])
+
+		if sig12 >= 0 {
+			// Short lines (inverseStart sets salp2, calp2, dnm)
+			s12x = sig12 * g.b * dnm
+		} else {
+			// Newton's method. This is a straightforward solution of f(alp1) = lambda12(alp1) - lam12 = 0 with one wrinkle.
+			// f(alp) has exactly one root in the interval (0, pi) and its derivative is positive at the root.
+			// During the course of the iteration, a range (alp1a, alp1b) is maintained which brackets the root.
+			var ssig1, csig1, ssig2, csig2, eps float64
+			numit := 0
+			tripn, tripb := false, false
+			// Bracketing range
+			salp1a, calp1a := geodesicTiny, 1.0
+			salp1b, calp1b := geodesicTiny, -1.0
+
+			for ; numit < maxit2; numit++ {
+				var v, dv float64
+				v, salp2, calp2, sig12, ssig1, csig1, ssig2, csig2, eps, dv = g.lambda12(sbet1, cbet1, dn1, sbet2, cbet2, dn2, salp1, calp1, slam12, clam12, numit < maxit1, c1a[:], c2a[:], c3a[:])
+				// Reversed test to allow escape with NaNs
+				tol := 1.0
+				if tripn {
+					tol = 8
+				}
+				if tripb || !(math.Abs(v) >= tol*geodesicTol0) {
+					break
+				}
+				// Update bracketing values
+				if v > 0 && (numit > maxit1 || calp1/salp1 > calp1b/salp1b) {
+					salp1b, calp1b = salp1, calp1
+				} else if v < 0 && (numit > maxit1 || calp1/salp1 < calp1a/salp1a) {
+					salp1a, calp1a = salp1, calp1
+				}
+
+				if numit+1 < maxit1 && dv > 0 {
+					dalp1 := -v / dv
+					sdalp1, cdalp1 := math.Sin(dalp1), math.Cos(dalp1)
+					nsalp1 := salp1*cdalp1 + calp1*sdalp1
+					if nsalp1 > 0 && math.Abs(dalp1) < math.Pi {
+						calp1 = calp1*cdalp1 - salp1*sdalp1
+						salp1 = nsalp1
+						salp1, calp1 = norm2(salp1, calp1)
+						// In some regimes we don't get quadratic convergence because slope -> 0.
+						// So use convergence conditions based on epsilon instead of sqrt(epsilon).
+						tripn = math.Abs(v) <= 16*geodesicTol0
+						continue
+					}
+				}
+				// Either dv was not positive or updated value was outside legal range.
+				// Use the midpoint of the bracket as the next estimate.
+				salp1 = (salp1a + salp1b) / 2
+				calp1 = (calp1a + calp1b) / 2
+				salp1, calp1 = norm2(salp1, calp1)
+				tripn = false
+				tripb = math.Abs(salp1a-salp1)+(calp1a-calp1) < geodesicTolb || math.Abs(salp1-salp1b)+(calp1-calp1b) < geodesicTolb
+			}
+			s12x, _, _ = g.lengths(eps, sig12, ssig1, csig1, dn1, ssig2, csig2, dn2, c1a[:], c2a[:])
+			s12x *= g.b
+		}
+	}
+
+	s12 = 0 + s12x // Convert -0 to 0
+
+	// Convert calp, salp to head accounting for lonsign, swapp, latsign.
+	if swapp < 0 {
+		salp1, salp2 = salp2, salp1
+		calp1, calp2 = calp2, calp1
+	}
+	salp1 *= swapp * lonsign
+	calp1 *= swapp * latsign
+	salp2 *= swapp * lonsign
+	calp2 *= swapp * latsign
+
+	return s12, salp1, calp1, salp2, calp2
+}
+
+// lengths returns the distance s12b and the reduced length m12b (both missing a factor of b) and the coefficient m0 of the secular term of the reduced length
+func (g *geodesic) lengths(eps, sig12, ssig1, csig1, dn1, ssig2, csig2, dn2 float64, c1a []float64, c2a []float64) (s12b float64, m12b float64, m0 float64) {
+	a1 := a1m1f(eps)
+	c1f(eps, c1a)
+	a2 := a2m1f(eps)
+	c2f(eps, c2a)
+	m0 = a1 - a2
+	a2 = 1 + a2
+	a1 = 1 + a1
+
+	b1 := sinCosSeries(true, ssig2, csig2, c1a) - sinCosSeries(true, ssig1, csig1, c1a)
+	s12b = a1 * (sig12 + b1)
+	b2 := sinCosSeries(true, ssig2, csig2, c2a) - sinCosSeries(true, ssig1, csig1, c2a)
+	j12 := m0*sig12 + (a1*b1 - a2*b2)
+
+	// Add parens around (csig1 * ssig2) and (ssig1 * csig2) to ensure accurate cancellation in the case of coincident points.
+	m12b = dn2*(csig1*ssig2) - dn1*(ssig1*csig2) - csig1*csig2*j12
+	return s12b, m12b, m0
+}
+
+// inverseStart returns a starting point for Newton's method in salp1 and calp1 (sig12 is -1).
+// If Newton's method doesn't need to be used, sig12, salp2, calp2 and dnm are returned as well.
+func (g *geodesic) inverseStart(sbet1, cbet1, dn1, sbet2, cbet2, dn2, lam12, slam12, clam12 float64, c1a []float64, c2a []float64) (sig12, salp1, calp1, salp2, calp2, dnm float64) {
+	sig12 = -1
+	salp2, calp2, dnm = math.NaN(), math.NaN(), math.NaN()
+
+	// bet12 = bet2 - bet1 in [0, pi); bet12a = bet2 + bet1 in (-pi, 0]
+	sbet12 := sbet2*cbet1 - cbet2*sbet1
+	cbet12 := cbet2*cbet1 + sbet2*sbet1
+	sbet12a := sbet2 * cbet1
+	sbet12a += cbet2 * sbet1
+
+	shortline := cbet12 >= 0 && sbet12 < 0.5 && cbet2*lam12 < 0.5
+	var somg12, comg12 float64
+	if shortline {
+		sbetm2 := (sbet1 + sbet2) * (sbet1 + sbet2)
+		// sin((bet1+bet2)/2)^2 = (sbet1 + sbet2)^2 / ((sbet1 + sbet2)^2 + (cbet1 + cbet2)^2)
+		sbetm2 /= sbetm2 + (cbet1+cbet2)*(cbet1+cbet2)
+		dnm = math.Sqrt(1 + g.ep2*sbetm2)
+		omg12 := lam12 / (g.f1 * dnm)
+		somg12, comg12 = math.Sin(omg12), math.Cos(omg12)
+	} else {
+		somg12, comg12 = slam12, clam12
+	}
+
+	salp1 = cbet2 * somg12
+	if comg12 >= 0 {
+		calp1 = sbet12 + cbet2*sbet1*somg12*somg12/(1+comg12)
+	} else {
+		calp1 = sbet12a - cbet2*sbet1*somg12*somg12/(1-comg12)
+	}
+
+	ssig12 := math.Hypot(salp1, calp1)
+	csig12 := sbet1*sbet2 + cbet1*cbet2*comg12
+
+	if shortline && ssig12 < g.etol2 {
+		// really short lines
+		salp2 = cbet1 * somg12
+		if comg12 >= 0 {
+			calp2 = sbet12 - cbet1*sbet2*(somg12*somg12/(1+comg12))
+		} else {
+			calp2 = sbet12 - cbet1*sbet2*(1-comg12)
+		}
+		salp2, calp2 = norm2(salp2, calp2)
+		// Set return value
+		sig12 = math.Atan2(ssig12, csig12)
+	} else if math.Abs(g.n) >= 0.1 || csig12 >= 0 || ssig12 >= 6*math.Abs(g.n)*math.Pi*cbet1*cbet1 {
+		// Nothing to do, zeroth order spherical approximation is OK
+	} else {
+		// Scale lam12 and bet2 to x, y coordinate system where antipodal point is at origin and singular point is at y = 0, x = -1.
+		var x, y, lamscale, betscale float64
+		lam12x := math.Atan2(-slam12, -clam12)
+		if g.f >= 0 {
+			// x = dlong, y = dlat
+			k2 := sbet1 * sbet1 * g.ep2
+			eps := k2 / (2*(1+math.Sqrt(1+k2)) + k2)
+			lamscale = g.f * cbet1 * g.a3f(eps) * math.Pi
+			betscale = lamscale * cbet1
+			x = lam12x / lamscale
+			y = sbet12a / betscale
+		} else {
+			// x = dlat, y = dlong
+			cbet12a := cbet2*cbet1 - sbet2*sbet1
+			bet12a := math.Atan2(sbet12a, cbet12a)
+			// In the case of lon12 = 180, this repeats a calculation made in inverse.
+			_, m12b, m0 := g.lengths(g.n, math.Pi+bet12a, sbet1, -cbet1, dn1, sbet2, cbet2, dn2, c1a, c2a)
+			x = -1 + m12b/(cbet1*cbet2*m0*math.Pi)
+			if x < -0.01 {
+				betscale = sbet12a / x
+			} else {
+				betscale = -g.f * cbet1 * cbet1 * math.Pi
+			}
+			lamscale = betscale / cbet1
+			y = lam12x / lamscale
+		}
+
+		if y > -geodesicTol1 && x > -1-geodesicXthresh {
+			// strip near cut
+			if g.f >= 0 {
+				salp1 = math.Min(1, -x)
+				calp1 = -math.Sqrt(1 - salp1*salp1)
+			} else {
+				lower := -1.0
+				if x > -geodesicTol1 {
+					lower = 0
+				}
+				calp1 = math.Max(lower, x)
+				salp1 = math.Sqrt(1 - calp1*calp1)
+			}
+		} else {
+			// Estimate alp1, by solving the astroid problem.
+			// Because omg12 is near pi, estimate work with omg12a = pi - omg12
+			k := astroid(x, y)
+			var omg12a float64
+			if g.f >= 0 {
+				omg12a = lamscale * (-x * k / (1 + k))
+			} else {
+				omg12a = lamscale * (-y * (1 + k) / k)
+			}
+			somg12, comg12 = math.Sin(omg12a), -math.Cos(omg12a)
+			// Update spherical estimate of alp1 using omg12 instead of lam12
+			salp1 = cbet2 * somg12
+			calp1 = sbet12a - cbet2*sbet1*somg12*somg12/(1-comg12)
+		}
+	}
+	// Sanity check on starting guess. Backwards check allows NaN through.
+	if !(salp1 <= 0) {
+		salp1, calp1 = norm2(salp1, calp1)
+	} else {
+		salp1, calp1 = 1, 0
+	}
+	return sig12, salp1, calp1, salp2, calp2, dnm
+}
+
+// lambda12 returns the difference of the longitude of the geodesic starting with the azimuth alp1 to the target longitude (and its derivative dlam12 if diffp is true)
+func (g *geodesic) lambda12(sbet1, cbet1, dn1, sbet2, cbet2, dn2, salp1, calp1, slam120, clam120 float64, diffp bool, c1a []float64, c2a []float64, c3a []float64) (lam12, salp2, calp2, sig12, ssig1, csig1, ssig2, csig2, eps, dlam12 float64) {
+	if sbet1 == 0 && calp1 == 0 {
+		// Break degeneracy of equatorial line. This case has already been handled.
+		calp1 = -geodesicTiny
+	}
+
+	// sin(alp1) * cos(bet1) = sin(alp0)
+	salp0 := salp1 * cbet1
+	calp0 := math.Hypot(calp1, salp1*sbet1) // calp0 > 0
+
+	// tan(bet1) = tan(sig1) * cos(alp1); tan(omg1) = sin(alp0) * tan(sig1)
+	ssig1 = sbet1
+	somg1 := salp0 * sbet1
+	csig1 = calp1 * cbet1
+	comg1 := csig1
+	ssig1, csig1 = norm2(ssig1, csig1)
+
+	// Enforce symmetries in the case abs(bet2) = -bet1.
+	if cbet2 != cbet1 {
+		salp2 = salp0 / cbet2
+	} else {
+		salp2 = salp1
+	}
+	// calp2 = sqrt(1 - sq(salp2)) = sqrt(sq(calp0) - sq(sbet2)) / cbet2
+	if cbet2 != cbet1 || math.Abs(sbet2) != -sbet1 {
+		var t float64
+		if cbet1 < -sbet1 {
+			t = (cbet2 - cbet1) * (cbet1 + cbet2)
+		} else {
+			t = (sbet1 - sbet2) * (sbet1 + sbet2)
+		}
+		calp2 = math.Sqrt((calp1*cbet1)*(calp1*cbet1)+t) / cbet2
+	} else {
+		calp2 = math.Abs(calp1)
+	}
+	// tan(bet2) = tan(sig2) * cos(alp2); tan(omg2) = sin(alp0) * tan(sig2).
+	ssig2 = sbet2
+	somg2 := salp0 * sbet2
+	csig2 = calp2 * cbet2
+	comg2 := csig2
+	ssig2, csig2 = norm2(ssig2, csig2)
+
+	// sig12 = sig2 - sig1, limit to [0, pi]
+	sig12 = math.Atan2(math.Max(0, csig1*ssig2-ssig1*csig2), csig1*csig2+ssig1*ssig2)
+
+	// omg12 = omg2 - omg1, limit to [0, pi]
+	somg12 := math.Max(0, comg1*somg2-somg1*comg2)
+	comg12 := comg1*comg2 + somg1*somg2
+	// eta = omg12 - lam120
+	eta := math.Atan2(somg12*clam120-comg12*slam120, comg12*clam120+somg12*slam120)
+
+	k2 := calp0 * calp0 * g.ep2
+	eps = k2 / (2*(1+math.Sqrt(1+k2)) + k2)
+	g.c3f(eps, c3a)
+	b312 := sinCosSeries(true, ssig2, csig2, c3a) - sinCosSeries(true, ssig1, csig1, c3a)
+	domg12 := -g.f * g.a3f(eps) * salp0 * (sig12 + b312)
+	lam12 = eta + domg12
+
+	if diffp {
+		if calp2 == 0 {
+			dlam12 = -2 * g.f1 * dn1 / sbet1
+		} else {
+			_, dlam12, _ = g.lengths(eps, sig12, ssig1, csig1, dn1, ssig2, csig2, dn2, c1a, c2a)
+			dlam12 *= g.f1 / (calp2 * cbet2)
+		}
+	} else {
+		dlam12 = math.NaN()
+	}
+	return lam12, salp2, calp2, sig12, ssig1, csig1, ssig2, csig2, eps, dlam12
+}
+
+// astroid solves k^4+2*k^3-(x^2+y^2-1)*k^2-2*y^2*k-y^2 = 0 for positive root k
+func astroid(x float64, y float64) float64 {
+	p := x * x
+	q := y * y
+	r := (p + q - 1) / 6
+	if q == 0 && r <= 0 {
+		// y = 0 with |x| <= 1. Handle this case directly.
+		return 0
+	}
+	// Avoid possible division by zero when r = 0 by multiplying equations for s and t by r^3 and r, resp.
+	S := p * q / 4 // S = r^3 * s
+	r2 := r * r
+	r3 := r * r2
+	// The discriminant of the quadratic equation for T3. This is zero on the evolute curve p^(1/3)+q^(1/3) = 1
+	disc := S * (S + 2*r3)
+	u := r
+	if disc >= 0 {
+		T3 := S + r3
+		// Pick the sign on the sqrt to maximize abs(T3). This minimizes loss of precision due to cancellation.
+		if T3 < 0 {
+			T3 -= math.Sqrt(disc)
+		} else {
+			T3 += math.Sqrt(disc)
+		}
+		T := math.Cbrt(T3) // T = r * t
+		// T can be zero; but then r2 / T -> 0.
+		if T != 0 {
+			u += T + r2/T
+		} else {
+			u += T
+		}
+	} else {
+		// T is complex, but the way u is defined the result is real.
+		ang := math.Atan2(math.Sqrt(-disc), -(S + r3))
+		// There are three possible cube roots. We choose the root which avoids cancellation.
+		u += 2 * r * math.Cos(ang/3)
+	}
+	v := math.Sqrt(u*u + q) // guaranteed positive
+	// Avoid loss of accuracy when u < 0.
+	var uv float64
+	if u < 0 {
+		uv = q / (v - u)
+	} else {
+		uv = u + v
+	}
+	w := (uv - q) / (2 * v)
+	// Rearrange expression for k to avoid loss of accuracy due to subtraction.
+	return uv / (math.Sqrt(uv+w*w) + w)
+}
+
+func a1m1f(eps float64) float64 {
+	coeff := []float64{1, 4, 64, 0, 256}
+	m := nA1 / 2
+	t := polyval(m, coeff, eps*eps) / coeff[m+1]
+	return (t + eps) / (1 - eps)
+}
+
+func c1f(eps float64, c []float64) {
+	coeff := []float64{
+		-1, 6, -16, 32,
+		-9, 64, -128, 2048,
+		9, -16, 768,
+		3, -5, 512,
+		-7, 1280,
+		-7, 2048,
+	}
+	eps2 := eps * eps
+	d := eps
+	o := 0
+	for l := 1; l <= nC1; l++ {
+		m := (nC1 - l) / 2
+		c[l] = d * polyval(m, coeff[o:], eps2) / coeff[o+m+1]
+		o += m + 2
+		d *= eps
+	}
+}
+
+func a2m1f(eps float64) float64 {
+	coeff := []float64{25, 36, 64, 0, 256}
+	m := nA2 / 2
+	t := polyval(m, coeff, eps*eps) / coeff[m+1]
+	return t*(1-eps) - eps
+}
+
+func c2f(eps float64, c []float64) {
+	coeff := []float64{
+		1, 2, 16, 32,
+		35, 64, 384, 2048,
+		15, 80, 768,
+		7, 35, 512,
+		63, 1280,
+		77, 2048,
+	}
+	eps2 := eps * eps
+	d := eps
+	o := 0
+	for l := 1; l <= nC2; l++ {
+		m := (nC2 - l) / 2
+		c[l] = d * polyval(m, coeff[o:], eps2) / coeff[o+m+1]
+		o += m + 2
+		d *= eps
+	}
+}
+
+// sinCosSeries evaluates sum(c[i] * sin(2*i * x), i, 1, n) if sinp is true otherwise sum(c[i] * cos((2*i+1) * x), i, 0, n-1) using Clenshaw summation
+func sinCosSeries(sinp bool, sinx float64, cosx float64, c []float64) float64 {
+	k := len(c) // Point to one beyond last element
+	n := k
+	if sinp {
+		n--
+	}
+	ar := 2 * (cosx - sinx) * (cosx + sinx) // 2 * cos(2 * x)
+	var y0, y1 float64
+	if n&1 != 0 {
+		k--
+		y0 = c[k]
+	}
+	// Now n is even
+	for n /= 2; n > 0; n-- {
+		// Unroll loop x 2, so accumulators return to their original role
+		k--
+		y1 = ar*y0 - y1 + c[k]
+		k--
+		y0 = ar*y1 - y0 + c[k]
+	}
+	if sinp {
+		return 2 * sinx * cosx * y0 // sin(2 * x) * y0
+	}
+	return cosx * (y0 - y1) // cos(x) * (y0 - y1)
+}
+
+// polyval evaluates the polynomial of order n with the coefficients p (highest order first) at x
+func polyval(n int, p []float64, x float64) float64 {
+	if n < 0 {
+		return 0
+	}
+	y := p[0]
+	for i := 1; i <= n; i++ {
+		y = y*x + p[i]
+	}
+	return y
+}
+
+// sincosd returns the sine and cosine of x in degree with exact results for multiples of 90°
+func sincosd(x float64) (float64, float64) {
+	r := math.Mod(x, 360)
+	q := 0
+	if !math.IsNaN(r) {
+		q = int(math.RoundToEven(r / 90))
+	}
+	r -= 90 * float64(q)
+	r = r * math.Pi / 180
+	s, c := math.Sin(r), math.Cos(r)
+	switch ((q % 4) + 4) % 4 {
+	case 1:
+		s, c = c, -s
+	case 2:
+		s, c = -s, -c
+	case 3:
+		s, c = -c, s
+	}
+	if x != 0 {
+		s += 0
+		c += 0
+	}
+	return s, c
+}
+
+// atan2d returns atan2(y, x) in degree
+func atan2d(y float64, x float64) float64 {
+	q := 0
+	if math.Abs(y) > math.Abs(x) {
+		q = 2
+		x, y = y, x
+	}
+	if x < 0 {
+		q++
+		x = -x
+	}
+	ang := math.Atan2(y, x) * 180 / math.Pi
+	switch q {
+	case 1:
+		if y >= 0 {
+			ang = 180 - ang
+		} else {
+			ang = -180 - ang
+		}
+	case 2:
+		ang = 90 - ang
+	case 3:
+		ang = -90 + ang
+	}
+	return ang
+}
+
+// angRound rounds tiny angles so that small differences are not lost
+func angRound(x float64) float64 {
+	const z = 1.0 / 16
+	if x == 0 {
+		return 0
+	}
+	y := math.Abs(x)
+	// The compiler mustn't "simplify" z - (z - y) to y
+	if y < z {
+		y = z - (z - y)
+	}
+	if x < 0 {
+		return -y
+	}
+	return y
+}
+
+// angNormalize reduces the angle x to the range [-180, 180]
+func angNormalize(x float64) float64 {
+	y := math.Remainder(x, 360)
+	if y == -180 {
+		return 180
+	}
+	return y
+}
+
+// angDiff returns the exact difference y - x of two angles reduced to [-180, 180] and the error term
+func angDiff(x float64, y float64) (float64, float64) {
+	d, t := sumError(angNormalize(-x), angNormalize(y))
+	d = angNormalize(d)
+	if d == 180 && t > 0 {
+		d = -180
+	}
+	return sumError(d, t)
+}
+
+// sumError returns the sum of u and v and the rounding error of the sum
+func sumError(u float64, v float64) (float64, float64) {
+	s := u + v
+	up := s - v
+	vpp := s - up
+	up -= u
+	vpp -= v
+	t := -(up + vpp)
+	return s, t
+}
+
+// latFix returns NaN if the latitude is not in the range [-90, 90]
+func latFix(x float64) float64 {
+	if math.Abs(x) > 90 {
+		return math.NaN()
+	}
+	return x
+}
+
+func norm2(x float64, y float64) (float64, float64) {
+	r := math.Hypot(x, y)
+	return x / r, y / r
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,123 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+// karneyTestCase is a reference geodesic on the WGS-84 ellipsoid of the GeographicLib test data (GeodTest.dat and the GeodSolve tests)
+type karneyTestCase struct {
+	lat1, lon1, lat2, lon2 float64
+	distance               float64 // The distance (m)
+	azimuth1, azimuth2     float64 // The forward azimuths (degree); not checked if the points are nearly antipodal
+	nearlyAntipodal        bool
+}
+
+var karneyTestCases = []karneyTestCase{
+	// GeodTest.dat
+	{35.60777, -139.44815, -11.17491, -69.95921, 8935244.5604818305, 111.098748429560326, 129.289270889708762, false},
+	{55.52454, 106.05087, 77.03196, 197.18234, 4105086.1713924406, 22.020059880982801, 109.112041110671519, false},
+	{-21.97856, 142.59065, 41.84138, 98.56635, 8394328.894657671, -32.44456876433189, -41.84359951440466, false},
+	{-66.99028, 112.2363, -12.70631, 285.90344, 11150344.2312080241, 173.73491240878403, 2.512956620913668, false},
+	{-17.42761, 173.34268, -15.84784, 5.93557, 16076603.1631180673, -159.033557661192928, -20.787484651536988, false},
+	{32.84994, 48.28919, -56.28556, 202.29132, 16727068.9438164461, 150.492927788121982, 48.113449399816759, false},
+	{6.96833, 52.74123, -7.39675, 206.17291, 17102477.2496958388, 92.581585386317712, 90.721692165923907, false},
+	{-87.85331, 85.66836, 66.48646, 16.09921, 17286615.3147144645, -65.120313040242748, -4.888658719272296, false},
+	// GeodSolve6, GeodSolve9, GeodSolve10 and GeodSolve11: Nearly antipodal points (Vincenty does not converge); the distances are given to 1 mm
+	{88.202499451857, 0, -88.202499451857, 179.981022032992859592, 20003898.214, 0, 0, true},
+	{89.262080389218, 0, -89.262080389218, 179.992207982775375662, 20003925.854, 0, 0, true},
+	{89.333123580033, 0, -89.333123580032997687, 179.99295812360148422, 20003926.881, 0, 0, true},
+	{56.320923501171, 0, -56.320923501171, 179.664747671772880215, 19993558.287, 0, 0, true},
+	{52.784459512564, 0, -52.784459512563990912, 179.634407464943777557, 19991596.095, 0, 0, true},
+	{48.522876735459, 0, -48.52287673545898293, 179.599720456223079643, 19989144.774, 0, 0, true},
+}
+
+func TestKarneyInverse(t *testing.T) {
+	karney := NewKarney("Karney", EllipsoidWGS84)
+	for _, tc := range karneyTestCases {
+		distance, azimuth1, azimuth2 := karney.Inverse(tc.lat1, tc.lon1, tc.lat2, tc.lon2)
+		tolerance := 1e-6
+		if tc.nearlyAntipodal {
+			tolerance = 0.5e-3
+		}
+		if math.Abs(distance-tc.distance) > tolerance {
+			t.Errorf("Inverse(%v, %v, %v, %v): distance %.9f, want %.9f", tc.lat1, tc.lon1, tc.lat2, tc.lon2, distance, tc.distance)
+		}
+		if tc.nearlyAntipodal {
+			continue
+		}
+		if math.Abs(azimuth1-tc.azimuth1) > 1e-9 || math.Abs(azimuth2-tc.azimuth2) > 1e-9 {
+			t.Errorf("Inverse(%v, %v, %v, %v): azimuths %.12f / %.12f, want %.12f / %.12f", tc.lat1, tc.lon1, tc.lat2, tc.lon2, azimuth1, azimuth2, tc.azimuth1, tc.azimuth2)
+		}
+	}
+}
+
+func TestKarneyInverseEquator(t *testing.T) {
+	// GeodSolve33: Points on the equator; the geodesic of the points 180° apart runs over a pole
+	testCases := []struct {
+		lon2, distance, azimuth1, azimuth2 float64
+	}{
+		{179, 19926189, 90, 90},
+		{179.5, 19980862, 55.96650, 124.03350},
+		{180, 20003931, 0, 180},
+	}
+	for _, tc := range testCases {
+		distance, azimuth1, azimuth2 := KarneyInverse(0, 0, 0, tc.lon2, EllipsoidWGS84.SemiMajorAxisA, EllipsoidWGS84.Flattening)
+		if math.Abs(distance-tc.distance) > 0.5 || math.Abs(azimuth1-tc.azimuth1) > 0.5e-5 || math.Abs(azimuth2-tc.azimuth2) > 0.5e-5 {
+			t.Errorf("KarneyInverse(0, 0, 0, %v): %.1f, %.5f, %.5f, want %.0f, %.5f, %.5f", tc.lon2, distance, azimuth1, azimuth2, tc.distance, tc.azimuth1, tc.azimuth2)
+		}
+	}
+}
+
+func TestKarneyDistanceAndBearing(t *testing.T) {
+	karney := NewKarney("Karney", EllipsoidWGS84)
+	previousPoint := &Point{Latitude: 40.6, Longitude: -73.8}
+	p1 := &Point{Latitude: 49.01666667, Longitude: 2.55}
+	// GeodSolve0: JFK to CDG
+	distance, err := karney.Distance(p1, previousPoint)
+	if err != nil || math.Abs(distance-5853226) > 0.5 {
+		t.Errorf("Distance: %f (%v), want 5853226", distance, err)
+	}
+	bearing, err := karney.Bearing(p1, previousPoint)
+	if err != nil || math.Abs(bearing-53.47022) > 0.5e-5 {
+		t.Errorf("Bearing: %f (%v), want 53.47022", bearing, err)
+	}
+	if distance, err := karney.Distance(previousPoint, previousPoint); err != nil || distance != 0 {
+		t.Errorf("Distance of equal points: %f (%v), want 0", distance, err)
+	}
+	if _, err := karney.Bearing(previousPoint, previousPoint); err == nil {
+		t.Error("Bearing of equal points: want an error")
+	}
+	if _, err := karney.Distance(&Point{Latitude: 1, Longitude: math.NaN()}, previousPoint); err == nil {
+		t.Error("Distance of an invalid point: want an error")
+	}
+}
+
+func TestVincentyAgainstKarney(t *testing.T) {
+	// Vincenty's distance equals the geodesic distance within 1 mm if it converges; it does not converge for nearly antipodal points
+	vincenty := NewVincenty("Vincenty", EllipsoidWGS84)
+	for _, tc := range karneyTestCases {
+		previousPoint := &Point{Latitude: tc.lat1, Longitude: tc.lon1}
+		p1 := &Point{Latitude: tc.lat2, Longitude: tc.lon2}
+		distance, err := vincenty.Distance(p1, previousPoint)
+		if tc.nearlyAntipodal {
+			if err == nil && math.Abs(distance-tc.distance) < 1e-3 {
+				t.Errorf("Vincenty(%v, %v, %v, %v): %f, want an error or a wrong distance", tc.lat1, tc.lon1, tc.lat2, tc.lon2, distance)
+			}
+			continue
+		}
+		if err != nil || math.Abs(distance-tc.distance) > 1e-3 {
+			t.Errorf("Vincenty(%v, %v, %v, %v): %.6f (%v), want %.6f", tc.lat1, tc.lon1, tc.lat2, tc.lon2, distance, err, tc.distance)
+		}
+	}
+}
+
+func TestHaversineAgainstKarney(t *testing.T) {
+	// The distance on the sphere with the mean earth radius differs less than 0.5% from the geodesic distance on the ellipsoid
+	for _, tc := range karneyTestCases {
+		distance := HaversineDistance(tc.lat1, tc.lon1, tc.lat2, tc.lon2, EllipsoidSphere.SemiMajorAxisA)
+		if relative := math.Abs(distance-tc.distance) / tc.distance; relative > 0.005 {
+			t.Errorf("HaversineDistance(%v, %v, %v, %v): %.0f, want %.0f within 0.5%% (%.3f%%)", tc.lat1, tc.lon1, tc.lat2, tc.lon2, distance, tc.distance, relative*100)
+		}
+	}
+}
@@ -11,15 +11,35 @@ import (
 type Vincenty struct {
 	ShouldStandardDeviationBeUsed bool    // Should the standard deviation be used to determine which points are used for calculation
 	SigmaMultiplier               float64 // Define the sima standard deviation
+	GradeSmoothingWindow          float64 // Distance (m) of the window to smooth the grade and vertical speed; 0 disables the smoothing
 	OneDegree                     float64
-	EarthRadius                   float64
-	Flattening                    float64
-	SemiMinorAxisB                float64
+	Ellipsoid                     Ellipsoid // Reference ellipsoid; WGS-84 if not set
 	Epsilon                       float64
 	MaxIterations                 int
 	Name                          string
 }
 
+// Default values of the algorithms
+const (
+	DefaultSigmaMultiplier = 3.29053                 // ~99.9%
+	DefaultOneDegree       = 1000.0 * 10000.8 / 90.0 // Length of one degree in m
+	DefaultEpsilon         = 1e-12
+	DefaultMaxIterations   = 200
+)
+
+// NewVincenty returns the Vincenty algorithm for the ellipsoid (WGS-84 if the ellipsoid is not set) with default values
+func NewVincenty(name string, ellipsoid Ellipsoid) *Vincenty {
+	return &Vincenty{
+		ShouldStandardDeviationBeUsed: false,
+		SigmaMultiplier:               DefaultSigmaMultiplier,
+		OneDegree:                     DefaultOneDegree,
+		Ellipsoid:                     ellipsoid.orDefault(),
+		Epsilon:                       DefaultEpsilon,
+		MaxIterations:                 DefaultMaxIterations,
+		Name:                          name,
+	}
+}
+
 // String returns the name of the algorithm
 func (v *Vincenty) String() string {
 	return v.Name
@@ -35,6 +55,11 @@ func (v *Vincenty) Sigma() float64 {
 	return v.SigmaMultiplier
 }
 
+// GradeWindow (Vincenty) returns the distance (m) of the window to smooth the grade and vertical speed
+func (v *Vincenty) GradeWindow() float64 {
+	return v.GradeSmoothingWindow
+}
+
 // Duration (Vincenty) returns the time.Duration from point p1 to previousPoint in sec
 func (v *Vincenty) Duration(p1 *Point, previousPoint *Point) (float64, error) {
 	if p1.Timestamp.Valid && previousPoint.Timestamp.Valid {
@@ -64,16 +89,46 @@ func toRadians(deg float64) float64 {
 }
 
 // Distance (Vincenty) returns the geographical distance in km between the points p1 (lat1, long1) and p2 (lat2, long2) using Vincenty's inverse formula.
-// The surface of the Earth is approximated by the WGS-84 ellipsoid.
+// The surface of the Earth is approximated by the ellipsoid v.Ellipsoid (WGS-84 if not set).
 // This method may fail to converge for nearly antipodal points.
 // https://github.com/asmarques/geodist/blob/master/vincenty.go
 func (v *Vincenty) Distance(p1 *Point, previousPoint *Point) (float64, error) {
 	if p1.Latitude == previousPoint.Latitude && p1.Longitude == previousPoint.Longitude {
 		return 0, nil
 	}
+	distance, _, err := v.inverse(p1, previousPoint)
+	return distance, err
+}
+
+// Bearing (Vincenty) returns the initial bearing (forward azimuth) from the previousPoint to the point p1 using Vincenty's inverse formula
+func (v *Vincenty) Bearing(p1 *Point, previousPoint *Point) (float64, error) {
+	if p1.Latitude == previousPoint.Latitude && p1.Longitude == previousPoint.Longitude {
+		return 0, errors.New("Points are equal")
+	}
+	_, bearing, err := v.inverse(previousPoint, p1)
+	if err != nil {
+		return 0, err
+	}
+	return NormalizeBearing(bearing), nil
+}
+
+// inverse returns the distance and the forward azimuth (degree) at p1 from p1 to previousPoint using Vincenty's inverse formula
+func (v *Vincenty) inverse(p1 *Point, previousPoint *Point) (float64, float64, error) {
+	ellipsoid := v.Ellipsoid.orDefault()
+	flattening := ellipsoid.Flattening
+	semiMajorAxisA := ellipsoid.SemiMajorAxisA
+	semiMinorAxisB := ellipsoid.SemiMinorAxisB()
+	epsilon := v.Epsilon
+	if epsilon == 0 {
+		epsilon = DefaultEpsilon
+	}
+	maxIterations := v.MaxIterations
+	if maxIterations == 0 {
+		maxIterations = DefaultMaxIterations
+	}
 
-	U1 := math.Atan((1 - v.Flattening) * math.Tan(toRadians(p1.Latitude)))
-	U2 := math.Atan((1 - v.Flattening) * math.Tan(toRadians(previousPoint.Latitude)))
+	U1 := math.Atan((1 - flattening) * math.Tan(toRadians(p1.Latitude)))
+	U2 := math.Atan((1 - flattening) * math.Tan(toRadians(previousPoint.Latitude)))
 	L := toRadians(previousPoint.Longitude - p1.Longitude)
 	sinU1 := math.Sin(U1)
 	cosU1 := math.Cos(U1)
@@ -82,8 +137,9 @@ func (v *Vincenty) Distance(p1 *Point, previousPoint *Point) (float64, error) {
 	lambda := L
 
 	result := math.NaN()
+	azimuth := math.NaN()
 
-	for i := 0; i < v.MaxIterations; i++ {
+	for i := 0; i < maxIterations; i++ {
 		curLambda := lambda
 		sinSigma := math.Sqrt(math.Pow(cosU2*math.Sin(lambda), 2) +
 			math.Pow(cosU1*sinU2-sinU1*cosU2*math.Cos(lambda), 2))
@@ -95,29 +151,30 @@ func (v *Vincenty) Distance(p1 *Point, previousPoint *Point) (float64, error) {
 		if cosSqrAlpha != 0 {
 			cos2sigmam = math.Cos(sigma) - ((2 * sinU1 * sinU2) / cosSqrAlpha)
 		}
-		C := (v.Flattening / 16) * cosSqrAlpha * (4 + v.Flattening*(4-3*cosSqrAlpha))
-		lambda = L + (1-C)*v.Flattening*sinAlpha*(sigma+C*sinSigma*(cos2sigmam+C*cosSigma*(-1+2*math.Pow(cos2sigmam, 2))))
+		C := (flattening / 16) * cosSqrAlpha * (4 + flattening*(4-3*cosSqrAlpha))
+		lambda = L + (1-C)*flattening*sinAlpha*(sigma+C*sinSigma*(cos2sigmam+C*cosSigma*(-1+2*math.Pow(cos2sigmam, 2))))
 
-		if math.Abs(lambda-curLambda) < v.Epsilon {
-			uSqr := cosSqrAlpha * ((math.Pow(v.EarthRadius, 2) - math.Pow(v.SemiMinorAxisB, 2)) / math.Pow(v.SemiMinorAxisB, 2))
+		if math.Abs(lambda-curLambda) < epsilon {
+			uSqr := cosSqrAlpha * ((math.Pow(semiMajorAxisA, 2) - math.Pow(semiMinorAxisB, 2)) / math.Pow(semiMinorAxisB, 2))
 			k1 := (math.Sqrt(1+uSqr) - 1) / (math.Sqrt(1+uSqr) + 1)
 			A := (1 + (math.Pow(k1, 2) / 4)) / (1 - k1)
 			B := k1 * (1 - (3*math.Pow(k1, 2))/8)
 
 			deltaSigma := B * sinSigma * (cos2sigmam + (B/4)*(cosSigma*(-1+2*math.Pow(cos2sigmam, 2))-
 				(B/6)*cos2sigmam*(-3+4*math.Pow(sinSigma, 2))*(-3+4*math.Pow(cos2sigmam, 2))))
-			s := v.SemiMinorAxisB * A * (sigma - deltaSigma)
+			s := semiMinorAxisB * A * (sigma - deltaSigma)
 			result = s
+			azimuth = math.Atan2(cosU2*math.Sin(lambda), cosU1*sinU2-sinU1*cosU2*math.Cos(lambda)) * 180 / math.Pi
 
 			break
 		}
 	}
 
 	if math.IsNaN(result) {
-		return result, fmt.Errorf("Failed to converge for Point(Latitude: %f, Longitude: %f) and Point(Latitude: %f, Longitude: %f)", p1.Latitude, p1.Longitude, previousPoint.Latitude, previousPoint.Latitude)
+		return result, azimuth, fmt.Errorf("Failed to converge for Point(Latitude: %f, Longitude: %f) and Point(Latitude: %f, Longitude: %f)", p1.Latitude, p1.Longitude, previousPoint.Latitude, previousPoint.Latitude)
 	}
 
-	return result, nil
+	return result, azimuth, nil
 }
 
 // Speed (Vincenty) returns the speed in m/s
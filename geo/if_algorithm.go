@@ -12,6 +12,8 @@ type Algorithm interface {
 	ShouldStandardDeviation() bool
 	// Sigma defines the multiplier for the standard deviation to define x1 and x2 in which all points should be to define moving time/distance
 	Sigma() float64
+	// GradeWindow defines the distance (m) of the window to smooth the grade and vertical speed of the points; 0 disables the smoothing
+	GradeWindow() float64
 
 	// Return statement: Sshould the Point be included in the "MovingTime, MovingDistance" or "StoppedTime, StoppedDistance"
 	// The gxPoint.Point.MovingData must be set in this func (!)
@@ -21,6 +23,8 @@ type Algorithm interface {
 	Duration(p1 *Point, previousPoint *Point) (float64, error)
 	// Returns the duration between previous and actual point
 	Distance(p1 *Point, previousPoint *Point) (float64, error)
+	// Returns the initial bearing (forward azimuth in degree, 0 <= bearing < 360, clockwise from north) from the previous to the actual point
+	Bearing(p1 *Point, previousPoint *Point) (float64, error)
 	// Returns the speed between previous and actual point
 	Speed(distance float64, duration float64) (float64, error)
 	// Returns the pace between previous and actual point
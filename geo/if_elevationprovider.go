@@ -0,0 +1,7 @@
+package geo
+
+// ElevationProvider interface defines a source of the terrain elevation, e.g. a digital elevation model (see package dem)
+type ElevationProvider interface {
+	// Elevation returns the elevation (m) at the latitude / longitude; an error is returned if the provider does not have data at the location
+	Elevation(latitude float64, longitude float64) (float64, error)
+}
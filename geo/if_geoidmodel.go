@@ -0,0 +1,7 @@
+package geo
+
+// GeoidModel interface defines a geoid model like EGM96 or EGM2008 (see package dem)
+type GeoidModel interface {
+	// GeoidHeight returns the height (m) of the geoid (mean sea level) above the WGS84 ellipsoid at the latitude / longitude
+	GeoidHeight(latitude float64, longitude float64) (float64, error)
+}
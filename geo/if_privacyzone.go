@@ -0,0 +1,11 @@
+package geo
+
+import "math/rand"
+
+// PrivacyZone interface defines an area whose points are hidden by the PrivacyFilter
+type PrivacyZone interface {
+	// Contains returns if the location is inside the zone
+	Contains(location *Point) bool
+	// Randomize returns a zone which contains this zone and is extended by a random distance up to maxDistance (m) that the hidden location cannot be triangulated from the visible boundary
+	Randomize(maxDistance float64, random *rand.Rand) PrivacyZone
+}
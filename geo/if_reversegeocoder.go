@@ -0,0 +1,7 @@
+package geo
+
+// ReverseGeocoder interface defines the resolution of the place of a location, e.g. by a cities dataset (see package geonames)
+type ReverseGeocoder interface {
+	// ReverseGeocode returns the nearest place of the latitude / longitude; an error is returned if no place is found
+	ReverseGeocode(latitude float64, longitude float64) (*Place, error)
+}
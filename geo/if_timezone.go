@@ -0,0 +1,9 @@
+package geo
+
+import "time"
+
+// TimeZoneResolver interface defines the resolution of the time zone of a location
+type TimeZoneResolver interface {
+	// TimeZone returns the time zone of the latitude / longitude
+	TimeZone(latitude float64, longitude float64) (*time.Location, error)
+}
@@ -0,0 +1,104 @@
+package geo
+
+import (
+	"errors"
+	"math"
+)
+
+// Gender of the athlete; used for the Banister TRIMP
+type Gender int
+
+// Genders
+const (
+	GenderMale Gender = iota
+	GenderFemale
+)
+
+// HeartRateZoneType defines how the heart rate zone boundaries are interpreted
+type HeartRateZoneType int
+
+// Heart rate zone types
+const (
+	HeartRateZonePercentMax     HeartRateZoneType = iota // The boundaries are a ratio of the max heart rate (0.6 == 60%)
+	HeartRateZonePercentReserve                          // The boundaries are a ratio of the heart rate reserve (Karvonen): resting + ratio * (max - resting)
+	HeartRateZoneBpm                                     // The boundaries are explicit heart rates (bpm)
+)
+
+// DefaultHeartRateZoneBoundaries are the lower boundaries of the zones 2-5 as a ratio of the max heart rate
+var DefaultHeartRateZoneBoundaries = []float64{0.6, 0.7, 0.8, 0.9}
+
+// AthleteProfile contains the personal data of an athlete to calculate the heart rate zones and training load
+type AthleteProfile struct {
+	Name             string
+	Gender           Gender
+	MaxHeartRate     float64 // The max heart rate (bpm)
+	RestingHeartRate float64 // The resting heart rate (bpm)
+	Weight           float64 // The weight (kg); used for the energy expenditure
+
+	HeartRateZoneType       HeartRateZoneType
+	HeartRateZoneBoundaries []float64 // The ascending lower boundaries of the zones 2, 3, ...; zone 1 starts at 0 bpm; DefaultHeartRateZoneBoundaries (% of max) if empty
+}
+
+// NewAthleteProfile returns an athlete profile with the default heart rate zones
+func NewAthleteProfile(name string, gender Gender, maxHeartRate float64, restingHeartRate float64) *AthleteProfile {
+	return &AthleteProfile{
+		Name:             name,
+		Gender:           gender,
+		MaxHeartRate:     maxHeartRate,
+		RestingHeartRate: restingHeartRate,
+	}
+}
+
+// HeartRateZone represents a heart rate zone
+type HeartRateZone struct {
+	Number int     // The number of the zone starting with 1
+	Min    float64 // The min heart rate (bpm) of the zone (inclusive)
+	Max    float64 // The max heart rate (bpm) of the zone (exclusive); +Inf for the last zone
+}
+
+// HeartRateReserve returns the ratio of the heart rate reserve (heartRate - resting) / (max - resting)
+func (ap *AthleteProfile) HeartRateReserve(heartRate float64) (float64, error) {
+	if ap.MaxHeartRate <= ap.RestingHeartRate {
+		return 0, errors.New("Max heart rate must be greater than the resting heart rate")
+	}
+	return (heartRate - ap.RestingHeartRate) / (ap.MaxHeartRate - ap.RestingHeartRate), nil
+}
+
+// HeartRateZones returns the heart rate zones (bpm) of the athlete
+func (ap *AthleteProfile) HeartRateZones() ([]HeartRateZone, error) {
+	zoneType := ap.HeartRateZoneType
+	boundaries := ap.HeartRateZoneBoundaries
+	if len(boundaries) == 0 {
+		zoneType = HeartRateZonePercentMax
+		boundaries = DefaultHeartRateZoneBoundaries
+	}
+
+	zones := make([]HeartRateZone, len(boundaries)+1)
+	zones[0] = HeartRateZone{Number: 1, Min: 0}
+	for i, boundary := range boundaries {
+		var heartRate float64
+		switch zoneType {
+		case HeartRateZonePercentMax:
+			if ap.MaxHeartRate <= 0 {
+				return nil, errors.New("Max heart rate is not set")
+			}
+			heartRate = boundary * ap.MaxHeartRate
+		case HeartRateZonePercentReserve:
+			if ap.MaxHeartRate <= ap.RestingHeartRate {
+				return nil, errors.New("Max heart rate must be greater than the resting heart rate")
+			}
+			heartRate = ap.RestingHeartRate + boundary*(ap.MaxHeartRate-ap.RestingHeartRate)
+		case HeartRateZoneBpm:
+			heartRate = boundary
+		default:
+			return nil, errors.New("Unknown heart rate zone type")
+		}
+		if heartRate <= zones[i].Min {
+			return nil, errors.New("Heart rate zone boundaries must be ascending")
+		}
+		zones[i].Max = heartRate
+		zones[i+1] = HeartRateZone{Number: i + 2, Min: heartRate}
+	}
+	zones[len(zones)-1].Max = math.Inf(1)
+	return zones, nil
+}
@@ -0,0 +1,56 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHeartRateZones(t *testing.T) {
+	profile := NewAthleteProfile("Test", GenderMale, 200, 50)
+	testCases := []struct {
+		zoneType   HeartRateZoneType
+		boundaries []float64
+		mins       []float64
+	}{
+		{HeartRateZonePercentMax, nil, []float64{0, 120, 140, 160, 180}},
+		{HeartRateZonePercentReserve, []float64{0.5, 0.8}, []float64{0, 125, 170}},
+		{HeartRateZoneBpm, []float64{100, 150}, []float64{0, 100, 150}},
+	}
+	for _, tc := range testCases {
+		profile.HeartRateZoneType = tc.zoneType
+		profile.HeartRateZoneBoundaries = tc.boundaries
+		zones, err := profile.HeartRateZones()
+		if err != nil || len(zones) != len(tc.mins) {
+			t.Fatalf("HeartRateZones(%v): %v (%v), want %d zones", tc.zoneType, zones, err, len(tc.mins))
+		}
+		for index, zone := range zones {
+			if zone.Number != index+1 || math.Abs(zone.Min-tc.mins[index]) > 1e-9 {
+				t.Errorf("HeartRateZones(%v): Zone %d %+v, want min %f", tc.zoneType, index, zone, tc.mins[index])
+			}
+			if index > 0 && zones[index-1].Max != zone.Min {
+				t.Errorf("HeartRateZones(%v): Zone %d ends at %f, want %f", tc.zoneType, index, zones[index-1].Max, zone.Min)
+			}
+		}
+		if last := zones[len(zones)-1]; !math.IsInf(last.Max, 1) {
+			t.Errorf("HeartRateZones(%v): The last zone ends at %f, want +Inf", tc.zoneType, last.Max)
+		}
+	}
+
+	profile.HeartRateZoneType = HeartRateZoneBpm
+	profile.HeartRateZoneBoundaries = []float64{150, 100}
+	if _, err := profile.HeartRateZones(); err == nil {
+		t.Error("HeartRateZones with descending boundaries: want an error")
+	}
+	if _, err := NewAthleteProfile("Test", GenderMale, 0, 50).HeartRateZones(); err == nil {
+		t.Error("HeartRateZones without max heart rate: want an error")
+	}
+}
+
+func TestHeartRateReserve(t *testing.T) {
+	if ratio, err := NewAthleteProfile("Test", GenderMale, 200, 50).HeartRateReserve(125); err != nil || ratio != 0.5 {
+		t.Errorf("HeartRateReserve: %f (%v), want 0.5", ratio, err)
+	}
+	if _, err := NewAthleteProfile("Test", GenderMale, 50, 50).HeartRateReserve(125); err == nil {
+		t.Error("HeartRateReserve with max == resting: want an error")
+	}
+}
@@ -0,0 +1,168 @@
+package geo
+
+// Default targets of the best efforts
+var (
+	BestEffortDistances = []float64{400, 1000, SplitMile, 5000, 10000, 21097.5, 42195} // m
+	BestEffortDurations = []float64{60, 300, 1200, 3600}                               // sec
+)
+
+// BestEffortOptions defines which windows of points are used for the best efforts
+type BestEffortOptions struct {
+	MaxGap     float64 // The max duration (sec) from one point to the next point in a window; 0 allows any gap
+	MovingTime bool    // Should only the moving points be used; the distance and duration of the stopped points are excluded from the windows
+}
+
+// BestEffort represents the fastest window of points for a target distance or duration
+type BestEffort struct {
+	TargetDistance float64 // The target distance (m); 0 if the target is a duration
+	TargetDuration float64 // The target duration (sec); 0 if the target is a distance
+
+	TrackIndex   int // The index of the track in GPX.Tracks
+	SegmentIndex int // The index of the segment in GPXTrack.Segments
+	StartIndex   int // The index of the first point of the window in GPXTrackSegment.Points
+	EndIndex     int // The index of the last point of the window in GPXTrackSegment.Points
+
+	StartTime NullTime
+	EndTime   NullTime
+
+	Distance float64 // The distance (m) of the window
+	Duration float64 // The duration (sec) of the window
+	Speed    float64 // The speed (m/s) of the window
+	Pace     float64 // The pace (s/m) of the window
+
+	EstimatedDistance float64 // The distance (m) at the speed of the window for the target duration; the target distance for a distance target
+	EstimatedDuration float64 // The duration (sec) at the speed of the window for the target distance; the target duration for a duration target
+}
+
+// BestEfforts returns the best efforts of the segment for the target distances (m) and durations (sec); targets which are longer than the segment are not returned
+func (seg *GPXTrackSegment) BestEfforts(distances []float64, durations []float64, options BestEffortOptions, alg Algorithm) []BestEffort {
+	var result []BestEffort
+	for _, distance := range distances {
+		if bestEffort, ok := seg.bestEffort(distance, true, options, alg); ok {
+			result = append(result, bestEffort)
+		}
+	}
+	for _, duration := range durations {
+		if bestEffort, ok := seg.bestEffort(duration, false, options, alg); ok {
+			result = append(result, bestEffort)
+		}
+	}
+	return result
+}
+
+// BestEfforts returns the best efforts of all segments of all tracks for the target distances (m) and durations (sec); a window does not span two segments
+func (gpx *GPX) BestEfforts(distances []float64, durations []float64, options BestEffortOptions, alg Algorithm) []BestEffort {
+	var result []BestEffort
+	add := func(target float64, byDistance bool) {
+		var best BestEffort
+		found := false
+		for trackNo := range gpx.Tracks {
+			for segmentNo := range gpx.Tracks[trackNo].Segments {
+				bestEffort, ok := gpx.Tracks[trackNo].Segments[segmentNo].bestEffort(target, byDistance, options, alg)
+				if !ok {
+					continue
+				}
+				if !found || (byDistance && bestEffort.EstimatedDuration < best.EstimatedDuration) || (!byDistance && bestEffort.EstimatedDistance > best.EstimatedDistance) {
+					bestEffort.TrackIndex = trackNo
+					bestEffort.SegmentIndex = segmentNo
+					best = bestEffort
+					found = true
+				}
+			}
+		}
+		if found {
+			result = append(result, best)
+		}
+	}
+	for _, distance := range distances {
+		add(distance, true)
+	}
+	for _, duration := range durations {
+		add(duration, false)
+	}
+	return result
+}
+
+// bestEffort returns the fastest window of the segment with at least the target distance (byDistance == true) or the target duration (byDistance == false).
+// The windows are searched with two pointers: For each end point the start point is moved forward as long as the window still reaches the target.
+func (seg *GPXTrackSegment) bestEffort(target float64, byDistance bool, options BestEffortOptions, alg Algorithm) (BestEffort, bool) {
+	var best BestEffort
+	found := false
+	if target <= 0 || len(seg.Points) < 2 {
+		return best, found
+	}
+
+	// Cumulative distance and duration of the points used for the windows
+	distances := make([]float64, len(seg.Points))
+	durations := make([]float64, len(seg.Points))
+	values := durations
+	if byDistance {
+		values = distances
+	}
+
+	start := 0
+	for end := 1; end < len(seg.Points); end++ {
+		point := &seg.Points[end]
+		distances[end] = distances[end-1]
+		durations[end] = durations[end-1]
+
+		// A gap ends all windows; the next window starts at this point
+		if options.MaxGap > 0 && point.Duration > options.MaxGap {
+			start = end
+			continue
+		}
+		if !options.MovingTime || point.IsMoving {
+			distances[end] += point.Distance
+			durations[end] += point.Duration
+		}
+
+		for start+1 < end && values[end]-values[start+1] >= target {
+			start++
+		}
+		if values[end]-values[start] < target {
+			continue
+		}
+
+		distance := distances[end] - distances[start]
+		duration := durations[end] - durations[start]
+		if distance <= 0 || duration <= 0 {
+			continue
+		}
+		estimatedDuration, estimatedDistance := target, target
+		if byDistance {
+			estimatedDuration = duration * target / distance
+		} else {
+			estimatedDistance = distance * target / duration
+		}
+		if found && ((byDistance && estimatedDuration >= best.EstimatedDuration) || (!byDistance && estimatedDistance <= best.EstimatedDistance)) {
+			continue
+		}
+
+		found = true
+		best = BestEffort{
+			StartIndex:        start,
+			EndIndex:          end,
+			StartTime:         seg.Points[start].Timestamp,
+			EndTime:           point.Timestamp,
+			Distance:          distance,
+			Duration:          duration,
+			EstimatedDistance: estimatedDistance,
+			EstimatedDuration: estimatedDuration,
+		}
+		if byDistance {
+			best.TargetDistance = target
+		} else {
+			best.TargetDuration = target
+		}
+	}
+
+	if found {
+		if speed, err := alg.Speed(best.Distance, best.Duration); err == nil {
+			best.Speed = speed
+		}
+		if pace, err := alg.Pace(best.Distance, best.Duration); err == nil {
+			best.Pace = pace
+		}
+	}
+	return best, found
+}
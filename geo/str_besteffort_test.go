@@ -0,0 +1,93 @@
+package geo
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// testLegSegment returns a segment of moving points 100 m apart with the durations (sec) of the legs
+func testLegSegment(durations ...float64) *GPXTrackSegment {
+	seg := &GPXTrackSegment{Points: make([]GPXPoint, len(durations)+1)}
+	t := testStart
+	seg.Points[0].Timestamp.SetTime(&t)
+	seg.Points[0].IsMoving = true
+	for index, duration := range durations {
+		point := &seg.Points[index+1]
+		t = t.Add(time.Duration(duration * float64(time.Second)))
+		pointTime := t
+		point.Timestamp.SetTime(&pointTime)
+		point.Distance = 100
+		point.Duration = duration
+		point.IsMoving = true
+	}
+	return seg
+}
+
+func TestBestEfforts(t *testing.T) {
+	alg := NewKarney("Karney", EllipsoidWGS84)
+	seg := testLegSegment(30, 30, 30, 30, 20, 20, 20, 30, 30)
+	// 1000 m is longer than the segment (900 m)
+	efforts := seg.BestEfforts([]float64{300, 1000}, []float64{60}, BestEffortOptions{}, alg)
+	if len(efforts) != 2 {
+		t.Fatalf("BestEfforts: %d, want 2", len(efforts))
+	}
+
+	distance := efforts[0]
+	if distance.TargetDistance != 300 || distance.StartIndex != 4 || distance.EndIndex != 7 || distance.Duration != 60 || distance.EstimatedDuration != 60 {
+		t.Errorf("300 m: %+v, want the points 4 - 7 in 60 sec", distance)
+	}
+	if !distance.StartTime.Time.Equal(testStart.Add(120*time.Second)) || !distance.EndTime.Time.Equal(testStart.Add(180*time.Second)) {
+		t.Errorf("300 m: %v - %v, want 120 sec - 180 sec", distance.StartTime.Time, distance.EndTime.Time)
+	}
+	if math.Abs(distance.Speed-5) > 1e-9 || math.Abs(distance.Pace-0.2) > 1e-9 {
+		t.Errorf("300 m: Speed %f / pace %f, want 5 / 0.2", distance.Speed, distance.Pace)
+	}
+
+	duration := efforts[1]
+	if duration.TargetDuration != 60 || duration.StartIndex != 4 || duration.EndIndex != 7 || duration.EstimatedDistance != 300 {
+		t.Errorf("60 sec: %+v, want the points 4 - 7 with 300 m", duration)
+	}
+}
+
+func TestBestEffortsGap(t *testing.T) {
+	alg := NewKarney("Karney", EllipsoidWGS84)
+	seg := testLegSegment(30, 20, 200, 20, 30)
+	// Without a max gap the fastest 300 m spans the gap
+	efforts := seg.BestEfforts([]float64{300}, nil, BestEffortOptions{}, alg)
+	if len(efforts) != 1 || efforts[0].StartIndex != 1 || efforts[0].Duration != 240 {
+		t.Errorf("Without max gap: %+v, want the points 1 - 4 in 240 sec", efforts)
+	}
+	// With a max gap there is no window of 300 m
+	efforts = seg.BestEfforts([]float64{300}, nil, BestEffortOptions{MaxGap: 100}, alg)
+	if len(efforts) != 0 {
+		t.Errorf("With max gap: %+v, want none", efforts)
+	}
+}
+
+func TestBestEffortsMovingTime(t *testing.T) {
+	alg := NewKarney("Karney", EllipsoidWGS84)
+	seg := testLegSegment(20, 20, 300, 20)
+	seg.Points[3].Distance = 0
+	seg.Points[3].IsMoving = false
+	efforts := seg.BestEfforts([]float64{300}, nil, BestEffortOptions{}, alg)
+	if len(efforts) != 1 || efforts[0].Duration != 360 {
+		t.Errorf("Elapsed time: %+v, want 360 sec", efforts)
+	}
+	efforts = seg.BestEfforts([]float64{300}, nil, BestEffortOptions{MovingTime: true}, alg)
+	if len(efforts) != 1 || efforts[0].Duration != 60 {
+		t.Errorf("Moving time: %+v, want 60 sec", efforts)
+	}
+}
+
+func TestGPXBestEfforts(t *testing.T) {
+	alg := NewKarney("Karney", EllipsoidWGS84)
+	gpx := &GPX{Tracks: []GPXTrack{
+		{Segments: []GPXTrackSegment{*testLegSegment(30, 30, 30)}},
+		{Segments: []GPXTrackSegment{*testLegSegment(40, 40), *testLegSegment(20, 30, 20)}},
+	}}
+	efforts := gpx.BestEfforts([]float64{200}, nil, BestEffortOptions{}, alg)
+	if len(efforts) != 1 || efforts[0].TrackIndex != 1 || efforts[0].SegmentIndex != 1 || efforts[0].Duration != 50 {
+		t.Errorf("BestEfforts: %+v, want track 1, segment 1 in 50 sec", efforts)
+	}
+}
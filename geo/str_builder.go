@@ -0,0 +1,253 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/mbecker/gpxs/generic"
+)
+
+// GPXBuilder builds a GPX from points, e.g. from sensor data: NewGPX().Track(name).Segment().Point(lat, lon, ele, time)...Build(algorithm).
+// The points are added to the last track and segment; a track / segment is started if there is none. The first invalid value is returned by Build.
+type GPXBuilder struct {
+	gpx   *GPX
+	point *GPXPoint // The last added point of Point, RoutePoint or Waypoint for the point setters like HeartRate
+	err   error
+}
+
+// NewGPX returns a builder of an empty GPX (version 1.1)
+func NewGPX() *GPXBuilder {
+	return &GPXBuilder{
+		gpx: &GPX{
+			Version: "1.1",
+			Creator: "gpxs",
+		},
+	}
+}
+
+// setError sets the error if there is no previous error
+func (b *GPXBuilder) setError(err error) *GPXBuilder {
+	if b.err == nil {
+		b.err = err
+	}
+	return b
+}
+
+// Name sets the name of the gpx
+func (b *GPXBuilder) Name(name string) *GPXBuilder {
+	b.gpx.Name = name
+	return b
+}
+
+// Description sets the description of the gpx
+func (b *GPXBuilder) Description(description string) *GPXBuilder {
+	b.gpx.Description = description
+	return b
+}
+
+// Creator sets the creator of the gpx
+func (b *GPXBuilder) Creator(creator string) *GPXBuilder {
+	b.gpx.Creator = creator
+	return b
+}
+
+// Time sets the time of the gpx
+func (b *GPXBuilder) Time(t time.Time) *GPXBuilder {
+	b.gpx.Timestamp = &t
+	return b
+}
+
+// Track starts a new track with the name
+func (b *GPXBuilder) Track(name string) *GPXBuilder {
+	b.gpx.Tracks = append(b.gpx.Tracks, GPXTrack{
+		Name:   name,
+		Number: len(b.gpx.Tracks),
+	})
+	b.point = nil
+	return b
+}
+
+// Type sets the activity type (e.g. ActivityTypeRunning) of the last track; the type of the gpx is the type of the first track
+func (b *GPXBuilder) Type(activityType string) *GPXBuilder {
+	if len(b.gpx.Tracks) == 0 {
+		b.Track("")
+	}
+	b.gpx.Tracks[len(b.gpx.Tracks)-1].Type = activityType
+	return b
+}
+
+// Segment starts a new segment of the last track
+func (b *GPXBuilder) Segment() *GPXBuilder {
+	if len(b.gpx.Tracks) == 0 {
+		b.Track("")
+	}
+	track := &b.gpx.Tracks[len(b.gpx.Tracks)-1]
+	track.Segments = append(track.Segments, GPXTrackSegment{})
+	b.point = nil
+	return b
+}
+
+// newPoint returns the point; the elevation is null if it is NaN and the time is null if it is zero
+func (b *GPXBuilder) newPoint(latitude float64, longitude float64, elevation float64, t time.Time) (GPXPoint, error) {
+	var point GPXPoint
+	if math.IsNaN(latitude) || latitude < -90 || latitude > 90 {
+		return point, fmt.Errorf("Invalid latitude %v", latitude)
+	}
+	if math.IsNaN(longitude) || longitude < -180 || longitude > 180 {
+		return point, fmt.Errorf("Invalid longitude %v", longitude)
+	}
+	if math.IsInf(elevation, 0) {
+		return point, fmt.Errorf("Invalid elevation %v", elevation)
+	}
+	point.Latitude = latitude
+	point.Longitude = longitude
+	if !math.IsNaN(elevation) {
+		point.Elevation = *generic.NewNullableFloat64(elevation)
+	}
+	if !t.IsZero() {
+		point.Timestamp.SetTime(&t)
+	}
+	point.IsMoving = true
+	return point, nil
+}
+
+// Point adds a point to the last segment; use math.NaN() for a point without an elevation and time.Time{} for a point without a time.
+// The time must not be before the time of the previous point of the segment.
+func (b *GPXBuilder) Point(latitude float64, longitude float64, elevation float64, t time.Time) *GPXBuilder {
+	point, err := b.newPoint(latitude, longitude, elevation, t)
+	if err != nil {
+		return b.setError(err)
+	}
+	if len(b.gpx.Tracks) == 0 || len(b.gpx.Tracks[len(b.gpx.Tracks)-1].Segments) == 0 {
+		b.Segment()
+	}
+	track := &b.gpx.Tracks[len(b.gpx.Tracks)-1]
+	seg := &track.Segments[len(track.Segments)-1]
+	if len(seg.Points) > 0 && point.Timestamp.Valid {
+		if previous := &seg.Points[len(seg.Points)-1]; previous.Timestamp.Valid && point.Timestamp.Time.Before(*previous.Timestamp.Time) {
+			return b.setError(fmt.Errorf("Time %v is before the time of the previous point", t))
+		}
+	}
+	seg.Points = append(seg.Points, point)
+	b.point = &seg.Points[len(seg.Points)-1]
+	return b
+}
+
+// Route starts a new route with the name
+func (b *GPXBuilder) Route(name string) *GPXBuilder {
+	b.gpx.Routes = append(b.gpx.Routes, GPXRoute{
+		Name:   name,
+		Number: len(b.gpx.Routes),
+	})
+	b.point = nil
+	return b
+}
+
+// RoutePoint adds a point to the last route; use math.NaN() for a point without an elevation
+func (b *GPXBuilder) RoutePoint(latitude float64, longitude float64, elevation float64) *GPXBuilder {
+	point, err := b.newPoint(latitude, longitude, elevation, time.Time{})
+	if err != nil {
+		return b.setError(err)
+	}
+	if len(b.gpx.Routes) == 0 {
+		b.Route("")
+	}
+	route := &b.gpx.Routes[len(b.gpx.Routes)-1]
+	route.Points = append(route.Points, point)
+	b.point = &route.Points[len(route.Points)-1]
+	return b
+}
+
+// Waypoint adds a waypoint with the name; use math.NaN() for a waypoint without an elevation and time.Time{} for a waypoint without a time
+func (b *GPXBuilder) Waypoint(name string, latitude float64, longitude float64, elevation float64, t time.Time) *GPXBuilder {
+	point, err := b.newPoint(latitude, longitude, elevation, t)
+	if err != nil {
+		return b.setError(err)
+	}
+	point.Name = name
+	b.gpx.Waypoints = append(b.gpx.Waypoints, point)
+	b.point = &b.gpx.Waypoints[len(b.gpx.Waypoints)-1]
+	return b
+}
+
+// lastPoint returns the last added point or sets an error if there is none
+func (b *GPXBuilder) lastPoint() *GPXPoint {
+	if b.point == nil {
+		b.setError(errors.New("No point added"))
+	}
+	return b.point
+}
+
+// HeartRate sets the heart rate (bpm) of the last added point
+func (b *GPXBuilder) HeartRate(heartRate int) *GPXBuilder {
+	if heartRate < 0 {
+		return b.setError(fmt.Errorf("Invalid heart rate %d", heartRate))
+	}
+	if point := b.lastPoint(); point != nil {
+		point.HeartRate.SetValue(heartRate)
+	}
+	return b
+}
+
+// Cadence sets the cadence (rpm) of the last added point
+func (b *GPXBuilder) Cadence(cadence int) *GPXBuilder {
+	if cadence < 0 {
+		return b.setError(fmt.Errorf("Invalid cadence %d", cadence))
+	}
+	if point := b.lastPoint(); point != nil {
+		point.Cadence.SetValue(cadence)
+	}
+	return b
+}
+
+// Power sets the power (W) of the last added point
+func (b *GPXBuilder) Power(power float64) *GPXBuilder {
+	if math.IsNaN(power) || math.IsInf(power, 0) || power < 0 {
+		return b.setError(fmt.Errorf("Invalid power %v", power))
+	}
+	if point := b.lastPoint(); point != nil {
+		point.Power.SetValue(power)
+	}
+	return b
+}
+
+// Temperature sets the air temperature (°C) of the last added point
+func (b *GPXBuilder) Temperature(temperature float64) *GPXBuilder {
+	if math.IsNaN(temperature) || math.IsInf(temperature, 0) {
+		return b.setError(fmt.Errorf("Invalid temperature %v", temperature))
+	}
+	if point := b.lastPoint(); point != nil {
+		point.Temperature.SetValue(temperature)
+	}
+	return b
+}
+
+// BarometricElevation sets the barometric altitude (m) of the last added point
+func (b *GPXBuilder) BarometricElevation(elevation float64) *GPXBuilder {
+	if math.IsNaN(elevation) || math.IsInf(elevation, 0) {
+		return b.setError(fmt.Errorf("Invalid barometric elevation %v", elevation))
+	}
+	if point := b.lastPoint(); point != nil {
+		point.BarometricElevation.SetValue(elevation)
+	}
+	return b
+}
+
+// Build returns the gpx with the statistics of the algorithm (see Recalculate) or the first invalid value. The builder must not be used after Build.
+func (b *GPXBuilder) Build(algorithm Algorithm) (*GPX, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	gpx := b.gpx
+	if len(gpx.Name) == 0 && len(gpx.Tracks) > 0 {
+		gpx.Name = gpx.Tracks[0].Name
+	}
+	Recalculate(gpx, algorithm)
+	if gpx.Timestamp == nil && gpx.MovementStats.OverallData.StartTime.Valid {
+		start := *gpx.MovementStats.OverallData.StartTime.Time
+		gpx.Timestamp = &start
+	}
+	return gpx, nil
+}
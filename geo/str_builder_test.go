@@ -0,0 +1,79 @@
+package geo
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGPXBuilder(t *testing.T) {
+	gpx, err := NewGPX().Creator("Test").
+		Track("Morning Ride").Type(ActivityTypeCycling).
+		Point(50, 8, 100, testStart).HeartRate(120).Cadence(80).Power(200).Temperature(18.5).BarometricElevation(101).
+		Point(50.001, 8, math.NaN(), testStart.Add(10*time.Second)).
+		Segment().
+		Point(50.002, 8, 110, time.Time{}).
+		Route("Plan").RoutePoint(50, 8, 100).RoutePoint(50.002, 8, math.NaN()).
+		Waypoint("Start", 50, 8, math.NaN(), testStart).
+		Build(NewKarney("Karney", EllipsoidWGS84))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gpx.Name != "Morning Ride" || gpx.Creator != "Test" || gpx.Version != "1.1" || gpx.Timestamp == nil || !gpx.Timestamp.Equal(testStart) {
+		t.Errorf("GPX: Name %q / creator %q / version %q / time %v", gpx.Name, gpx.Creator, gpx.Version, gpx.Timestamp)
+	}
+	if len(gpx.Tracks) != 1 || gpx.Tracks[0].Type != ActivityTypeCycling || len(gpx.Tracks[0].Segments) != 2 {
+		t.Fatalf("Tracks: %+v, want one cycling track with 2 segments", gpx.Tracks)
+	}
+	first := gpx.Tracks[0].Segments[0].Points[0]
+	if first.HeartRate.Value() != 120 || first.Cadence.Value() != 80 || first.Power.Value() != 200 || first.Temperature.Value() != 18.5 || first.BarometricElevation.Value() != 101 {
+		t.Errorf("First point: %+v", first)
+	}
+	// NaN is a point without an elevation, a zero time is a point without a time
+	if second := gpx.Tracks[0].Segments[0].Points[1]; second.Elevation.NotNull() || second.HeartRate.NotNull() {
+		t.Errorf("Second point: Elevation %v / heart rate %v, want null", second.Elevation.Value(), second.HeartRate.Value())
+	}
+	if third := gpx.Tracks[0].Segments[1].Points[0]; third.Timestamp.Valid || third.Elevation.Value() != 110 {
+		t.Errorf("Third point: Time %v / elevation %v, want no time / 110", third.Timestamp.Time, third.Elevation.Value())
+	}
+	// The statistics are calculated
+	if distance := gpx.Tracks[0].Segments[0].Points[1].Distance; math.Abs(distance-111.25) > 0.1 {
+		t.Errorf("Distance of the second point: %f, want 111.25", distance)
+	}
+	if len(gpx.Routes) != 1 || gpx.Routes[0].Name != "Plan" || len(gpx.Routes[0].Points) != 2 || gpx.Routes[0].Points[1].Elevation.NotNull() {
+		t.Errorf("Routes: %+v, want the route Plan with 2 points", gpx.Routes)
+	}
+	if len(gpx.Waypoints) != 1 || gpx.Waypoints[0].Name != "Start" || !gpx.Waypoints[0].Timestamp.Valid {
+		t.Errorf("Waypoints: %+v, want the waypoint Start", gpx.Waypoints)
+	}
+}
+
+func TestGPXBuilderErrors(t *testing.T) {
+	alg := NewKarney("Karney", EllipsoidWGS84)
+	testCases := []struct {
+		name    string
+		builder *GPXBuilder
+		err     string
+	}{
+		{"Latitude", NewGPX().Point(91, 8, 0, testStart), "Invalid latitude"},
+		{"Longitude", NewGPX().Point(50, math.NaN(), 0, testStart), "Invalid longitude"},
+		{"Elevation", NewGPX().RoutePoint(50, 8, math.Inf(1)), "Invalid elevation"},
+		{"Time", NewGPX().Point(50, 8, 0, testStart).Point(50, 8, 0, testStart.Add(-time.Second)), "is before the time of the previous point"},
+		{"No point", NewGPX().Track("Run").HeartRate(120), "No point added"},
+		{"Heart rate", NewGPX().Point(50, 8, 0, testStart).HeartRate(-1), "Invalid heart rate"},
+		{"Power", NewGPX().Point(50, 8, 0, testStart).Power(math.NaN()), "Invalid power"},
+		// The first error is returned
+		{"First error", NewGPX().Point(50, 200, 0, testStart).Point(-100, 8, 0, testStart), "Invalid longitude"},
+	}
+	for _, tc := range testCases {
+		if _, err := tc.builder.Build(alg); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: %v, want %q", tc.name, err, tc.err)
+		}
+	}
+
+	// The time of a new segment does not depend on the previous segment
+	if _, err := NewGPX().Point(50, 8, 0, testStart).Segment().Point(50, 8, 0, testStart.Add(-time.Hour)).Build(alg); err != nil {
+		t.Errorf("Time in a new segment: %v, want no error", err)
+	}
+}
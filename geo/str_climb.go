@@ -0,0 +1,233 @@
+package geo
+
+import (
+	"fmt"
+	"time"
+)
+
+// ClimbCategory defines the category of a climb by the score length (m) * grade (%)
+type ClimbCategory int
+
+// Climb categories
+const (
+	ClimbCategoryNone ClimbCategory = iota // Score < 8000
+	ClimbCategory4                         // Score >= 8000
+	ClimbCategory3                         // Score >= 16000
+	ClimbCategory2                         // Score >= 32000
+	ClimbCategory1                         // Score >= 64000
+	ClimbCategoryHC                        // Score >= 80000; hors catégorie
+)
+
+// climbCategoryScores defines the min score of the categories ClimbCategory4 ... ClimbCategoryHC
+var climbCategoryScores = []float64{8000, 16000, 32000, 64000, 80000}
+
+// String returns the name of the category
+func (c ClimbCategory) String() string {
+	switch c {
+	case ClimbCategory4, ClimbCategory3, ClimbCategory2, ClimbCategory1:
+		return fmt.Sprintf("Cat %d", int(ClimbCategoryHC-c))
+	case ClimbCategoryHC:
+		return "HC"
+	}
+	return "Uncategorized"
+}
+
+// ClimbCategoryByScore returns the category of the score length (m) * grade (%)
+func ClimbCategoryByScore(score float64) ClimbCategory {
+	category := ClimbCategoryNone
+	for i, minScore := range climbCategoryScores {
+		if score >= minScore {
+			category = ClimbCategory(i + 1)
+		}
+	}
+	return category
+}
+
+// ClimbOptions defines which sections are detected as a climb
+type ClimbOptions struct {
+	MinGain         float64 // The min elevation gain (m) of a climb
+	MinGrade        float64 // The min average grade (0.03 == 3%) of a climb
+	MaxDip          float64 // The max elevation loss (m) from the highest point within a climb; a larger loss ends the climb
+	MaxFlatDistance float64 // The max distance (m) after the top of a climb with an average grade below MinGrade; a longer flat (or slightly rising) section ends the climb at the top. 0 disables the check
+	MaxGradeWindow  float64 // The min distance (m) of the sections to calculate the max grade; 0 uses each leg from one point to the next point
+}
+
+// DefaultClimbOptions are the default options to detect climbs
+var DefaultClimbOptions = ClimbOptions{
+	MinGain:         30,
+	MinGrade:        0.03,
+	MaxDip:          10,
+	MaxFlatDistance: 500,
+	MaxGradeWindow:  100,
+}
+
+// Climb represents a continuous section with an elevation gain
+type Climb struct {
+	StartSegmentIndex int // The index of the segment of the first point; always 0 for a route
+	StartIndex        int // The index of the first point in the segment (route)
+	EndSegmentIndex   int // The index of the segment of the last point; always 0 for a route
+	EndIndex          int // The index of the last point in the segment (route)
+
+	StartDistance  float64 // The distance (m) from the start of the track (route) to the start of the climb
+	Length         float64 // The distance (m) of the climb
+	StartElevation float64 // The elevation (m) of the first point
+	EndElevation   float64 // The elevation (m) of the last (and highest) point
+	ElevationGain  float64 // EndElevation - StartElevation (m)
+
+	AverageGrade float64 // ElevationGain / Length (0.05 == 5%)
+	MaxGrade     float64 // The max grade of the sections of the climb (see ClimbOptions.MaxGradeWindow)
+
+	StartTime NullTime
+	EndTime   NullTime
+	Duration  float64 // The duration (sec) of the climb; 0 if the points do not have a time
+	VAM       float64 // The vertical ascent rate (m/h); 0 if the points do not have a time
+
+	Score    float64 // Length (m) * AverageGrade (%)
+	Category ClimbCategory
+}
+
+// climbProfilePoint is a point with an elevation of the elevation profile
+type climbProfilePoint struct {
+	segmentIndex int
+	index        int
+	distance     float64 // The distance (m) from the start
+	elevation    float64
+	timestamp    NullTime
+}
+
+// Climbs returns the climbs of the track; the point data must be set (see Point.SetPointData)
+func (track *GPXTrack) Climbs(options ClimbOptions) []Climb {
+	var profile []climbProfilePoint
+	var distance float64
+	for segmentNo := range track.Segments {
+		for index := range track.Segments[segmentNo].Points {
+			point := &track.Segments[segmentNo].Points[index]
+			if index > 0 {
+				distance += point.Distance
+			}
+			if point.Elevation.NotNull() {
+				profile = append(profile, climbProfilePoint{segmentNo, index, distance, point.Elevation.Value(), point.Timestamp})
+			}
+		}
+	}
+	return detectClimbs(profile, options)
+}
+
+// Climbs returns the climbs of the route; the distance between the points is calculated with the algorithm
+func (route *GPXRoute) Climbs(options ClimbOptions, alg Algorithm) []Climb {
+	var profile []climbProfilePoint
+	var distance float64
+	for index := range route.Points {
+		point := &route.Points[index]
+		if index > 0 {
+			if legDistance, err := alg.Distance(&point.Point, &route.Points[index-1].Point); err == nil {
+				distance += legDistance
+			}
+		}
+		if point.Elevation.NotNull() {
+			profile = append(profile, climbProfilePoint{0, index, distance, point.Elevation.Value(), point.Timestamp})
+		}
+	}
+	return detectClimbs(profile, options)
+}
+
+// detectClimbs walks the profile from the lowest point to the highest point until the elevation drops more than MaxDip below the highest point
+// or the grade from the top of the climb stays below MinGrade for more than MaxFlatDistance, e.g. a long flat section after a climb.
+// The section from the lowest point to the top is a climb if the elevation gain and the average grade reach the options.
+func detectClimbs(profile []climbProfilePoint, options ClimbOptions) []Climb {
+	var climbs []Climb
+	if len(profile) == 0 {
+		return climbs
+	}
+
+	add := func(start int, end int) {
+		gain := profile[end].elevation - profile[start].elevation
+		length := profile[end].distance - profile[start].distance
+		if gain <= 0 || length <= 0 || gain < options.MinGain || gain/length < options.MinGrade {
+			return
+		}
+		climbs = append(climbs, newClimb(profile[start:end+1], options))
+	}
+
+	// start is the lowest point of the climb, peak the highest point and top the last point reached from the previous top with at least MinGrade (the end of the climb)
+	start, peak, top := 0, 0, 0
+	for i := 1; i < len(profile); i++ {
+		distance := profile[i].distance - profile[top].distance
+		grade := 0.0
+		if distance > 0 {
+			grade = (profile[i].elevation - profile[top].elevation) / distance
+		}
+		switch {
+		case start != peak && options.MaxFlatDistance > 0 && distance > options.MaxFlatDistance && grade < options.MinGrade:
+			// The flat section ends the climb; the next climb starts at the lowest point after the top
+			add(start, top)
+			low := top + 1
+			for j := low + 1; j <= i; j++ {
+				if profile[j].elevation <= profile[low].elevation {
+					low = j
+				}
+			}
+			start, peak, top, i = low, low, low, low
+		case profile[i].elevation > profile[peak].elevation:
+			peak = i
+			if options.MaxFlatDistance <= 0 || grade >= options.MinGrade {
+				top = i
+			}
+		case profile[i].elevation < profile[peak].elevation-options.MaxDip || profile[i].elevation < profile[start].elevation:
+			// The dip ends the climb; the next climb starts at the lowest point after the peak
+			add(start, top)
+			start, peak, top = i, i, i
+		case start == peak && profile[i].elevation <= profile[start].elevation:
+			// Not climbing yet: Move the start to the lowest point
+			start, peak, top = i, i, i
+		}
+	}
+	add(start, top)
+	return climbs
+}
+
+// newClimb returns the climb of the profile section from the first (lowest) point to the last (highest) point
+func newClimb(section []climbProfilePoint, options ClimbOptions) Climb {
+	first := section[0]
+	last := section[len(section)-1]
+
+	climb := Climb{
+		StartSegmentIndex: first.segmentIndex,
+		StartIndex:        first.index,
+		EndSegmentIndex:   last.segmentIndex,
+		EndIndex:          last.index,
+		StartDistance:     first.distance,
+		Length:            last.distance - first.distance,
+		StartElevation:    first.elevation,
+		EndElevation:      last.elevation,
+		ElevationGain:     last.elevation - first.elevation,
+		StartTime:         first.timestamp,
+		EndTime:           last.timestamp,
+	}
+	climb.AverageGrade = climb.ElevationGain / climb.Length
+	climb.Score = climb.Length * climb.AverageGrade * 100
+	climb.Category = ClimbCategoryByScore(climb.Score)
+
+	// Max grade of the shortest sections with at least MaxGradeWindow (m)
+	start := 0
+	for end := 1; end < len(section); end++ {
+		for start+1 < end && section[end].distance-section[start+1].distance >= options.MaxGradeWindow {
+			start++
+		}
+		distance := section[end].distance - section[start].distance
+		if distance <= 0 || (distance < options.MaxGradeWindow && climb.Length >= options.MaxGradeWindow) {
+			continue
+		}
+		if grade := (section[end].elevation - section[start].elevation) / distance; grade > climb.MaxGrade {
+			climb.MaxGrade = grade
+		}
+	}
+
+	if first.timestamp.Valid && last.timestamp.Valid {
+		climb.Duration = last.timestamp.Time.Sub(*first.timestamp.Time).Seconds()
+		if climb.Duration > 0 {
+			climb.VAM = climb.ElevationGain / (climb.Duration / time.Hour.Seconds())
+		}
+	}
+	return climb
+}
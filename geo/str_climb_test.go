@@ -0,0 +1,111 @@
+package geo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mbecker/gpxs/generic"
+)
+
+// testProfileTrack returns a track with points every 100 m; the elevation changes by the grades (0.05 == 5%) for the distances (m) of the sections
+func testProfileTrack(sections ...[2]float64) *GPXTrack {
+	points := []GPXPoint{{}}
+	elevation := 100.0
+	points[0].Elevation = *generic.NewNullableFloat64(elevation)
+	for _, section := range sections {
+		distance, grade := section[0], section[1]
+		for legs := int(math.Round(distance / 100)); legs > 0; legs-- {
+			elevation += 100 * grade
+			point := GPXPoint{}
+			point.Distance = 100
+			point.Elevation = *generic.NewNullableFloat64(elevation)
+			points = append(points, point)
+		}
+	}
+	return &GPXTrack{Segments: []GPXTrackSegment{{Points: points}}}
+}
+
+func TestClimbCategoryByScore(t *testing.T) {
+	for score, want := range map[float64]ClimbCategory{7999: ClimbCategoryNone, 8000: ClimbCategory4, 20000: ClimbCategory3, 40000: ClimbCategory2, 70000: ClimbCategory1, 90000: ClimbCategoryHC} {
+		if category := ClimbCategoryByScore(score); category != want {
+			t.Errorf("ClimbCategoryByScore(%v): %v, want %v", score, category, want)
+		}
+	}
+	if name := ClimbCategory4.String(); name != "Cat 4" {
+		t.Errorf("String: %s, want Cat 4", name)
+	}
+}
+
+func TestClimbs(t *testing.T) {
+	// 1 km flat, 2 km at 5%, 1 km down at 5%, 1 km at 2% (too flat), 500 m at 8%
+	climbs := testProfileTrack([2]float64{1000, 0}, [2]float64{2000, 0.05}, [2]float64{1000, -0.05}, [2]float64{1000, 0.02}, [2]float64{500, 0.08}).Climbs(DefaultClimbOptions)
+	if len(climbs) != 2 {
+		t.Fatalf("Climbs: %d, want 2", len(climbs))
+	}
+	first := climbs[0]
+	if first.StartIndex != 10 || first.EndIndex != 30 || math.Abs(first.ElevationGain-100) > 1e-9 || math.Abs(first.AverageGrade-0.05) > 1e-9 {
+		t.Errorf("First climb: %+v, want the points 10 - 30 with 100 m at 5%%", first)
+	}
+	if math.Abs(first.Score-10000) > 1e-6 || first.Category != ClimbCategory4 || math.Abs(first.MaxGrade-0.05) > 1e-9 {
+		t.Errorf("First climb: Score %f / category %v / max grade %f, want 10000 / Cat 4 / 0.05", first.Score, first.Category, first.MaxGrade)
+	}
+	// The last climb starts in the last 500 m (MaxFlatDistance) of the 2% section and ends at the top
+	last := climbs[1]
+	if last.StartIndex < 45 || last.StartIndex > 50 || last.EndIndex != 55 || last.AverageGrade < 0.05 {
+		t.Errorf("Last climb: %+v, want the points 45 - 50 to 55 with at least 5%%", last)
+	}
+}
+
+func TestClimbsMaxDip(t *testing.T) {
+	// A dip of 5 m continues the climb, a dip of 20 m ends it
+	climbs := testProfileTrack([2]float64{1000, 0.05}, [2]float64{100, -0.05}, [2]float64{1000, 0.05}).Climbs(DefaultClimbOptions)
+	if len(climbs) != 1 || math.Abs(climbs[0].ElevationGain-95) > 1e-9 {
+		t.Errorf("Small dip: %+v, want one climb with 95 m", climbs)
+	}
+	climbs = testProfileTrack([2]float64{1000, 0.05}, [2]float64{400, -0.05}, [2]float64{1000, 0.05}).Climbs(DefaultClimbOptions)
+	if len(climbs) != 2 {
+		t.Errorf("Large dip: %d climbs, want 2", len(climbs))
+	}
+}
+
+func TestClimbsFlatAfterPeak(t *testing.T) {
+	// A 300 m climb (5 km at 6%), 20 km flat and a small rise of 5 m above the old peak: The climb ends at the old peak
+	track := testProfileTrack([2]float64{5000, 0.06}, [2]float64{20000, 0}, [2]float64{500, 0.01})
+	climbs := track.Climbs(DefaultClimbOptions)
+	if len(climbs) != 1 {
+		t.Fatalf("Climbs: %d, want 1", len(climbs))
+	}
+	climb := climbs[0]
+	if climb.StartIndex != 0 || climb.EndIndex != 50 || math.Abs(climb.ElevationGain-300) > 1e-9 || math.Abs(climb.Length-5000) > 1e-9 {
+		t.Errorf("Climb: %+v, want the points 0 - 50 with 300 m in 5000 m", climb)
+	}
+
+	// A slightly rising section (10 m in 20 km) ends the climb, too
+	climbs = testProfileTrack([2]float64{5000, 0.06}, [2]float64{20000, 0.0005}).Climbs(DefaultClimbOptions)
+	if len(climbs) != 1 || climbs[0].EndIndex != 50 {
+		t.Errorf("Climbs with a slightly rising section: %+v, want one climb with the points 0 - 50", climbs)
+	}
+
+	// Without the flat distance the climb runs until the new peak and is too flat for a climb
+	options := DefaultClimbOptions
+	options.MaxFlatDistance = 0
+	if climbs := track.Climbs(options); len(climbs) != 0 {
+		t.Errorf("Climbs without max flat distance: %+v, want none", climbs)
+	}
+}
+
+func TestRouteClimbs(t *testing.T) {
+	// A route north with 5% (≈ 5.6 m per 0.001°)
+	route := GPXRoute{}
+	for index := 0; index <= 20; index++ {
+		point := GPXPoint{}
+		point.Latitude = 50 + float64(index)*0.001
+		point.Longitude = 8
+		point.Elevation = *generic.NewNullableFloat64(100 + float64(index)*5.56)
+		route.Points = append(route.Points, point)
+	}
+	climbs := route.Climbs(DefaultClimbOptions, NewKarney("Karney", EllipsoidWGS84))
+	if len(climbs) != 1 || climbs[0].EndIndex != 20 || math.Abs(climbs[0].AverageGrade-0.05) > 0.001 {
+		t.Errorf("Route climbs: %+v, want one climb of 5%%", climbs)
+	}
+}
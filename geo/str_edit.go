@@ -0,0 +1,261 @@
+package geo
+
+import (
+	"errors"
+	"time"
+)
+
+// The editing operations change the tracks of the gpx and recalculate the statistics with the algorithm (see Recalculate)
+
+// trackAt returns the track with the index
+func (gpx *GPX) trackAt(trackIndex int) (*GPXTrack, error) {
+	if trackIndex < 0 || trackIndex >= len(gpx.Tracks) {
+		return nil, errors.New("Track index out of range")
+	}
+	return &gpx.Tracks[trackIndex], nil
+}
+
+// pointPosition returns the segment and point index of the point with the index of all points of the track (see GPXTrack.Points)
+func (track *GPXTrack) pointPosition(index int) (int, int, bool) {
+	if index < 0 {
+		return 0, 0, false
+	}
+	for segmentNo := range track.Segments {
+		if index < len(track.Segments[segmentNo].Points) {
+			return segmentNo, index, true
+		}
+		index -= len(track.Segments[segmentNo].Points)
+	}
+	return 0, 0, false
+}
+
+// copySegments returns a copy of the segments with copies of the points; segments without points are removed
+func copySegments(segments []GPXTrackSegment) []GPXTrackSegment {
+	var result []GPXTrackSegment
+	for segmentNo := range segments {
+		if len(segments[segmentNo].Points) == 0 {
+			continue
+		}
+		result = append(result, GPXTrackSegment{Points: append([]GPXPoint(nil), segments[segmentNo].Points...)})
+	}
+	return result
+}
+
+// renumberTracks sets the number of each track to its index
+func (gpx *GPX) renumberTracks() {
+	for trackNo := range gpx.Tracks {
+		gpx.Tracks[trackNo].Number = trackNo
+	}
+}
+
+// CropTime removes all points of the tracks before start or after end (and points without a time); tracks and segments without points are removed
+func (gpx *GPX) CropTime(start time.Time, end time.Time, alg Algorithm) error {
+	if end.Before(start) {
+		return errors.New("End is before start")
+	}
+	var tracks []GPXTrack
+	for trackNo := range gpx.Tracks {
+		track := gpx.Tracks[trackNo]
+		var segments []GPXTrackSegment
+		for segmentNo := range track.Segments {
+			var points []GPXPoint
+			for _, point := range track.Segments[segmentNo].Points {
+				if point.Timestamp.Valid && !point.Timestamp.Time.Before(start) && !point.Timestamp.Time.After(end) {
+					points = append(points, point)
+				}
+			}
+			if len(points) > 0 {
+				segments = append(segments, GPXTrackSegment{Points: points})
+			}
+		}
+		if len(segments) > 0 {
+			track.Segments = segments
+			tracks = append(tracks, track)
+		}
+	}
+	if len(tracks) == 0 {
+		return errors.New("No points in the time range")
+	}
+	gpx.Tracks = tracks
+	gpx.renumberTracks()
+	Recalculate(gpx, alg)
+	return nil
+}
+
+// CropIndex removes all points of the track before the point startIndex and after the point endIndex; the indices are the indices of all points of the track (see GPXTrack.Points)
+func (gpx *GPX) CropIndex(trackIndex int, startIndex int, endIndex int, alg Algorithm) error {
+	track, err := gpx.trackAt(trackIndex)
+	if err != nil {
+		return err
+	}
+	startSegment, startPoint, okStart := track.pointPosition(startIndex)
+	endSegment, endPoint, okEnd := track.pointPosition(endIndex)
+	if !okStart || !okEnd || endIndex < startIndex {
+		return errors.New("Point index out of range")
+	}
+	segments := copySegments(track.Segments[startSegment : endSegment+1])
+	if startSegment == endSegment {
+		segments[0].Points = segments[0].Points[startPoint : endPoint+1]
+	} else {
+		segments[0].Points = segments[0].Points[startPoint:]
+		segments[len(segments)-1].Points = segments[len(segments)-1].Points[:endPoint+1]
+	}
+	track.Segments = segments
+	Recalculate(gpx, alg)
+	return nil
+}
+
+// splitTrack splits the track at the point of the segment: The point is the last point of the first track and the first point of the second track;
+// if the point is the first point of a segment the track is split between the segments
+func (gpx *GPX) splitTrack(trackIndex int, segmentNo int, pointNo int, alg Algorithm) error {
+	track := &gpx.Tracks[trackIndex]
+	// The index of the point of all points of the track; segments without points are ignored
+	index := pointNo
+	for _, seg := range track.Segments[:segmentNo] {
+		index += len(seg.Points)
+	}
+	if index == 0 || index >= len(track.Points())-1 {
+		return errors.New("Cannot split a track at the first or last point")
+	}
+
+	first, second := *track, *track
+	if pointNo == 0 {
+		first.Segments = copySegments(track.Segments[:segmentNo])
+		second.Segments = copySegments(track.Segments[segmentNo:])
+	} else {
+		first.Segments = copySegments(track.Segments[:segmentNo+1])
+		last := &first.Segments[len(first.Segments)-1]
+		last.Points = last.Points[:pointNo+1]
+		second.Segments = copySegments(track.Segments[segmentNo:])
+		second.Segments[0].Points = second.Segments[0].Points[pointNo:]
+	}
+
+	tracks := append([]GPXTrack(nil), gpx.Tracks[:trackIndex]...)
+	tracks = append(tracks, first, second)
+	gpx.Tracks = append(tracks, gpx.Tracks[trackIndex+1:]...)
+	gpx.renumberTracks()
+	Recalculate(gpx, alg)
+	return nil
+}
+
+// SplitTrackAtIndex splits the track into two tracks at the point with the index of all points of the track (see GPXTrack.Points); the point is in both tracks
+func (gpx *GPX) SplitTrackAtIndex(trackIndex int, index int, alg Algorithm) error {
+	track, err := gpx.trackAt(trackIndex)
+	if err != nil {
+		return err
+	}
+	segmentNo, pointNo, ok := track.pointPosition(index)
+	if !ok {
+		return errors.New("Point index out of range")
+	}
+	return gpx.splitTrack(trackIndex, segmentNo, pointNo, alg)
+}
+
+// SplitTrackAtTime splits the track into two tracks at the first point at or after the time; the point is in both tracks
+func (gpx *GPX) SplitTrackAtTime(trackIndex int, t time.Time, alg Algorithm) error {
+	track, err := gpx.trackAt(trackIndex)
+	if err != nil {
+		return err
+	}
+	for segmentNo := range track.Segments {
+		for pointNo, point := range track.Segments[segmentNo].Points {
+			if point.Timestamp.Valid && !point.Timestamp.Time.Before(t) {
+				return gpx.splitTrack(trackIndex, segmentNo, pointNo, alg)
+			}
+		}
+	}
+	return errors.New("No point at or after the time")
+}
+
+// SplitTrackAtDistance splits the track into two tracks at the first point at or after the distance (m) from the start of the track; the point is in both tracks
+func (gpx *GPX) SplitTrackAtDistance(trackIndex int, distance float64, alg Algorithm) error {
+	track, err := gpx.trackAt(trackIndex)
+	if err != nil {
+		return err
+	}
+	var trackDistance float64
+	for segmentNo := range track.Segments {
+		for pointNo, point := range track.Segments[segmentNo].Points {
+			trackDistance += point.Distance
+			if trackDistance >= distance {
+				return gpx.splitTrack(trackIndex, segmentNo, pointNo, alg)
+			}
+		}
+	}
+	return errors.New("Distance is longer than the track")
+}
+
+// JoinSegments joins all segments of the track into one segment
+func (gpx *GPX) JoinSegments(trackIndex int, alg Algorithm) error {
+	track, err := gpx.trackAt(trackIndex)
+	if err != nil {
+		return err
+	}
+	var points []GPXPoint
+	for segmentNo := range track.Segments {
+		points = append(points, track.Segments[segmentNo].Points...)
+	}
+	track.Segments = copySegments([]GPXTrackSegment{{Points: points}})
+	Recalculate(gpx, alg)
+	return nil
+}
+
+// SeparateSegments separates each segment of the track into a track of its own; the tracks have the name and type of the track
+func (gpx *GPX) SeparateSegments(trackIndex int, alg Algorithm) error {
+	track, err := gpx.trackAt(trackIndex)
+	if err != nil {
+		return err
+	}
+	tracks := append([]GPXTrack(nil), gpx.Tracks[:trackIndex]...)
+	for _, segment := range copySegments(track.Segments) {
+		separated := *track
+		separated.Segments = []GPXTrackSegment{segment}
+		tracks = append(tracks, separated)
+	}
+	gpx.Tracks = append(tracks, gpx.Tracks[trackIndex+1:]...)
+	gpx.renumberTracks()
+	Recalculate(gpx, alg)
+	return nil
+}
+
+// JoinTracks joins all tracks into one track with the name and type of the first track; the segments of the tracks are kept
+func (gpx *GPX) JoinTracks(alg Algorithm) error {
+	if len(gpx.Tracks) == 0 {
+		return errors.New("No track found")
+	}
+	joined := gpx.Tracks[0]
+	joined.Segments = nil
+	for trackNo := range gpx.Tracks {
+		joined.Segments = append(joined.Segments, copySegments(gpx.Tracks[trackNo].Segments)...)
+	}
+	gpx.Tracks = []GPXTrack{joined}
+	gpx.renumberTracks()
+	Recalculate(gpx, alg)
+	return nil
+}
+
+// Merge returns a new gpx with the metadata of the first gpx and copies of the tracks, routes and waypoints of all gpx, e.g. the files of a watch which rebooted.
+// The tracks are kept; use GPX.JoinTracks to join them into one track.
+func Merge(alg Algorithm, gpxs ...*GPX) (*GPX, error) {
+	if len(gpxs) == 0 {
+		return nil, errors.New("No gpx found")
+	}
+	merged := *gpxs[0]
+	merged.Tracks, merged.Routes, merged.Waypoints = nil, nil, nil
+	for _, gpx := range gpxs {
+		for trackNo := range gpx.Tracks {
+			track := gpx.Tracks[trackNo]
+			track.Segments = copySegments(track.Segments)
+			merged.Tracks = append(merged.Tracks, track)
+		}
+		for routeNo := range gpx.Routes {
+			route := gpx.Routes[routeNo]
+			route.Points = append([]GPXPoint(nil), route.Points...)
+			merged.Routes = append(merged.Routes, route)
+		}
+		merged.Waypoints = append(merged.Waypoints, gpx.Waypoints...)
+	}
+	merged.renumberTracks()
+	Recalculate(&merged, alg)
+	return &merged, nil
+}
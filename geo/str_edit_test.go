@@ -0,0 +1,180 @@
+package geo
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// testEditGPX returns a gpx with the track Run of two segments (4 points at 0 - 30 sec and 3 points at 60 - 80 sec) and the track Ride of 2 points 2 hours later;
+// the points are 10 sec and 0.001° (≈ 111 m) apart
+func testEditGPX(t *testing.T) *GPX {
+	at := func(seconds int) time.Time { return testStart.Add(time.Duration(seconds) * time.Second) }
+	builder := NewGPX().Track("Run").Type(ActivityTypeRunning)
+	for index := 0; index < 4; index++ {
+		builder.Point(50+float64(index)*0.001, 8, 100, at(index*10))
+	}
+	builder.Segment()
+	for index := 0; index < 3; index++ {
+		builder.Point(50.004+float64(index)*0.001, 8, 100, at(60+index*10))
+	}
+	builder.Track("Ride").Point(51, 8, 100, at(7200)).Point(51.001, 8, 100, at(7210)).Waypoint("Start", 50, 8, 100, at(0))
+	gpx, err := builder.Build(NewKarney("Karney", EllipsoidWGS84))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return gpx
+}
+
+// testSegmentLengths returns the number of points of each segment of each track
+func testSegmentLengths(gpx *GPX) [][]int {
+	result := make([][]int, len(gpx.Tracks))
+	for trackNo, track := range gpx.Tracks {
+		for _, seg := range track.Segments {
+			result[trackNo] = append(result[trackNo], len(seg.Points))
+		}
+	}
+	return result
+}
+
+func TestEditOperations(t *testing.T) {
+	alg := NewKarney("Karney", EllipsoidWGS84)
+	testCases := []struct {
+		name    string
+		edit    func(gpx *GPX) error
+		lengths string
+	}{
+		{"CropTime", func(gpx *GPX) error {
+			return gpx.CropTime(testStart.Add(10*time.Second), testStart.Add(70*time.Second), alg)
+		}, "[[3 2]]"},
+		{"CropIndex", func(gpx *GPX) error { return gpx.CropIndex(0, 2, 5, alg) }, "[[2 2] [2]]"},
+		{"CropIndex in a segment", func(gpx *GPX) error { return gpx.CropIndex(0, 1, 2, alg) }, "[[2] [2]]"},
+		{"SplitTrackAtIndex", func(gpx *GPX) error { return gpx.SplitTrackAtIndex(0, 2, alg) }, "[[3] [2 3] [2]]"},
+		{"SplitTrackAtIndex between segments", func(gpx *GPX) error { return gpx.SplitTrackAtIndex(0, 4, alg) }, "[[4] [3] [2]]"},
+		{"SplitTrackAtTime", func(gpx *GPX) error { return gpx.SplitTrackAtTime(0, testStart.Add(15*time.Second), alg) }, "[[3] [2 3] [2]]"},
+		{"SplitTrackAtDistance", func(gpx *GPX) error { return gpx.SplitTrackAtDistance(0, 150, alg) }, "[[3] [2 3] [2]]"},
+		{"JoinSegments", func(gpx *GPX) error { return gpx.JoinSegments(0, alg) }, "[[7] [2]]"},
+		{"SeparateSegments", func(gpx *GPX) error { return gpx.SeparateSegments(0, alg) }, "[[4] [3] [2]]"},
+		{"JoinTracks", func(gpx *GPX) error { return gpx.JoinTracks(alg) }, "[[4 3 2]]"},
+	}
+	for _, tc := range testCases {
+		gpx := testEditGPX(t)
+		if err := tc.edit(gpx); err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if lengths := fmt.Sprint(testSegmentLengths(gpx)); lengths != tc.lengths {
+			t.Errorf("%s: Points %s, want %s", tc.name, lengths, tc.lengths)
+		}
+		// The tracks are renumbered and the statistics are recalculated
+		count := 0
+		for trackNo, track := range gpx.Tracks {
+			if track.Number != trackNo || track.Name == "" {
+				t.Errorf("%s: Track %d has the number %d and the name %q", tc.name, trackNo, track.Number, track.Name)
+			}
+			for _, seg := range track.Segments {
+				count += len(seg.Points)
+				duration := seg.Points[len(seg.Points)-1].Timestamp.Time.Sub(*seg.Points[0].Timestamp.Time).Seconds()
+				if seg.Points[0].Distance != 0 || seg.MovementStats.OverallData.Duration != duration {
+					t.Errorf("%s: Segment of track %d: First distance %f / duration %f, want 0 / %f", tc.name, trackNo, seg.Points[0].Distance, seg.MovementStats.OverallData.Duration, duration)
+				}
+			}
+		}
+		if gpx.PointsCount != count {
+			t.Errorf("%s: Points count %d, want %d", tc.name, gpx.PointsCount, count)
+		}
+	}
+
+	// The joined segment has the distance between the segments
+	gpx := testEditGPX(t)
+	if err := gpx.JoinSegments(0, alg); err != nil {
+		t.Fatal(err)
+	}
+	if point := gpx.Tracks[0].Segments[0].Points[4]; point.Distance < 100 || point.Duration != 30 {
+		t.Errorf("JoinSegments: Distance %f / duration %f of the first point of the second segment, want 111 m / 30 sec", point.Distance, point.Duration)
+	}
+}
+
+func TestSplitTrackEmptySegments(t *testing.T) {
+	alg := NewKarney("Karney", EllipsoidWGS84)
+	// testEditGPX with an empty segment before and after the segments of the track Run
+	emptySegments := func(t *testing.T) *GPX {
+		gpx := testEditGPX(t)
+		track := &gpx.Tracks[0]
+		track.Segments = append(append([]GPXTrackSegment{{}}, track.Segments...), GPXTrackSegment{})
+		return gpx
+	}
+	for name, edit := range map[string]func(gpx *GPX) error{
+		"Split at the first point":       func(gpx *GPX) error { return gpx.SplitTrackAtIndex(0, 0, alg) },
+		"Split at the last point":        func(gpx *GPX) error { return gpx.SplitTrackAtIndex(0, 6, alg) },
+		"Split at the time of the start": func(gpx *GPX) error { return gpx.SplitTrackAtTime(0, testStart, alg) },
+	} {
+		gpx := emptySegments(t)
+		if err := edit(gpx); err == nil {
+			t.Errorf("%s: want an error", name)
+		}
+		if lengths := fmt.Sprint(testSegmentLengths(gpx)); lengths != "[[0 4 3 0] [2]]" {
+			t.Errorf("%s: Points %s, want [[0 4 3 0] [2]]", name, lengths)
+		}
+	}
+	gpx := emptySegments(t)
+	if err := gpx.SplitTrackAtIndex(0, 3, alg); err != nil {
+		t.Fatal(err)
+	}
+	if lengths := fmt.Sprint(testSegmentLengths(gpx)); lengths != "[[4] [1 3] [2]]" {
+		t.Errorf("SplitTrackAtIndex: Points %s, want [[4] [1 3] [2]]", lengths)
+	}
+}
+
+func TestEditErrors(t *testing.T) {
+	alg := NewKarney("Karney", EllipsoidWGS84)
+	testCases := map[string]func(gpx *GPX) error{
+		"CropTime end before start": func(gpx *GPX) error { return gpx.CropTime(testStart, testStart.Add(-time.Second), alg) },
+		"CropTime without points": func(gpx *GPX) error {
+			return gpx.CropTime(testStart.Add(time.Hour), testStart.Add(90*time.Minute), alg)
+		},
+		"CropIndex track":          func(gpx *GPX) error { return gpx.CropIndex(2, 0, 1, alg) },
+		"CropIndex point":          func(gpx *GPX) error { return gpx.CropIndex(0, 2, 7, alg) },
+		"CropIndex order":          func(gpx *GPX) error { return gpx.CropIndex(0, 3, 2, alg) },
+		"Split at the first point": func(gpx *GPX) error { return gpx.SplitTrackAtIndex(0, 0, alg) },
+		"Split at the last point":  func(gpx *GPX) error { return gpx.SplitTrackAtIndex(0, 6, alg) },
+		"Split after the end":      func(gpx *GPX) error { return gpx.SplitTrackAtTime(0, testStart.Add(time.Hour), alg) },
+		"Split after the distance": func(gpx *GPX) error { return gpx.SplitTrackAtDistance(0, 10000, alg) },
+		"JoinSegments track":       func(gpx *GPX) error { return gpx.JoinSegments(-1, alg) },
+		"SeparateSegments track":   func(gpx *GPX) error { return gpx.SeparateSegments(2, alg) },
+	}
+	for name, edit := range testCases {
+		gpx := testEditGPX(t)
+		if err := edit(gpx); err == nil {
+			t.Errorf("%s: want an error", name)
+		}
+		// The gpx is not changed
+		if lengths := fmt.Sprint(testSegmentLengths(gpx)); lengths != "[[4 3] [2]]" {
+			t.Errorf("%s: Points %s, want [[4 3] [2]]", name, lengths)
+		}
+	}
+	if err := (&GPX{}).JoinTracks(alg); err == nil {
+		t.Error("JoinTracks without tracks: want an error")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	alg := NewKarney("Karney", EllipsoidWGS84)
+	a, b := testEditGPX(t), testEditGPX(t)
+	a.Name, b.Name = "Watch", "Watch after reboot"
+	merged, err := Merge(alg, a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.Name != "Watch" || len(merged.Tracks) != 4 || merged.Tracks[3].Number != 3 || len(merged.Waypoints) != 2 || merged.PointsCount != 18 {
+		t.Errorf("Merge: Name %q / %d tracks / %d waypoints / %d points, want Watch / 4 / 2 / 18", merged.Name, len(merged.Tracks), len(merged.Waypoints), merged.PointsCount)
+	}
+	// The points are copies
+	merged.Tracks[0].Segments[0].Points[0].Latitude = 0
+	if a.Tracks[0].Segments[0].Points[0].Latitude != 50 {
+		t.Error("Merge: The points of the merged gpx are not copies")
+	}
+	if _, err := Merge(alg); err == nil {
+		t.Error("Merge without gpx: want an error")
+	}
+}
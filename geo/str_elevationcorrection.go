@@ -0,0 +1,64 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ElevationCorrection replaces or blends the elevation of the points with the elevation of a provider, e.g. a digital elevation model (see package dem) instead of the poor GPS elevation of a phone;
+// the elevation of the provider is above mean sea level and is converted into the datum of the gpx (GPX.ElevationDatum)
+type ElevationCorrection struct {
+	Provider ElevationProvider
+	Weight   float64    // The weight (0 < weight <= 1) of the provider elevation: 1 replaces the elevation, 0.5 is the mean of the point and the provider elevation; a point without an elevation gets the provider elevation
+	Geoid    GeoidModel // The geoid model of the points without a geoid height (GPXPoint.GeoidHeight) if the elevations of the gpx are ellipsoidal heights; may be nil for mean sea level
+}
+
+// NewElevationCorrection returns an elevation correction which replaces the elevation of the points with the elevation of the provider
+func NewElevationCorrection(provider ElevationProvider) *ElevationCorrection {
+	return &ElevationCorrection{
+		Provider: provider,
+		Weight:   1,
+	}
+}
+
+// Apply sets the elevation of the points of the tracks, routes and waypoints and recalculates the statistics with the algorithm (see Recalculate);
+// points without an elevation of the provider (e.g. outside of the available tiles) keep their elevation. Apply returns the number of corrected points.
+// An error is returned and the gpx is unchanged if the elevation of the provider cannot be converted into the datum of the gpx, e.g. ellipsoidal heights without a geoid height or model.
+func (ec *ElevationCorrection) Apply(gpx *GPX, alg Algorithm) (int, error) {
+	if ec.Provider == nil {
+		return 0, errors.New("No elevation provider")
+	}
+	if ec.Weight <= 0 || ec.Weight > 1 {
+		return 0, fmt.Errorf("Invalid weight %f", ec.Weight)
+	}
+
+	// The elevations are converted before any elevation is changed that an error leaves the gpx unchanged
+	points := gpx.allPoints()
+	elevations := make([]float64, len(points))
+	hasElevation := make([]bool, len(points))
+	for index, point := range points {
+		elevation, err := ec.Provider.Elevation(point.Latitude, point.Longitude)
+		if err != nil {
+			continue
+		}
+		if elevations[index], err = gpx.ElevationDatum.fromMeanSeaLevel(elevation, point, ec.Geoid); err != nil {
+			return 0, err
+		}
+		hasElevation[index] = true
+	}
+
+	var corrected int
+	for index, point := range points {
+		if !hasElevation[index] {
+			continue
+		}
+		elevation := elevations[index]
+		if point.Elevation.NotNull() {
+			elevation = ec.Weight*elevation + (1-ec.Weight)*point.Elevation.Value()
+		}
+		point.Elevation.SetValue(elevation)
+		corrected++
+	}
+	Recalculate(gpx, alg)
+	return corrected, nil
+}
@@ -0,0 +1,131 @@
+package geo
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// testElevationProvider is an elevation provider by a function
+type testElevationProvider func(latitude float64, longitude float64) (float64, error)
+
+func (p testElevationProvider) Elevation(latitude float64, longitude float64) (float64, error) {
+	return p(latitude, longitude)
+}
+
+// testDEM is the elevation 100 m + 1 m per 0.001° north of 50° up to 50.002°; it has no data further north
+var testDEM = testElevationProvider(func(latitude float64, longitude float64) (float64, error) {
+	if latitude > 50.0025 {
+		return 0, errors.New("No data")
+	}
+	return 100 + 1000*(latitude-50), nil
+})
+
+// testElevationGPX returns a track with the elevations 10, 20, 30 and 40 m from 50° north every 0.001°, a route point at 50.001° and a waypoint at 50.002°
+func testElevationGPX(t *testing.T) *GPX {
+	builder := NewGPX().Track("Elevation")
+	for index := 0; index < 4; index++ {
+		builder.Point(50+float64(index)*0.001, 8, 10*float64(index+1), testStart.Add(time.Duration(index)*time.Minute))
+	}
+	gpx, err := builder.Route("Route").RoutePoint(50.001, 8, 0).
+		Waypoint("Waypoint", 50.002, 8, 0, testStart).
+		Build(NewKarney("Karney", EllipsoidWGS84))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gpx.Routes[0].Points[0].Elevation.SetNull()
+	return gpx
+}
+
+// testElevations returns the elevations of the track points, the route point and the waypoint; NaN for a point without an elevation
+func testElevations(gpx *GPX) []float64 {
+	var elevations []float64
+	for _, point := range gpx.allPoints() {
+		elevation := math.NaN()
+		if point.Elevation.NotNull() {
+			elevation = point.Elevation.Value()
+		}
+		elevations = append(elevations, elevation)
+	}
+	return elevations
+}
+
+func TestElevationCorrection(t *testing.T) {
+	alg := NewKarney("Karney", EllipsoidWGS84)
+	testCases := []struct {
+		weight     float64
+		elevations []float64
+	}{
+		// The last track point is outside of the DEM; the route point without an elevation gets the DEM elevation
+		{1, []float64{100, 101, 102, 40, 101, 102}},
+		{0.5, []float64{55, 60.5, 66, 40, 101, 51}},
+	}
+	for _, tc := range testCases {
+		gpx := testElevationGPX(t)
+		ec := NewElevationCorrection(testDEM)
+		ec.Weight = tc.weight
+		corrected, err := ec.Apply(gpx, alg)
+		if err != nil || corrected != 5 {
+			t.Errorf("Weight %v: %d corrected points (%v), want 5", tc.weight, corrected, err)
+		}
+		for index, elevation := range testElevations(gpx) {
+			if math.Abs(elevation-tc.elevations[index]) > 1e-6 {
+				t.Errorf("Weight %v: Elevation of point %d: %f, want %f", tc.weight, index, elevation, tc.elevations[index])
+			}
+		}
+	}
+	// The statistics are recalculated
+	gpx := testElevationGPX(t)
+	if _, err := NewElevationCorrection(testDEM).Apply(gpx, alg); err != nil {
+		t.Fatal(err)
+	}
+	if gain := gpx.Tracks[0].MovementStats.OverallData.ElevationGain; math.Abs(gain-2) > 1e-6 {
+		t.Errorf("Elevation gain: %f, want 2", gain)
+	}
+
+	for name, ec := range map[string]*ElevationCorrection{
+		"No provider": {Weight: 1},
+		"Weight 0":    {Provider: testDEM},
+		"Weight 2":    {Provider: testDEM, Weight: 2},
+	} {
+		if _, err := ec.Apply(testElevationGPX(t), alg); err == nil {
+			t.Errorf("%s: want an error", name)
+		}
+	}
+}
+
+func TestElevationCorrectionDatum(t *testing.T) {
+	alg := NewKarney("Karney", EllipsoidWGS84)
+	// The DEM elevation above mean sea level is converted into ellipsoidal heights with the geoid height of the point or the model
+	gpx := testElevationGPX(t)
+	gpx.ElevationDatum = HeightDatumEllipsoid
+	gpx.Tracks[0].Segments[0].Points[0].GeoidHeight.SetValue(47)
+	ec := NewElevationCorrection(testDEM)
+	ec.Geoid = testGeoidModel(48)
+	if _, err := ec.Apply(gpx, alg); err != nil {
+		t.Fatal(err)
+	}
+	for index, want := range []float64{147, 149, 150, 40, 149, 150} {
+		if elevation := testElevations(gpx)[index]; math.Abs(elevation-want) > 1e-6 {
+			t.Errorf("Elevation of point %d: %f, want %f", index, elevation, want)
+		}
+	}
+
+	// Without a geoid model the ellipsoidal heights cannot be corrected; the gpx is unchanged
+	gpx = testElevationGPX(t)
+	gpx.ElevationDatum = HeightDatumEllipsoid
+	gpx.Tracks[0].Segments[0].Points[0].GeoidHeight.SetValue(47)
+	if _, err := NewElevationCorrection(testDEM).Apply(gpx, alg); err == nil {
+		t.Error("Without geoid model: want an error")
+	}
+	for index, want := range []float64{10, 20, 30, 40} {
+		if elevation := testElevations(gpx)[index]; elevation != want {
+			t.Errorf("Without geoid model: Elevation of point %d: %f, want %f", index, elevation, want)
+		}
+	}
+	gpx.ElevationDatum = HeightDatum(5)
+	if _, err := NewElevationCorrection(testDEM).Apply(gpx, alg); err == nil {
+		t.Error("Unknown datum: want an error")
+	}
+}
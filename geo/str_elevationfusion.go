@@ -0,0 +1,145 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DefaultElevationFusionTimeConstant is the default time constant (sec) of the complementary filter of the elevation fusion
+const DefaultElevationFusionTimeConstant = 60.0
+
+// ElevationFusion fuses the barometric elevation (GPXPoint.BarometricElevation) and a reference elevation with a complementary filter:
+// The barometric elevation is precise for relative changes but drifts with the air pressure; the GPS elevation of the point (or the elevation of a DEM) is noisy but has the correct absolute level.
+// The changes of the fused elevation which are faster than the time constant follow the barometric elevation, slower changes follow the reference elevation.
+// The reference elevation of the provider is above mean sea level and is converted into the datum of the gpx (GPX.ElevationDatum).
+type ElevationFusion struct {
+	TimeConstant float64           // The time constant τ (sec) of the filter: The weight of the barometric change from the previous point is τ / (τ + duration)
+	Reference    ElevationProvider // The reference elevation, e.g. a DEM (see package dem); the elevation of the points if nil or the reference does not have data at a point
+	Geoid        GeoidModel        // The geoid model of the points without a geoid height (GPXPoint.GeoidHeight) if the elevations of the gpx are ellipsoidal heights; may be nil for mean sea level
+}
+
+// NewElevationFusion returns an elevation fusion with the default time constant and the elevation of the points as the reference
+func NewElevationFusion() *ElevationFusion {
+	return &ElevationFusion{TimeConstant: DefaultElevationFusionTimeConstant}
+}
+
+// reference returns the reference elevation of the point in the datum and if it is available
+func (ef *ElevationFusion) reference(point *GPXPoint, datum HeightDatum) (float64, bool, error) {
+	if ef.Reference != nil {
+		if elevation, err := ef.Reference.Elevation(point.Latitude, point.Longitude); err == nil {
+			elevation, err = datum.fromMeanSeaLevel(elevation, point, ef.Geoid)
+			return elevation, err == nil, err
+		}
+	}
+	if point.Elevation.NotNull() {
+		return point.Elevation.Value(), true, nil
+	}
+	return 0, false, nil
+}
+
+// segmentReferences returns the reference elevations of the points of the segment in the datum and if they are available
+func (ef *ElevationFusion) segmentReferences(seg *GPXTrackSegment, datum HeightDatum) ([]float64, []bool, error) {
+	references := make([]float64, len(seg.Points))
+	hasReference := make([]bool, len(seg.Points))
+	for index := range seg.Points {
+		var err error
+		if references[index], hasReference[index], err = ef.reference(&seg.Points[index], datum); err != nil {
+			return nil, nil, err
+		}
+	}
+	return references, hasReference, nil
+}
+
+// fuseSegment sets the fused elevation of the points of the segment with the reference elevations of the points and returns the number of fused points
+func (ef *ElevationFusion) fuseSegment(seg *GPXTrackSegment, references []float64, hasReference []bool) int {
+	var offsetSum float64
+	var offsetCount int
+	for index := range seg.Points {
+		if hasReference[index] && seg.Points[index].BarometricElevation.NotNull() {
+			offsetSum += references[index] - seg.Points[index].BarometricElevation.Value()
+			offsetCount++
+		}
+	}
+
+	var fused int
+	var previous *GPXPoint // The previous point with a fused elevation
+	for index := range seg.Points {
+		point := &seg.Points[index]
+		if point.BarometricElevation.Null() {
+			// The filter starts again after points without a barometric elevation
+			previous = nil
+			if hasReference[index] {
+				point.Elevation.SetValue(references[index])
+			}
+			continue
+		}
+		barometricElevation := point.BarometricElevation.Value()
+
+		var elevation float64
+		switch {
+		case previous != nil && previous.Timestamp.Valid && point.Timestamp.Valid:
+			// The complementary filter: The barometric change from the previous point weighted by α and the reference weighted by 1 - α
+			elevation = previous.Elevation.Value() + barometricElevation - previous.BarometricElevation.Value()
+			if hasReference[index] {
+				duration := point.Timestamp.Time.Sub(*previous.Timestamp.Time).Seconds()
+				α := 1.0
+				if duration > 0 {
+					α = ef.TimeConstant / (ef.TimeConstant + duration)
+				}
+				elevation = α*elevation + (1-α)*references[index]
+			}
+		case offsetCount > 0:
+			// The first point: The barometric elevation plus the mean offset to the reference that the filter does not start with the noise of a single reference
+			elevation = barometricElevation + offsetSum/float64(offsetCount)
+		default:
+			elevation = barometricElevation
+		}
+		point.Elevation.SetValue(elevation)
+		previous = point
+		fused++
+	}
+	return fused
+}
+
+// Apply sets the fused elevation of the track points and recalculates the statistics with the algorithm (see Recalculate) that the elevation gain / loss and the grade are based on the fused elevation;
+// the points without a barometric elevation get the reference elevation. Apply returns the number of points with a fused elevation.
+// An error is returned and the gpx is unchanged if the reference elevation cannot be converted into the datum of the gpx, e.g. ellipsoidal heights without a geoid height or model.
+func (ef *ElevationFusion) Apply(gpx *GPX, alg Algorithm) (int, error) {
+	if ef.TimeConstant < 0 {
+		return 0, fmt.Errorf("Invalid time constant %f", ef.TimeConstant)
+	}
+	hasBarometricElevation := false
+	for trackNo := range gpx.Tracks {
+		for segmentNo := range gpx.Tracks[trackNo].Segments {
+			for _, point := range gpx.Tracks[trackNo].Segments[segmentNo].Points {
+				hasBarometricElevation = hasBarometricElevation || point.BarometricElevation.NotNull()
+			}
+		}
+	}
+	if !hasBarometricElevation {
+		return 0, errors.New("No point with a barometric elevation")
+	}
+
+	// The references are taken before any elevation is changed that an error leaves the gpx unchanged
+	var segments []*GPXTrackSegment
+	var references [][]float64
+	var hasReferences [][]bool
+	for trackNo := range gpx.Tracks {
+		for segmentNo := range gpx.Tracks[trackNo].Segments {
+			seg := &gpx.Tracks[trackNo].Segments[segmentNo]
+			segmentReferences, hasReference, err := ef.segmentReferences(seg, gpx.ElevationDatum)
+			if err != nil {
+				return 0, err
+			}
+			segments = append(segments, seg)
+			references = append(references, segmentReferences)
+			hasReferences = append(hasReferences, hasReference)
+		}
+	}
+	var fused int
+	for index, seg := range segments {
+		fused += ef.fuseSegment(seg, references[index], hasReferences[index])
+	}
+	Recalculate(gpx, alg)
+	return fused, nil
+}
@@ -0,0 +1,138 @@
+package geo
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// testFusionGPX returns a track with points every 0.001° north from 50° and 60 sec apart with the GPS and the barometric elevations; a NaN barometric elevation is omitted
+func testFusionGPX(t *testing.T, elevations []float64, barometricElevations []float64) *GPX {
+	builder := NewGPX().Track("Fusion")
+	for index, elevation := range elevations {
+		builder.Point(50+float64(index)*0.001, 8, elevation, testStart.Add(time.Duration(index)*time.Minute))
+		if !math.IsNaN(barometricElevations[index]) {
+			builder.BarometricElevation(barometricElevations[index])
+		}
+	}
+	gpx, err := builder.Build(NewKarney("Karney", EllipsoidWGS84))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return gpx
+}
+
+// testFusedElevations returns the elevations of the track points
+func testFusedElevations(gpx *GPX) []float64 {
+	var elevations []float64
+	for _, point := range gpx.Tracks[0].Segments[0].Points {
+		elevations = append(elevations, point.Elevation.Value())
+	}
+	return elevations
+}
+
+func TestElevationFusion(t *testing.T) {
+	alg := NewKarney("Karney", EllipsoidWGS84)
+	// The noisy GPS elevation is about 100 m, the barometric elevation is flat 100 m too high
+	gps := []float64{110, 90, 100, 104}
+	barometric := []float64{200, 200, 200, 200}
+	testCases := []struct {
+		timeConstant float64
+		elevations   []float64
+	}{
+		// The first point is the barometric elevation plus the mean offset of -99 m; α is 0.5 for 60 sec
+		{60, []float64{101, 95.5, 97.75, 100.875}},
+		// Without the time constant the fused elevation is the reference
+		{0, []float64{101, 90, 100, 104}},
+		// A large time constant follows the barometric elevation
+		{1e9, []float64{101, 101, 101, 101}},
+	}
+	for _, tc := range testCases {
+		gpx := testFusionGPX(t, gps, barometric)
+		ef := NewElevationFusion()
+		ef.TimeConstant = tc.timeConstant
+		fused, err := ef.Apply(gpx, alg)
+		if err != nil || fused != 4 {
+			t.Errorf("Time constant %v: %d fused points (%v), want 4", tc.timeConstant, fused, err)
+		}
+		for index, elevation := range testFusedElevations(gpx) {
+			if math.Abs(elevation-tc.elevations[index]) > 1e-6 {
+				t.Errorf("Time constant %v: Elevation of point %d: %f, want %f", tc.timeConstant, index, elevation, tc.elevations[index])
+			}
+		}
+	}
+
+	// The statistics are recalculated with the flat fused elevation
+	gpx := testFusionGPX(t, gps, barometric)
+	ef := NewElevationFusion()
+	ef.TimeConstant = 1e9
+	if _, err := ef.Apply(gpx, alg); err != nil {
+		t.Fatal(err)
+	}
+	if gain := gpx.Tracks[0].MovementStats.OverallData.ElevationGain; math.Abs(gain) > 1e-6 {
+		t.Errorf("Elevation gain: %f, want 0", gain)
+	}
+}
+
+func TestElevationFusionGap(t *testing.T) {
+	alg := NewKarney("Karney", EllipsoidWGS84)
+	// The point without a barometric elevation gets the reference; the filter starts again with the mean offset of -110 m
+	gpx := testFusionGPX(t, []float64{100, 90, 100, 100}, []float64{200, 220, math.NaN(), 200})
+	fused, err := NewElevationFusion().Apply(gpx, alg)
+	if err != nil || fused != 3 {
+		t.Errorf("Fused points: %d (%v), want 3", fused, err)
+	}
+	// (90 + 20) * 0.5 + 90 * 0.5 == 100
+	for index, want := range []float64{90, 100, 100, 90} {
+		if elevation := testFusedElevations(gpx)[index]; math.Abs(elevation-want) > 1e-6 {
+			t.Errorf("Elevation of point %d: %f, want %f", index, elevation, want)
+		}
+	}
+}
+
+func TestElevationFusionReference(t *testing.T) {
+	alg := NewKarney("Karney", EllipsoidWGS84)
+	// The DEM elevation is 100, 101 and 102 m; the last point is outside of the DEM and uses its GPS elevation
+	gpx := testFusionGPX(t, []float64{0, 0, 0, 103}, []float64{200, 201, 202, 203})
+	ef := NewElevationFusion()
+	ef.Reference = testDEM
+	if _, err := ef.Apply(gpx, alg); err != nil {
+		t.Fatal(err)
+	}
+	for index, want := range []float64{100, 101, 102, 103} {
+		if elevation := testFusedElevations(gpx)[index]; math.Abs(elevation-want) > 1e-6 {
+			t.Errorf("Elevation of point %d: %f, want %f", index, elevation, want)
+		}
+	}
+
+	// The DEM elevation is converted into ellipsoidal heights; the GPS elevation of the last point is already ellipsoidal
+	gpx = testFusionGPX(t, []float64{0, 0, 0, 151}, []float64{200, 201, 202, 203})
+	gpx.ElevationDatum = HeightDatumEllipsoid
+	if _, err := ef.Apply(gpx, alg); err == nil {
+		t.Error("Without geoid model: want an error")
+	}
+	if elevations := testFusedElevations(gpx); elevations[0] != 0 {
+		t.Errorf("Without geoid model: Elevation %f, want the unchanged 0", elevations[0])
+	}
+	ef.Geoid = testGeoidModel(48)
+	if _, err := ef.Apply(gpx, alg); err != nil {
+		t.Fatal(err)
+	}
+	for index, want := range []float64{148, 149, 150, 151} {
+		if elevation := testFusedElevations(gpx)[index]; math.Abs(elevation-want) > 1e-6 {
+			t.Errorf("Ellipsoid: Elevation of point %d: %f, want %f", index, elevation, want)
+		}
+	}
+}
+
+func TestElevationFusionErrors(t *testing.T) {
+	alg := NewKarney("Karney", EllipsoidWGS84)
+	if _, err := NewElevationFusion().Apply(testFusionGPX(t, []float64{100, 100}, []float64{math.NaN(), math.NaN()}), alg); err == nil {
+		t.Error("No barometric elevation: want an error")
+	}
+	ef := NewElevationFusion()
+	ef.TimeConstant = -1
+	if _, err := ef.Apply(testFusionGPX(t, []float64{100, 100}, []float64{200, 200}), alg); err == nil {
+		t.Error("Negative time constant: want an error")
+	}
+}
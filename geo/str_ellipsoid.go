@@ -0,0 +1,82 @@
+package geo
+
+import (
+	"errors"
+	"strings"
+)
+
+// Ellipsoid defines a reference ellipsoid by the equatorial radius (semi-major axis a) and the flattening f = (a-b)/a
+type Ellipsoid struct {
+	Name           string
+	SemiMajorAxisA float64 // Equatorial radius in m
+	Flattening     float64 // Flattening; 0 defines a sphere with the radius SemiMajorAxisA
+}
+
+// Built-in reference ellipsoids; See https://en.wikipedia.org/wiki/Earth_ellipsoid#Historical_Earth_ellipsoids
+var (
+	// EllipsoidWGS84 is the World Geodetic System 1984 ellipsoid used by GPS; See https://en.wikipedia.org/wiki/World_Geodetic_System
+	EllipsoidWGS84 = Ellipsoid{Name: "WGS-84", SemiMajorAxisA: 6378137, Flattening: 1 / 298.257223563}
+	// EllipsoidGRS80 is the Geodetic Reference System 1980 ellipsoid (ETRS89, NAD83)
+	EllipsoidGRS80 = Ellipsoid{Name: "GRS80", SemiMajorAxisA: 6378137, Flattening: 1 / 298.257222100882711}
+	// EllipsoidClarke1866 is the Clarke 1866 ellipsoid (NAD27)
+	EllipsoidClarke1866 = Ellipsoid{Name: "Clarke 1866", SemiMajorAxisA: 6378206.4, Flattening: 1 / 294.978698213898}
+	// EllipsoidBessel1841 is the Bessel 1841 ellipsoid (DHDN / Gauß-Krüger)
+	EllipsoidBessel1841 = Ellipsoid{Name: "Bessel 1841", SemiMajorAxisA: 6377397.155, Flattening: 1 / 299.1528128}
+	// EllipsoidSphere is a sphere with the mean earth radius R1 = (2a+b)/3 of WGS-84
+	EllipsoidSphere = Ellipsoid{Name: "Sphere", SemiMajorAxisA: 6371008.8, Flattening: 0}
+)
+
+// Ellipsoids contains all built-in reference ellipsoids
+var Ellipsoids = []Ellipsoid{
+	EllipsoidWGS84,
+	EllipsoidGRS80,
+	EllipsoidClarke1866,
+	EllipsoidBessel1841,
+	EllipsoidSphere,
+}
+
+// EllipsoidByName returns the built-in reference ellipsoid with the (case insensitive) name
+func EllipsoidByName(name string) (Ellipsoid, error) {
+	for _, ellipsoid := range Ellipsoids {
+		if strings.EqualFold(ellipsoid.Name, name) {
+			return ellipsoid, nil
+		}
+	}
+	return Ellipsoid{}, errors.New("No ellipsoid found")
+}
+
+// String returns the name of the ellipsoid
+func (e Ellipsoid) String() string {
+	return e.Name
+}
+
+// IsZero returns if the ellipsoid is not defined
+func (e Ellipsoid) IsZero() bool {
+	return e.SemiMajorAxisA == 0
+}
+
+// SemiMinorAxisB returns the polar radius b = a(1-f) in m
+func (e Ellipsoid) SemiMinorAxisB() float64 {
+	return e.SemiMajorAxisA * (1 - e.Flattening)
+}
+
+// MeanRadius returns the mean radius R1 = (2a+b)/3 in m
+func (e Ellipsoid) MeanRadius() float64 {
+	return (2*e.SemiMajorAxisA + e.SemiMinorAxisB()) / 3
+}
+
+// InverseFlattening returns 1/f; 0 for a sphere
+func (e Ellipsoid) InverseFlattening() float64 {
+	if e.Flattening == 0 {
+		return 0
+	}
+	return 1 / e.Flattening
+}
+
+// orDefault returns the ellipsoid or WGS-84 if the ellipsoid is not defined
+func (e Ellipsoid) orDefault() Ellipsoid {
+	if e.IsZero() {
+		return EllipsoidWGS84
+	}
+	return e
+}
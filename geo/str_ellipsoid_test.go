@@ -0,0 +1,74 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEllipsoidByName(t *testing.T) {
+	ellipsoid, err := EllipsoidByName("wgs-84")
+	if err != nil || ellipsoid != EllipsoidWGS84 {
+		t.Errorf("EllipsoidByName(wgs-84): %v (%v), want %v", ellipsoid, err, EllipsoidWGS84)
+	}
+	if _, err := EllipsoidByName("Unknown"); err == nil {
+		t.Error("EllipsoidByName(Unknown): want an error")
+	}
+}
+
+func TestEllipsoidAxes(t *testing.T) {
+	if b := EllipsoidWGS84.SemiMinorAxisB(); math.Abs(b-6356752.314245) > 1e-6 {
+		t.Errorf("SemiMinorAxisB: %f, want 6356752.314245", b)
+	}
+	if r := EllipsoidWGS84.MeanRadius(); math.Abs(r-6371008.7714) > 1e-4 {
+		t.Errorf("MeanRadius: %f, want 6371008.7714", r)
+	}
+	if f := EllipsoidWGS84.InverseFlattening(); math.Abs(f-298.257223563) > 1e-9 {
+		t.Errorf("InverseFlattening: %f, want 298.257223563", f)
+	}
+	if f := EllipsoidSphere.InverseFlattening(); f != 0 {
+		t.Errorf("InverseFlattening of the sphere: %f, want 0", f)
+	}
+}
+
+func TestAlgorithmConstructorsDefaultEllipsoid(t *testing.T) {
+	if ellipsoid := NewKarney("Karney", Ellipsoid{}).Ellipsoid; ellipsoid != EllipsoidWGS84 {
+		t.Errorf("NewKarney: %v, want WGS-84", ellipsoid)
+	}
+	if ellipsoid := NewVincenty("Vincenty", Ellipsoid{}).Ellipsoid; ellipsoid != EllipsoidWGS84 {
+		t.Errorf("NewVincenty: %v, want WGS-84", ellipsoid)
+	}
+	if ellipsoid := NewAlgorithmGpxgo("Gpxgo", Ellipsoid{}).Ellipsoid; ellipsoid != EllipsoidWGS84 {
+		t.Errorf("NewAlgorithmGpxgo: %v, want WGS-84", ellipsoid)
+	}
+	// The zero value of an algorithm uses WGS-84, too
+	zero := &Karney{}
+	previousPoint, p1 := &Point{Latitude: 50, Longitude: 8}, &Point{Latitude: 51, Longitude: 9}
+	want, _ := NewKarney("Karney", EllipsoidWGS84).Distance(p1, previousPoint)
+	if distance, err := zero.Distance(p1, previousPoint); err != nil || distance != want {
+		t.Errorf("Distance of the zero value: %f (%v), want %f", distance, err, want)
+	}
+}
+
+func TestAlgorithmsOnEllipsoids(t *testing.T) {
+	previousPoint, p1 := &Point{Latitude: 50, Longitude: 8}, &Point{Latitude: 51, Longitude: 9}
+
+	// The geodesic on the sphere is the great circle of Haversine
+	sphere, _ := NewKarney("Karney", EllipsoidSphere).Distance(p1, previousPoint)
+	haversine := HaversineDistance(previousPoint.Latitude, previousPoint.Longitude, p1.Latitude, p1.Longitude, EllipsoidSphere.SemiMajorAxisA)
+	if math.Abs(sphere-haversine) > 1e-6 {
+		t.Errorf("Karney on the sphere: %f, want %f", sphere, haversine)
+	}
+
+	// Vincenty and Karney agree on each ellipsoid; the distance depends on the ellipsoid
+	wgs84, _ := NewKarney("Karney", EllipsoidWGS84).Distance(p1, previousPoint)
+	for _, ellipsoid := range []Ellipsoid{EllipsoidGRS80, EllipsoidClarke1866, EllipsoidBessel1841} {
+		karney, _ := NewKarney("Karney", ellipsoid).Distance(p1, previousPoint)
+		vincenty, err := NewVincenty("Vincenty", ellipsoid).Distance(p1, previousPoint)
+		if err != nil || math.Abs(karney-vincenty) > 1e-3 {
+			t.Errorf("%v: Vincenty %f (%v), Karney %f", ellipsoid, vincenty, err, karney)
+		}
+		if ellipsoid != EllipsoidGRS80 && math.Abs(karney-wgs84) < 1 {
+			t.Errorf("%v: %f, want a distance different from WGS-84 %f", ellipsoid, karney, wgs84)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package geo
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+// Activity types as returned by Algorithm.CheckActivityType (Strava numbers)
+const (
+	ActivityTypeCycling = "1"
+	ActivityTypeWalking = "4"
+	ActivityTypeRunning = "9"
+)
+
+// EnergyMethod defines how the energy expenditure is estimated
+type EnergyMethod int
+
+// Energy methods
+const (
+	EnergyMethodAuto  EnergyMethod = iota // Power if the points have a measured power; ACSM for running and walking; MET otherwise
+	EnergyMethodMET                       // Metabolic equivalent of the activity type at the average moving speed (Compendium of Physical Activities)
+	EnergyMethodACSM                      // ACSM metabolic equations for running and walking by the speed and grade of each point
+	EnergyMethodPower                     // Mechanical work of the (measured or estimated) power of each point with a gross efficiency of GrossEfficiency
+)
+
+// String returns the name of the energy method
+func (m EnergyMethod) String() string {
+	switch m {
+	case EnergyMethodMET:
+		return "MET"
+	case EnergyMethodACSM:
+		return "ACSM"
+	case EnergyMethodPower:
+		return "Power"
+	}
+	return "Auto"
+}
+
+// Constants of the energy estimation
+const (
+	GrossEfficiency            = 0.24 // The ratio of the mechanical work to the metabolic energy for cycling
+	KilocaloriesPerKilojoule   = 1 / 4.184
+	KilocaloriesPerLiterOxygen = 5.0 // The energy (kcal) of one litre oxygen
+	RestingOxygenUptake        = 3.5 // The oxygen uptake (ml/(kg*min)) at rest; 1 MET
+)
+
+// Energy represents the estimated energy expenditure of an activity
+type Energy struct {
+	Valid        bool
+	Method       EnergyMethod // The method used; EnergyMethodAuto for a GPX with tracks of different methods
+	ActivityType string       // The activity type used; empty for a GPX with tracks of different activity types
+	Kilocalories float64      // The estimated energy expenditure (kcal)
+	Work         float64      // The mechanical work (kJ) of the power; only for EnergyMethodPower
+}
+
+// metSpeed is the MET of an activity from the min speed (km/h)
+type metSpeed struct {
+	minSpeed float64
+	met      float64
+}
+
+// metTables contains the MET by speed of the activity types; See: Ainsworth et al., Compendium of Physical Activities (2011)
+var metTables = map[string][]metSpeed{
+	ActivityTypeCycling: {{0, 4.0}, {16.1, 6.8}, {19.3, 8.0}, {22.5, 10.0}, {25.7, 12.0}, {30.6, 15.8}},
+	ActivityTypeWalking: {{0, 2.0}, {3.2, 2.8}, {4.0, 3.0}, {4.8, 3.5}, {5.6, 4.3}, {6.4, 5.0}, {7.2, 7.0}},
+	ActivityTypeRunning: {{0, 6.0}, {8.0, 8.3}, {8.4, 9.0}, {9.7, 9.8}, {10.8, 10.5}, {11.3, 11.0}, {12.1, 11.5}, {12.9, 11.8}, {13.8, 12.3}, {14.5, 12.8}, {16.1, 14.5}, {17.7, 16.0}, {19.3, 19.0}, {20.9, 19.8}, {22.5, 23.0}},
+}
+
+// MET returns the metabolic equivalent of the activity type at the speed (m/s)
+func MET(activityType string, speed float64) (float64, error) {
+	table, ok := metTables[activityType]
+	if !ok {
+		return 0, errors.New("No MET for the activity type")
+	}
+	met := table[0].met
+	for _, entry := range table {
+		if speed*3.6 >= entry.minSpeed {
+			met = entry.met
+		}
+	}
+	return met, nil
+}
+
+// ACSMOxygenUptake returns the oxygen uptake (ml/(kg*min)) of running or walking at the speed (m/s) and grade (0.05 == 5%) by the ACSM metabolic equations; a negative grade is used as flat ground
+func ACSMOxygenUptake(activityType string, speed float64, grade float64) (float64, error) {
+	metersPerMinute := speed * 60
+	grade = math.Max(grade, 0)
+	switch activityType {
+	case ActivityTypeRunning:
+		return 0.2*metersPerMinute + 0.9*metersPerMinute*grade + RestingOxygenUptake, nil
+	case ActivityTypeWalking:
+		return 0.1*metersPerMinute + 1.8*metersPerMinute*grade + RestingOxygenUptake, nil
+	}
+	return 0, errors.New("ACSM equations are only defined for running and walking")
+}
+
+// ResolveActivityType returns the activity type (see ActivityTypeCycling, ...) of the type of a track or gpx
+func ResolveActivityType(activityType string, alg Algorithm) (string, error) {
+	switch activityType {
+	case ActivityTypeCycling, ActivityTypeWalking, ActivityTypeRunning:
+		return activityType, nil
+	}
+	return alg.CheckActivityType(strings.ToLower(activityType))
+}
+
+// hasPower returns if a point of the track has a measured power; an estimated power (see PowerEstimator) is not a reason to prefer the power method
+func (track *GPXTrack) hasPower() bool {
+	for segmentNo := range track.Segments {
+		for index := range track.Segments[segmentNo].Points {
+			if track.Segments[segmentNo].Points[index].Power.NotNull() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// EstimateEnergy (GPXTrack) returns the estimated energy expenditure of the track for the weight of the athlete; the point data and the movement stats must be set
+func (track *GPXTrack) EstimateEnergy(profile *AthleteProfile, method EnergyMethod, alg Algorithm) (Energy, error) {
+	return track.estimateEnergy(track.Type, profile, method, alg)
+}
+
+// estimateEnergy returns the estimated energy expenditure of the track for the activity type
+func (track *GPXTrack) estimateEnergy(trackType string, profile *AthleteProfile, method EnergyMethod, alg Algorithm) (Energy, error) {
+	var energy Energy
+	if profile.Weight <= 0 {
+		return energy, errors.New("Weight of the athlete is not set")
+	}
+	activityType, errActivityType := ResolveActivityType(trackType, alg)
+
+	if method == EnergyMethodAuto {
+		switch {
+		case track.hasPower():
+			method = EnergyMethodPower
+		case activityType == ActivityTypeRunning || activityType == ActivityTypeWalking:
+			method = EnergyMethodACSM
+		default:
+			method = EnergyMethodMET
+		}
+	}
+	if method != EnergyMethodPower && errActivityType != nil {
+		return energy, errActivityType
+	}
+	energy.Method = method
+	energy.ActivityType = activityType
+
+	switch method {
+	case EnergyMethodPower:
+		var work float64 // J
+		for segmentNo := range track.Segments {
+			points := track.Segments[segmentNo].Points
+			for index := 1; index < len(points); index++ {
+				if power, ok := points[index].EffectivePower(); ok {
+					work += power * points[index].Duration
+				}
+			}
+		}
+		energy.Work = work / 1000
+		energy.Kilocalories = energy.Work / GrossEfficiency * KilocaloriesPerKilojoule
+	case EnergyMethodACSM:
+		var oxygen float64 // ml/kg
+		for segmentNo := range track.Segments {
+			points := track.Segments[segmentNo].Points
+			for index := 1; index < len(points); index++ {
+				oxygenUptake, err := ACSMOxygenUptake(activityType, points[index].Speed, points[index].Grade)
+				if err != nil {
+					return energy, err
+				}
+				oxygen += oxygenUptake * points[index].Duration / 60
+			}
+		}
+		energy.Kilocalories = oxygen * profile.Weight / 1000 * KilocaloriesPerLiterOxygen
+	case EnergyMethodMET:
+		movingData := track.MovementStats.MovingData
+		met, err := MET(activityType, movingData.AverageSpeed)
+		if err != nil {
+			return energy, err
+		}
+		// 1 MET == 1 kcal / (kg * h)
+		energy.Kilocalories = met * profile.Weight * movingData.Duration / 3600
+	default:
+		return energy, errors.New("Unknown energy method")
+	}
+	energy.Valid = true
+	return energy, nil
+}
+
+// SetEnergy (GPX) estimates the energy expenditure of each track and sets GPXTrack.Energy and the sum of all tracks in GPX.Energy.
+// The first error of a track is returned; the energy of the other tracks is set anyway.
+func (gpx *GPX) SetEnergy(profile *AthleteProfile, method EnergyMethod, alg Algorithm) error {
+	var result error
+	gpx.Energy = Energy{}
+	for trackNo := range gpx.Tracks {
+		track := &gpx.Tracks[trackNo]
+		trackType := track.Type
+		if len(trackType) == 0 {
+			trackType = gpx.Type
+		}
+		energy, err := track.estimateEnergy(trackType, profile, method, alg)
+		track.Energy = energy
+		if err != nil {
+			if result == nil {
+				result = err
+			}
+			continue
+		}
+
+		if !gpx.Energy.Valid {
+			gpx.Energy.Method = energy.Method
+			gpx.Energy.ActivityType = energy.ActivityType
+		}
+		if gpx.Energy.Method != energy.Method {
+			gpx.Energy.Method = EnergyMethodAuto
+		}
+		if gpx.Energy.ActivityType != energy.ActivityType {
+			gpx.Energy.ActivityType = ""
+		}
+		gpx.Energy.Valid = true
+		gpx.Energy.Kilocalories += energy.Kilocalories
+		gpx.Energy.Work += energy.Work
+	}
+	return result
+}
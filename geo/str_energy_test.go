@@ -0,0 +1,120 @@
+package geo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mbecker/gpxs/generic"
+)
+
+// testEnergyTrack returns a track of the activity type with 10 legs of 60 sec at the speed (m/s) and grade; the moving data are set
+func testEnergyTrack(activityType string, speed float64, grade float64) *GPXTrack {
+	points := make([]GPXPoint, 11)
+	for index := 1; index < len(points); index++ {
+		points[index].Duration = 60
+		points[index].Distance = speed * 60
+		points[index].Speed = speed
+		points[index].Grade = grade
+	}
+	track := &GPXTrack{Type: activityType, Segments: []GPXTrackSegment{{Points: points}}}
+	track.MovementStats.MovingData.Duration = 600
+	track.MovementStats.MovingData.AverageSpeed = speed
+	return track
+}
+
+func TestMET(t *testing.T) {
+	testCases := []struct {
+		activityType string
+		speed, met   float64
+	}{
+		{ActivityTypeRunning, 10 / 3.6, 9.8},
+		{ActivityTypeRunning, 1, 6.0},
+		{ActivityTypeRunning, 8.4 / 3.6, 9.0},
+		{ActivityTypeRunning, 12.1 / 3.6, 11.5},
+		{ActivityTypeRunning, 12.8 / 3.6, 11.5},
+		{ActivityTypeRunning, 12.9 / 3.6, 11.8},
+		{ActivityTypeRunning, 16.1 / 3.6, 14.5},
+		{ActivityTypeRunning, 20.9 / 3.6, 19.8},
+		{ActivityTypeRunning, 22.5 / 3.6, 23.0},
+		{ActivityTypeRunning, 30 / 3.6, 23.0},
+		{ActivityTypeCycling, 25 / 3.6, 10.0},
+		{ActivityTypeWalking, 5 / 3.6, 3.5},
+	}
+	for _, tc := range testCases {
+		if met, err := MET(tc.activityType, tc.speed); err != nil || met != tc.met {
+			t.Errorf("MET(%s, %f): %f (%v), want %f", tc.activityType, tc.speed, met, err, tc.met)
+		}
+	}
+	if _, err := MET("99", 1); err == nil {
+		t.Error("MET of an unknown activity type: want an error")
+	}
+}
+
+func TestACSMOxygenUptake(t *testing.T) {
+	// Running at 200 m/min: 0.2 * 200 + 3.5 on flat ground, + 0.9 * 200 * 0.05 at 5%; a downhill is flat ground
+	for grade, want := range map[float64]float64{0: 43.5, 0.05: 52.5, -0.05: 43.5} {
+		if uptake, err := ACSMOxygenUptake(ActivityTypeRunning, 200.0/60, grade); err != nil || math.Abs(uptake-want) > 1e-9 {
+			t.Errorf("ACSMOxygenUptake(running, %v): %f (%v), want %f", grade, uptake, err, want)
+		}
+	}
+	if uptake, err := ACSMOxygenUptake(ActivityTypeWalking, 100.0/60, 0.1); err != nil || math.Abs(uptake-31.5) > 1e-9 {
+		t.Errorf("ACSMOxygenUptake(walking): %f (%v), want 31.5", uptake, err)
+	}
+	if _, err := ACSMOxygenUptake(ActivityTypeCycling, 5, 0); err == nil {
+		t.Error("ACSMOxygenUptake(cycling): want an error")
+	}
+}
+
+func TestEstimateEnergy(t *testing.T) {
+	alg := NewKarney("Karney", EllipsoidWGS84)
+	profile := &AthleteProfile{Weight: 70}
+
+	// Running 10 min at 200 m/min: 43.5 ml/(kg*min) * 10 min * 70 kg = 30.45 l oxygen
+	energy, err := testEnergyTrack(ActivityTypeRunning, 200.0/60, 0).EstimateEnergy(profile, EnergyMethodAuto, alg)
+	if err != nil || energy.Method != EnergyMethodACSM || math.Abs(energy.Kilocalories-30.45*KilocaloriesPerLiterOxygen) > 1e-9 {
+		t.Errorf("Running: %+v (%v), want ACSM with %f kcal", energy, err, 30.45*KilocaloriesPerLiterOxygen)
+	}
+
+	// Cycling 10 min at 25 km/h: 10 MET * 70 kg * 1/6 h
+	energy, err = testEnergyTrack("Cycling", 25/3.6, 0).EstimateEnergy(profile, EnergyMethodAuto, alg)
+	if err != nil || energy.Method != EnergyMethodMET || energy.ActivityType != ActivityTypeCycling || math.Abs(energy.Kilocalories-10*70/6.0) > 1e-9 {
+		t.Errorf("Cycling: %+v (%v), want MET with %f kcal", energy, err, 10*70/6.0)
+	}
+
+	// Cycling with 200 W: 120 kJ of work
+	track := testEnergyTrack(ActivityTypeCycling, 25/3.6, 0)
+	for index := 1; index < len(track.Segments[0].Points); index++ {
+		track.Segments[0].Points[index].Power = *generic.NewNullableFloat64(200)
+	}
+	energy, err = track.EstimateEnergy(profile, EnergyMethodAuto, alg)
+	if want := 120 / GrossEfficiency * KilocaloriesPerKilojoule; err != nil || energy.Method != EnergyMethodPower || energy.Work != 120 || math.Abs(energy.Kilocalories-want) > 1e-9 {
+		t.Errorf("Cycling with power: %+v (%v), want Power with 120 kJ and %f kcal", energy, err, want)
+	}
+
+	if _, err := testEnergyTrack(ActivityTypeRunning, 3, 0).EstimateEnergy(&AthleteProfile{}, EnergyMethodAuto, alg); err == nil {
+		t.Error("EstimateEnergy without weight: want an error")
+	}
+	if _, err := testEnergyTrack(ActivityTypeCycling, 3, 0).EstimateEnergy(profile, EnergyMethodACSM, alg); err == nil {
+		t.Error("EstimateEnergy ACSM for cycling: want an error")
+	}
+}
+
+func TestSetEnergy(t *testing.T) {
+	alg := NewKarney("Karney", EllipsoidWGS84)
+	// The second track gets the type of the gpx; the third track has an unknown type
+	gpx := &GPX{Type: ActivityTypeRunning, Tracks: []GPXTrack{
+		*testEnergyTrack(ActivityTypeRunning, 200.0/60, 0),
+		*testEnergyTrack("", 200.0/60, 0),
+		*testEnergyTrack("Unknown", 200.0/60, 0),
+	}}
+	err := gpx.SetEnergy(&AthleteProfile{Weight: 70}, EnergyMethodAuto, alg)
+	if err == nil {
+		t.Error("SetEnergy with an unknown activity type: want an error")
+	}
+	if want := 2 * 30.45 * KilocaloriesPerLiterOxygen; !gpx.Energy.Valid || gpx.Energy.Method != EnergyMethodACSM || math.Abs(gpx.Energy.Kilocalories-want) > 1e-9 {
+		t.Errorf("SetEnergy: %+v, want ACSM with %f kcal", gpx.Energy, want)
+	}
+	if gpx.Tracks[2].Energy.Valid {
+		t.Errorf("Energy of the unknown track: %+v, want invalid", gpx.Tracks[2].Energy)
+	}
+}
@@ -0,0 +1,117 @@
+package geo
+
+import "errors"
+
+// HeightDatum defines the reference surface of elevations
+type HeightDatum int
+
+const (
+	// HeightDatumMeanSeaLevel is the orthometric height above the geoid (mean sea level) like the elevations of GPX files and maps
+	HeightDatumMeanSeaLevel HeightDatum = iota
+	// HeightDatumEllipsoid is the height above the WGS84 ellipsoid like the raw height of a GPS receiver
+	HeightDatumEllipsoid
+)
+
+func (hd HeightDatum) String() string {
+	switch hd {
+	case HeightDatumMeanSeaLevel:
+		return "Mean sea level"
+	case HeightDatumEllipsoid:
+		return "Ellipsoid"
+	}
+	return "Unknown"
+}
+
+// allPoints returns the points of the tracks, routes and waypoints
+func (gpx *GPX) allPoints() []*GPXPoint {
+	var points []*GPXPoint
+	for trackNo := range gpx.Tracks {
+		for segmentNo := range gpx.Tracks[trackNo].Segments {
+			seg := &gpx.Tracks[trackNo].Segments[segmentNo]
+			for index := range seg.Points {
+				points = append(points, &seg.Points[index])
+			}
+		}
+	}
+	for routeNo := range gpx.Routes {
+		for index := range gpx.Routes[routeNo].Points {
+			points = append(points, &gpx.Routes[routeNo].Points[index])
+		}
+	}
+	for index := range gpx.Waypoints {
+		points = append(points, &gpx.Waypoints[index])
+	}
+	return points
+}
+
+// fromMeanSeaLevel converts the elevation (m) above mean sea level at the point into the datum, e.g. the elevation of a DEM into the datum of the gpx:
+// The geoid height of the point (GPXPoint.GeoidHeight) is used if it is set; otherwise the geoid height of the model. The model may be nil for mean sea level.
+func (hd HeightDatum) fromMeanSeaLevel(elevation float64, point *GPXPoint, model GeoidModel) (float64, error) {
+	switch hd {
+	case HeightDatumMeanSeaLevel:
+		return elevation, nil
+	case HeightDatumEllipsoid:
+		if point.GeoidHeight.NotNull() {
+			return elevation + point.GeoidHeight.Value(), nil
+		}
+		if model == nil {
+			return 0, errors.New("Point without geoid height and no geoid model")
+		}
+		geoidHeight, err := model.GeoidHeight(point.Latitude, point.Longitude)
+		if err != nil {
+			return 0, err
+		}
+		return elevation + geoidHeight, nil
+	}
+	return 0, errors.New("Unknown height datum")
+}
+
+// ConvertElevationDatum converts the elevations of the points of the tracks, routes and waypoints into the datum and recalculates the statistics with the algorithm (see Recalculate):
+// The ellipsoidal height is the height above mean sea level plus the geoid height. The geoid height of a point (GPXPoint.GeoidHeight) is used if it is set;
+// otherwise the geoid height of the model is used and set on the point. The model may be nil if all points with an elevation have a geoid height.
+// Set GPX.ElevationDatum before the conversion if the elevations are not above mean sea level, e.g. the raw heights of a GPS receiver.
+func (gpx *GPX) ConvertElevationDatum(datum HeightDatum, model GeoidModel, alg Algorithm) error {
+	if datum != HeightDatumMeanSeaLevel && datum != HeightDatumEllipsoid {
+		return errors.New("Unknown height datum")
+	}
+	if datum == gpx.ElevationDatum {
+		return nil
+	}
+
+	points := gpx.allPoints()
+
+	// The geoid heights are resolved before any elevation is changed that an error of the model leaves the gpx unchanged
+	geoidHeights := make([]float64, len(points))
+	for index, point := range points {
+		if point.Elevation.Null() {
+			continue
+		}
+		if point.GeoidHeight.NotNull() {
+			geoidHeights[index] = point.GeoidHeight.Value()
+			continue
+		}
+		if model == nil {
+			return errors.New("Point without geoid height and no geoid model")
+		}
+		geoidHeight, err := model.GeoidHeight(point.Latitude, point.Longitude)
+		if err != nil {
+			return err
+		}
+		geoidHeights[index] = geoidHeight
+	}
+
+	for index, point := range points {
+		if point.Elevation.Null() {
+			continue
+		}
+		point.GeoidHeight.SetValue(geoidHeights[index])
+		if datum == HeightDatumEllipsoid {
+			point.Elevation.SetValue(point.Elevation.Value() + geoidHeights[index])
+		} else {
+			point.Elevation.SetValue(point.Elevation.Value() - geoidHeights[index])
+		}
+	}
+	gpx.ElevationDatum = datum
+	Recalculate(gpx, alg)
+	return nil
+}
@@ -0,0 +1,62 @@
+package geo
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// testGeoidModel is a geoid model with a constant geoid height (m); a negative height is an error
+type testGeoidModel float64
+
+func (m testGeoidModel) GeoidHeight(latitude float64, longitude float64) (float64, error) {
+	if m < 0 {
+		return 0, errors.New("No geoid height")
+	}
+	return float64(m), nil
+}
+
+func TestConvertElevationDatum(t *testing.T) {
+	alg := NewKarney("Karney", EllipsoidWGS84)
+	gpx := testElevationGPX(t)
+	gpx.Tracks[0].Segments[0].Points[0].GeoidHeight.SetValue(47)
+	if err := gpx.ConvertElevationDatum(HeightDatumEllipsoid, testGeoidModel(48), alg); err != nil {
+		t.Fatal(err)
+	}
+	// The route point has no elevation
+	for index, want := range []float64{57, 68, 78, 88, math.NaN(), 48} {
+		if elevation := testElevations(gpx)[index]; math.Abs(elevation-want) > 1e-6 || math.IsNaN(want) != math.IsNaN(elevation) {
+			t.Errorf("Ellipsoid: Elevation of point %d: %f, want %f", index, elevation, want)
+		}
+	}
+	if gpx.ElevationDatum != HeightDatumEllipsoid || gpx.Tracks[0].Segments[0].Points[1].GeoidHeight.Value() != 48 {
+		t.Errorf("Ellipsoid: Datum %v / geoid height %v, want ellipsoid / 48", gpx.ElevationDatum, gpx.Tracks[0].Segments[0].Points[1].GeoidHeight.Value())
+	}
+
+	// The geoid heights are set on the points; the conversion back does not need the model
+	if err := gpx.ConvertElevationDatum(HeightDatumMeanSeaLevel, nil, alg); err != nil {
+		t.Fatal(err)
+	}
+	for index, want := range []float64{10, 20, 30, 40} {
+		if elevation := testElevations(gpx)[index]; math.Abs(elevation-want) > 1e-6 {
+			t.Errorf("Mean sea level: Elevation of point %d: %f, want %f", index, elevation, want)
+		}
+	}
+
+	// An error of the model leaves the gpx unchanged
+	gpx = testElevationGPX(t)
+	for name, model := range map[string]GeoidModel{"No model": nil, "Model error": testGeoidModel(-1)} {
+		if err := gpx.ConvertElevationDatum(HeightDatumEllipsoid, model, alg); err == nil {
+			t.Errorf("%s: want an error", name)
+		}
+		if gpx.ElevationDatum != HeightDatumMeanSeaLevel || testElevations(gpx)[0] != 10 {
+			t.Errorf("%s: Datum %v / elevation %f, want the unchanged gpx", name, gpx.ElevationDatum, testElevations(gpx)[0])
+		}
+	}
+	if err := gpx.ConvertElevationDatum(HeightDatum(5), nil, alg); err == nil {
+		t.Error("Unknown datum: want an error")
+	}
+	if name := HeightDatumEllipsoid.String(); name != "Ellipsoid" {
+		t.Errorf("String: %s, want Ellipsoid", name)
+	}
+}
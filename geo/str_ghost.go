@@ -0,0 +1,247 @@
+package geo
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// GhostOptions defines how an activity is compared with a ghost (virtual partner)
+type GhostOptions struct {
+	SplitDistance    float64 // The distance (m) of the splits along the route; 0 does not return splits
+	CorridorWidth    float64 // The max distance (m) of a point of the ghost to the route of the activity
+	SectionTolerance float64 // The min change (sec) of the trend of the time gap which ends a section where time was gained / lost
+}
+
+// DefaultGhostOptions are the default options to compare an activity with a ghost
+var DefaultGhostOptions = GhostOptions{
+	SplitDistance:    SplitKilometre,
+	CorridorWidth:    50,
+	SectionTolerance: 5,
+}
+
+// GhostPoint represents the time gap to the ghost at a point of the activity
+type GhostPoint struct {
+	TrackIndex   int // The index of the track in GPX.Tracks of the activity
+	SegmentIndex int // The index of the segment in GPXTrack.Segments
+	Index        int // The index of the point in GPXTrackSegment.Points
+
+	Distance         float64 // The distance (m) along the route
+	ElapsedTime      float64 // The duration (sec) of the activity from the start to the point
+	GhostElapsedTime float64 // The duration (sec) of the ghost from the start to the same distance along the route
+	TimeGap          float64 // ElapsedTime - GhostElapsedTime (sec); positive if the activity is behind the ghost, negative if it is ahead
+	TimeGapChange    float64 // The change (sec) of the time gap from the previous point; positive if time was lost, negative if time was gained
+}
+
+// GhostSplit represents the difference of a split to the split of the ghost
+type GhostSplit struct {
+	Number        int     // The number of the split starting with 1
+	StartDistance float64 // The distance (m) along the route of the start of the split
+	Distance      float64 // The distance (m) of the split; the last split may be shorter
+	Duration      float64 // The duration (sec) of the activity for the split
+	GhostDuration float64 // The duration (sec) of the ghost for the split
+	Difference    float64 // Duration - GhostDuration (sec); positive if time was lost, negative if time was gained
+}
+
+// GhostSection represents a part of the route where time was continuously gained or lost
+type GhostSection struct {
+	StartIndex    int     // The index of the first point of the section in GhostComparison.Points
+	EndIndex      int     // The index of the last point of the section in GhostComparison.Points
+	StartDistance float64 // The distance (m) along the route of the start of the section
+	EndDistance   float64 // The distance (m) along the route of the end of the section
+	TimeChange    float64 // The change (sec) of the time gap; positive if time was lost, negative if time was gained
+}
+
+// GhostComparison represents the comparison of an activity with a ghost along the route of the activity
+type GhostComparison struct {
+	Distance  float64 // The distance (m) along the route covered by the activity and the ghost
+	FinalGap  float64 // The time gap (sec) at the last compared point; positive if the activity is behind the ghost
+	MaxAhead  float64 // The max time (sec) the activity was ahead of the ghost
+	MaxBehind float64 // The max time (sec) the activity was behind the ghost
+
+	Points   []GhostPoint
+	Splits   []GhostSplit
+	Sections []GhostSection
+}
+
+// ghostSample is the elapsed time (sec) at a distance (m) along the route
+type ghostSample struct {
+	trackIndex   int
+	segmentIndex int
+	index        int
+	distance     float64
+	elapsed      float64
+}
+
+// ghostProfile returns the elapsed time of the points of the gpx at the distance along the route; points without a time or without a distance along the route are skipped.
+// along returns the distance (m) along the route of the point with the index of all points of the gpx.
+func ghostProfile(gpx *GPX, along func(point *GPXPoint, pointNo int) (float64, bool)) []ghostSample {
+	var samples []ghostSample
+	var start time.Time
+	var pointNo int
+	for trackNo := range gpx.Tracks {
+		for segmentNo := range gpx.Tracks[trackNo].Segments {
+			seg := &gpx.Tracks[trackNo].Segments[segmentNo]
+			for index := range seg.Points {
+				point := &seg.Points[index]
+				distance, ok := along(point, pointNo)
+				pointNo++
+				if !ok || !point.Timestamp.Valid {
+					continue
+				}
+				if len(samples) == 0 {
+					start = *point.Timestamp.Time
+				}
+				samples = append(samples, ghostSample{
+					trackIndex:   trackNo,
+					segmentIndex: segmentNo,
+					index:        index,
+					distance:     distance,
+					elapsed:      point.Timestamp.Time.Sub(start).Seconds(),
+				})
+			}
+		}
+	}
+	return samples
+}
+
+// elapsedAt returns the elapsed time (sec) when the distance (m) was reached first, interpolated linearly between the samples, starting the search with the sample from;
+// the index of the sample reaching the distance is returned to continue the search of a larger distance. false if the distance was not reached.
+func elapsedAt(samples []ghostSample, distance float64, from int) (float64, int, bool) {
+	for index := from; index < len(samples); index++ {
+		if samples[index].distance < distance {
+			continue
+		}
+		if index == 0 || samples[index].distance == samples[index-1].distance {
+			return samples[index].elapsed, index, true
+		}
+		previous := &samples[index-1]
+		fraction := (distance - previous.distance) / (samples[index].distance - previous.distance)
+		return previous.elapsed + fraction*(samples[index].elapsed-previous.elapsed), index, true
+	}
+	return 0, len(samples), false
+}
+
+// CompareWithGhost compares the activity with the ghost, e.g. a previous activity of the same route: Both are aligned by the distance along the route of the activity
+// and the time gap is returned for each point of the activity until the activity or the ghost ends.
+func CompareWithGhost(activity *GPX, ghost *GPX, options GhostOptions) (GhostComparison, error) {
+	var result GhostComparison
+	polyline := NewPolyline(activity.Points())
+	if polyline.Length() == 0 {
+		return result, errors.New("Activity does not have a route")
+	}
+	samples := ghostProfile(activity, func(point *GPXPoint, pointNo int) (float64, bool) {
+		return polyline.distances[pointNo], true
+	})
+	follower := polylineFollower{polyline: polyline, corridor: options.CorridorWidth}
+	ghostSamples := ghostProfile(ghost, func(point *GPXPoint, pointNo int) (float64, bool) {
+		onRoute, _ := follower.follow(&point.Point, point.Distance)
+		return follower.progress, onRoute
+	})
+	if len(samples) == 0 || len(ghostSamples) == 0 {
+		return result, errors.New("Activity or ghost does not have points with a time on the route")
+	}
+
+	// Time gap of each point
+	var ghostIndex int
+	for _, sample := range samples {
+		ghostElapsed, index, ok := elapsedAt(ghostSamples, sample.distance, ghostIndex)
+		if !ok {
+			break
+		}
+		ghostIndex = index
+		point := GhostPoint{
+			TrackIndex:       sample.trackIndex,
+			SegmentIndex:     sample.segmentIndex,
+			Index:            sample.index,
+			Distance:         sample.distance,
+			ElapsedTime:      sample.elapsed,
+			GhostElapsedTime: ghostElapsed,
+			TimeGap:          sample.elapsed - ghostElapsed,
+		}
+		if len(result.Points) > 0 {
+			point.TimeGapChange = point.TimeGap - result.Points[len(result.Points)-1].TimeGap
+		}
+		result.Points = append(result.Points, point)
+		result.MaxAhead = math.Max(result.MaxAhead, -point.TimeGap)
+		result.MaxBehind = math.Max(result.MaxBehind, point.TimeGap)
+	}
+	last := result.Points[len(result.Points)-1]
+	result.Distance = last.Distance
+	result.FinalGap = last.TimeGap
+
+	result.Splits = ghostSplits(samples, ghostSamples, result.Distance, options.SplitDistance)
+	result.Sections = ghostSections(result.Points, options.SectionTolerance)
+	return result, nil
+}
+
+// ghostSplits returns the difference of the durations of the splits of both profiles up to the distance (m)
+func ghostSplits(samples []ghostSample, ghostSamples []ghostSample, distance float64, splitDistance float64) []GhostSplit {
+	var splits []GhostSplit
+	if splitDistance <= 0 {
+		return splits
+	}
+	var index, ghostIndex int
+	var elapsed, ghostElapsed float64
+	for start := 0.0; start < distance; start += splitDistance {
+		end := math.Min(start+splitDistance, distance)
+		endElapsed, nextIndex, ok := elapsedAt(samples, end, index)
+		ghostEndElapsed, nextGhostIndex, ghostOk := elapsedAt(ghostSamples, end, ghostIndex)
+		if !ok || !ghostOk {
+			break
+		}
+		split := GhostSplit{
+			Number:        len(splits) + 1,
+			StartDistance: start,
+			Distance:      end - start,
+			Duration:      endElapsed - elapsed,
+			GhostDuration: ghostEndElapsed - ghostElapsed,
+		}
+		split.Difference = split.Duration - split.GhostDuration
+		splits = append(splits, split)
+		index, ghostIndex = nextIndex, nextGhostIndex
+		elapsed, ghostElapsed = endElapsed, ghostEndElapsed
+	}
+	return splits
+}
+
+// ghostSections returns the parts where the time gap continuously increases or decreases; a section ends at its extreme time gap if the gap reverses by more than the tolerance (sec)
+func ghostSections(points []GhostPoint, tolerance float64) []GhostSection {
+	var sections []GhostSection
+	if len(points) < 2 {
+		return sections
+	}
+	startIndex, extremeIndex := 0, 0
+	var direction float64 // 1 losing time, -1 gaining time, 0 not decided yet
+	for index := 1; index < len(points); index++ {
+		change := points[index].TimeGap - points[extremeIndex].TimeGap
+		switch {
+		case direction == 0:
+			if change := points[index].TimeGap - points[startIndex].TimeGap; math.Abs(change) > tolerance {
+				direction = math.Copysign(1, change)
+				extremeIndex = index
+			}
+		case change*direction >= 0:
+			extremeIndex = index
+		case -change*direction > tolerance:
+			sections = append(sections, newGhostSection(points, startIndex, extremeIndex))
+			startIndex, direction = extremeIndex, -direction
+			extremeIndex = index
+		}
+	}
+	if direction != 0 {
+		sections = append(sections, newGhostSection(points, startIndex, extremeIndex))
+	}
+	return sections
+}
+
+// newGhostSection returns the section of the points from the start index to the end index
+func newGhostSection(points []GhostPoint, startIndex int, endIndex int) GhostSection {
+	return GhostSection{
+		StartIndex:    startIndex,
+		EndIndex:      endIndex,
+		StartDistance: points[startIndex].Distance,
+		EndDistance:   points[endIndex].Distance,
+		TimeChange:    points[endIndex].TimeGap - points[startIndex].TimeGap,
+	}
+}
@@ -0,0 +1,79 @@
+package geo
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCompareWithGhost(t *testing.T) {
+	// The activity runs 36 legs of 0.0005° north in 10 sec each; the ghost runs the first 18 legs in 8 sec and the last 18 legs in 12 sec
+	path := testLine(50, 8, 50.018, 8, 36)
+	activity := testPathGPX(t, path...)
+	builder := NewGPX().Track("Ghost")
+	elapsed := 0
+	for index, location := range path {
+		if index > 18 {
+			elapsed += 12
+		} else if index > 0 {
+			elapsed += 8
+		}
+		builder.Point(location[0], location[1]+0.0001, 100, testStart.Add(time.Duration(elapsed)*time.Second))
+	}
+	ghost, err := builder.Build(NewKarney("Karney", EllipsoidWGS84))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	comparison, err := CompareWithGhost(activity, ghost, DefaultGhostOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comparison.Points) != len(path) {
+		t.Fatalf("Points: %d, want %d", len(comparison.Points), len(path))
+	}
+	for index, point := range comparison.Points {
+		want := 2 * float64(index)
+		if index > 18 {
+			want = 72 - 2*float64(index)
+		}
+		if math.Abs(point.TimeGap-want) > 1e-6 {
+			t.Errorf("Point %d: Time gap %f, want %f", index, point.TimeGap, want)
+		}
+	}
+	leg := NewPolyline(activity.Points()).Length() / 36
+	if math.Abs(comparison.Distance-36*leg) > 1e-6 || math.Abs(comparison.FinalGap) > 1e-6 || math.Abs(comparison.MaxBehind-36) > 1e-6 || comparison.MaxAhead != 0 {
+		t.Errorf("Comparison: Distance %f / final gap %f / max behind %f / max ahead %f, want %f / 0 / 36 / 0", comparison.Distance, comparison.FinalGap, comparison.MaxBehind, comparison.MaxAhead, 36*leg)
+	}
+
+	// Time is lost to the point 18 and gained after
+	sections := comparison.Sections
+	if len(sections) != 2 || sections[0].EndIndex != 18 || math.Abs(sections[0].TimeChange-36) > 1e-6 || math.Abs(sections[1].TimeChange+36) > 1e-6 {
+		t.Errorf("Sections: %+v, want 0 - 18 with +36 sec and 18 - 36 with -36 sec", sections)
+	}
+
+	// Two splits of 1 km and the rest
+	splits := comparison.Splits
+	if len(splits) != 3 || splits[2].Distance != comparison.Distance-2000 {
+		t.Fatalf("Splits: %+v, want 3", splits)
+	}
+	if want := 2 * 1000 / leg; math.Abs(splits[0].Difference-want) > 1e-6 || math.Abs(splits[0].Duration-10*1000/leg) > 1e-6 {
+		t.Errorf("First split: %+v, want a difference of %f sec", splits[0], want)
+	}
+	var difference float64
+	for _, split := range splits {
+		difference += split.Difference
+	}
+	if math.Abs(difference-comparison.FinalGap) > 1e-6 {
+		t.Errorf("Sum of the split differences: %f, want the final gap %f", difference, comparison.FinalGap)
+	}
+
+	if _, err := CompareWithGhost(&GPX{}, ghost, DefaultGhostOptions); err == nil {
+		t.Error("CompareWithGhost without an activity: want an error")
+	}
+	// A ghost on another route
+	other := testPathGPX(t, testLine(51, 8, 51.018, 8, 36)...)
+	if _, err := CompareWithGhost(activity, other, DefaultGhostOptions); err == nil {
+		t.Error("CompareWithGhost with a ghost on another route: want an error")
+	}
+}
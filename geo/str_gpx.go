@@ -33,6 +33,7 @@ type GPX struct {
 	Keywords         string
 
 	MovementStats MovementStats
+	Energy        Energy // The sum of the energy expenditure of all tracks; see GPX.SetEnergy
 
 	// TODO:
 	//Extensions []byte
@@ -43,8 +44,9 @@ type GPX struct {
 	 * TODO:
 	 * - [x] add type in converter
 	 */
-	Type        string
-	PointsCount int
+	Type           string
+	PointsCount    int
+	ElevationDatum HeightDatum // The datum of the elevations of the points; see GPX.ConvertElevationDatum
 }
 
 func (gpx *GPX) String() string {
@@ -59,6 +61,15 @@ func (gpx *GPX) String() string {
 	return result
 }
 
+// Points returns the points of all tracks of the gpx
+func (gpx *GPX) Points() []Point {
+	var points []Point
+	for trackNo := range gpx.Tracks {
+		points = append(points, gpx.Tracks[trackNo].Points()...)
+	}
+	return points
+}
+
 //GPXRoute implements a gpx route
 type GPXRoute struct {
 	Name        string
@@ -89,6 +100,7 @@ type GPXTrack struct {
 	Type          string
 	Segments      []GPXTrackSegment
 	MovementStats MovementStats
+	Energy        Energy // The energy expenditure of the track; see GPX.SetEnergy
 }
 
 func (track *GPXTrack) String() string {
@@ -105,6 +117,17 @@ func (track *GPXTrack) String() string {
 	return result
 }
 
+// Points returns the points of all segments of the track
+func (track *GPXTrack) Points() []Point {
+	var points []Point
+	for segmentNo := range track.Segments {
+		for index := range track.Segments[segmentNo].Points {
+			points = append(points, track.Segments[segmentNo].Points[index].Point)
+		}
+	}
+	return points
+}
+
 //GPXTrackSegment represents a segment of a track
 type GPXTrackSegment struct {
 	Points        []GPXPoint
@@ -120,6 +143,80 @@ func (seg *GPXTrackSegment) String() string {
 	return result
 }
 
+// SetGradeData sets the elevation change, grade, vertical speed and grade adjusted distance / pace of each point. The point data (distance, duration, pace) must be set before.
+// If window > 0 the grade and vertical speed are smoothed: The values of each point are calculated from the first and last point of the distance window (m) centered at the point.
+func (seg *GPXTrackSegment) SetGradeData(window float64) {
+	if len(seg.Points) == 0 {
+		return
+	}
+
+	// Cumulative distance and duration of the points
+	distances := make([]float64, len(seg.Points))
+	durations := make([]float64, len(seg.Points))
+	for i := 1; i < len(seg.Points); i++ {
+		distances[i] = distances[i-1] + seg.Points[i].Distance
+		durations[i] = durations[i-1] + seg.Points[i].Duration
+	}
+
+	seg.Points[0].ElevationChange = 0
+	seg.Points[0].Grade = 0
+	seg.Points[0].VerticalSpeed = 0
+
+	start, end := 0, 0
+	for i := 1; i < len(seg.Points); i++ {
+		pt := &seg.Points[i]
+		prevPt := &seg.Points[i-1]
+
+		pt.ElevationChange = 0
+		pt.Grade = 0
+		pt.VerticalSpeed = 0
+		if pt.Elevation.Null() || prevPt.Elevation.Null() {
+			continue
+		}
+		pt.ElevationChange = pt.Elevation.Value() - prevPt.Elevation.Value()
+		if pt.Distance > 0 {
+			pt.Grade = pt.ElevationChange / pt.Distance
+		}
+		if pt.Duration > 0 {
+			pt.VerticalSpeed = pt.ElevationChange / pt.Duration
+		}
+
+		if window <= 0 {
+			continue
+		}
+
+		// The window [center - window/2, center + window/2] around the center of the leg from the previous to this point
+		center := (distances[i-1] + distances[i]) / 2
+		for start < i-1 && distances[start+1] <= center-window/2 {
+			start++
+		}
+		if end < i {
+			end = i
+		}
+		for end < len(seg.Points)-1 && distances[end] < center+window/2 {
+			end++
+		}
+		if seg.Points[start].Elevation.Null() || seg.Points[end].Elevation.Null() {
+			continue
+		}
+		elevationChange := seg.Points[end].Elevation.Value() - seg.Points[start].Elevation.Value()
+		if distance := distances[end] - distances[start]; distance > 0 {
+			pt.Grade = elevationChange / distance
+		}
+		if duration := durations[end] - durations[start]; duration > 0 {
+			pt.VerticalSpeed = elevationChange / duration
+		}
+	}
+
+	// Grade adjusted distance and pace by the energy cost of running at the grade
+	for i := range seg.Points {
+		pt := &seg.Points[i]
+		factor := GradeAdjustmentFactor(pt.Grade)
+		pt.GradeAdjustedDistance = pt.Distance * factor
+		pt.GradeAdjustedPace = pt.Pace / factor
+	}
+}
+
 //GPXPoint represents a point of the gpx file
 type GPXPoint struct {
 	Point
@@ -127,8 +224,7 @@ type GPXPoint struct {
 
 	// TODO: Type
 	MagneticVariation string
-	// TODO: Type
-	GeoidHeight string
+	GeoidHeight       generic.NullableFloat64 // The height (m) of the geoid (mean sea level) above the WGS84 ellipsoid at the point
 	// Description info
 	Name        string
 	Comment     string
@@ -146,6 +242,14 @@ type GPXPoint struct {
 	PositionalDilution generic.NullableFloat64
 	AgeOfDGpsData      generic.NullableFloat64
 	DGpsID             generic.NullableInt
+	// Extensions
+	HeartRate   generic.NullableInt     // The heart rate (bpm)
+	Cadence     generic.NullableInt     // The cadence (rpm)
+	Power       generic.NullableFloat64 // The measured power (W)
+	Temperature generic.NullableFloat64 // The air temperature (°C)
+
+	EstimatedPower      generic.NullableFloat64 // The power (W) estimated by a PowerEstimator; the power stats use it if the point does not have a measured power
+	BarometricElevation generic.NullableFloat64 // The barometric altitude (m) of the device; see ElevationFusion
 }
 
 //GpxBounds contains min/max latitude and longitude
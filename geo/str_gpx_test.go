@@ -0,0 +1,105 @@
+package geo
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/mbecker/gpxs/generic"
+)
+
+// testGradeSegment returns a segment with points 100 m and 60 sec apart at the elevations
+func testGradeSegment(elevations ...float64) *GPXTrackSegment {
+	seg := &GPXTrackSegment{Points: make([]GPXPoint, len(elevations))}
+	for index, elevation := range elevations {
+		seg.Points[index].Elevation = *generic.NewNullableFloat64(elevation)
+		if index > 0 {
+			seg.Points[index].Distance = 100
+			seg.Points[index].Duration = 60
+		}
+	}
+	return seg
+}
+
+func TestSetGradeData(t *testing.T) {
+	seg := testGradeSegment(0, 10, 20, 20, 10)
+	seg.SetGradeData(0)
+	grades := []float64{0, 0.1, 0.1, 0, -0.1}
+	for index, point := range seg.Points {
+		if math.Abs(point.Grade-grades[index]) > 1e-9 || math.Abs(point.VerticalSpeed-grades[index]*100/60) > 1e-9 {
+			t.Errorf("Point %d: Grade %f / vertical speed %f, want %f / %f", index, point.Grade, point.VerticalSpeed, grades[index], grades[index]*100/60)
+		}
+	}
+	if seg.Points[4].ElevationChange != -10 {
+		t.Errorf("Elevation change: %f, want -10", seg.Points[4].ElevationChange)
+	}
+
+	// The window of 200 m around the center of the leg to the point 3 spans the points 1 to 4
+	seg.SetGradeData(200)
+	if grade := seg.Points[3].Grade; math.Abs(grade-0) > 1e-9 {
+		t.Errorf("Smoothed grade of point 3: %f, want 0", grade)
+	}
+	if grade := seg.Points[1].Grade; math.Abs(grade-0.1) > 1e-9 {
+		t.Errorf("Smoothed grade of point 1: %f, want 0.1", grade)
+	}
+
+	// A point without an elevation has no grade
+	seg = testGradeSegment(0, 10, 20)
+	seg.Points[1].Elevation.SetNull()
+	seg.SetGradeData(0)
+	if seg.Points[1].Grade != 0 || seg.Points[2].Grade != 0 {
+		t.Errorf("Grade without elevation: %f / %f, want 0", seg.Points[1].Grade, seg.Points[2].Grade)
+	}
+}
+
+func TestElevationGainAndVAM(t *testing.T) {
+	// 2 x 10 m up in 120 sec, 10 m down
+	builder := NewGPX().Track("Hill")
+	for index, elevation := range []float64{100, 110, 120, 110} {
+		builder.Point(50+float64(index)*0.001, 8, elevation, testStart.Add(time.Duration(index)*time.Minute))
+	}
+	gpx, err := builder.Build(NewKarney("Karney", EllipsoidWGS84))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := gpx.MovementStats.OverallData
+	if math.Abs(data.ElevationGain-20) > 1e-9 || math.Abs(data.ElevationLoss-10) > 1e-9 {
+		t.Errorf("Elevation gain / loss: %f / %f, want 20 / 10", data.ElevationGain, data.ElevationLoss)
+	}
+	if math.Abs(data.VAM-600) > 1e-9 {
+		t.Errorf("VAM: %f, want 600", data.VAM)
+	}
+	if want := 10 / data.Distance; math.Abs(data.AverageGrade-want) > 1e-9 {
+		t.Errorf("Average grade: %f, want %f", data.AverageGrade, want)
+	}
+}
+
+func TestGradeAdjustedPace(t *testing.T) {
+	// 100 m in 60 sec at 10% and at 0%
+	seg := testGradeSegment(0, 10, 10)
+	for index := 1; index < len(seg.Points); index++ {
+		seg.Points[index].Pace = 0.6
+	}
+	seg.SetGradeData(0)
+	uphill := seg.Points[1]
+	if factor := GradeAdjustmentFactor(0.1); math.Abs(uphill.GradeAdjustedDistance-100*factor) > 1e-9 || math.Abs(uphill.GradeAdjustedPace-0.6/factor) > 1e-9 {
+		t.Errorf("Uphill: Grade adjusted distance %f / pace %f, want %f / %f", uphill.GradeAdjustedDistance, uphill.GradeAdjustedPace, 100*factor, 0.6/factor)
+	}
+	if flat := seg.Points[2]; flat.GradeAdjustedDistance != 100 || flat.GradeAdjustedPace != 0.6 {
+		t.Errorf("Flat: Grade adjusted distance %f / pace %f, want 100 / 0.6", flat.GradeAdjustedDistance, flat.GradeAdjustedPace)
+	}
+}
+
+func TestGPXPoints(t *testing.T) {
+	gpx := &GPX{Tracks: []GPXTrack{
+		{Segments: []GPXTrackSegment{{Points: make([]GPXPoint, 2)}, {Points: make([]GPXPoint, 3)}}},
+		{Segments: []GPXTrackSegment{{Points: make([]GPXPoint, 1)}}},
+	}}
+	gpx.Tracks[1].Segments[0].Points[0].Latitude = 50
+	if points := gpx.Tracks[0].Points(); len(points) != 5 {
+		t.Errorf("GPXTrack.Points: %d points, want 5", len(points))
+	}
+	if points := gpx.Points(); len(points) != 6 || points[5].Latitude != 50 {
+		t.Errorf("GPX.Points: %+v, want 6 points", points)
+	}
+}
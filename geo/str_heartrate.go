@@ -0,0 +1,121 @@
+package geo
+
+import (
+	"errors"
+	"math"
+)
+
+// heartRateLegsFunc calls fn with the heart rate (bpm) and the duration (sec) of each leg from one point to the next point which has a heart rate
+type heartRateLegsFunc func(fn func(heartRate float64, duration float64))
+
+// heartRateLegs calls fn for each leg of the segment; the heart rate of a point is used for the duration from the previous point to the point
+func (seg *GPXTrackSegment) heartRateLegs(fn func(heartRate float64, duration float64)) {
+	for index := 1; index < len(seg.Points); index++ {
+		point := &seg.Points[index]
+		if point.HeartRate.NotNull() && point.Duration > 0 {
+			fn(float64(point.HeartRate.Value()), point.Duration)
+		}
+	}
+}
+
+// heartRateLegs calls fn for each leg of all segments of the track
+func (track *GPXTrack) heartRateLegs(fn func(heartRate float64, duration float64)) {
+	for segmentNo := range track.Segments {
+		track.Segments[segmentNo].heartRateLegs(fn)
+	}
+}
+
+// heartRateLegs calls fn for each leg of all tracks of the gpx
+func (gpx *GPX) heartRateLegs(fn func(heartRate float64, duration float64)) {
+	for trackNo := range gpx.Tracks {
+		gpx.Tracks[trackNo].heartRateLegs(fn)
+	}
+}
+
+// TimeInHeartRateZones (GPXTrackSegment) returns the duration (sec) in each heart rate zone of the athlete
+func (seg *GPXTrackSegment) TimeInHeartRateZones(profile *AthleteProfile) ([]float64, error) {
+	return timeInHeartRateZones(seg.heartRateLegs, profile)
+}
+
+// TimeInHeartRateZones (GPXTrack) returns the duration (sec) in each heart rate zone of the athlete
+func (track *GPXTrack) TimeInHeartRateZones(profile *AthleteProfile) ([]float64, error) {
+	return timeInHeartRateZones(track.heartRateLegs, profile)
+}
+
+// TimeInHeartRateZones (GPX) returns the duration (sec) in each heart rate zone of the athlete
+func (gpx *GPX) TimeInHeartRateZones(profile *AthleteProfile) ([]float64, error) {
+	return timeInHeartRateZones(gpx.heartRateLegs, profile)
+}
+
+// BanisterTRIMP (GPXTrack) returns the training impulse by Banister of the track
+func (track *GPXTrack) BanisterTRIMP(profile *AthleteProfile) (float64, error) {
+	return banisterTRIMP(track.heartRateLegs, profile)
+}
+
+// BanisterTRIMP (GPX) returns the training impulse by Banister of all tracks
+func (gpx *GPX) BanisterTRIMP(profile *AthleteProfile) (float64, error) {
+	return banisterTRIMP(gpx.heartRateLegs, profile)
+}
+
+// EdwardsTRIMP (GPXTrack) returns the training load by Edwards of the track
+func (track *GPXTrack) EdwardsTRIMP(profile *AthleteProfile) (float64, error) {
+	return edwardsTRIMP(track.heartRateLegs, profile)
+}
+
+// EdwardsTRIMP (GPX) returns the training load by Edwards of all tracks
+func (gpx *GPX) EdwardsTRIMP(profile *AthleteProfile) (float64, error) {
+	return edwardsTRIMP(gpx.heartRateLegs, profile)
+}
+
+// timeInHeartRateZones sums up the duration of the legs per heart rate zone
+func timeInHeartRateZones(legs heartRateLegsFunc, profile *AthleteProfile) ([]float64, error) {
+	zones, err := profile.HeartRateZones()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]float64, len(zones))
+	legs(func(heartRate float64, duration float64) {
+		for i := len(zones) - 1; i >= 0; i-- {
+			if heartRate >= zones[i].Min {
+				result[i] += duration
+				return
+			}
+		}
+	})
+	return result, nil
+}
+
+// banisterTRIMP sums up duration (min) * HRr * k * e^(b * HRr) of the legs with the heart rate reserve ratio HRr;
+// k = 0.64, b = 1.92 for men and k = 0.86, b = 1.67 for women
+// See: E. W. Banister, Modeling elite athletic performance (1991)
+func banisterTRIMP(legs heartRateLegsFunc, profile *AthleteProfile) (float64, error) {
+	if profile.MaxHeartRate <= profile.RestingHeartRate {
+		return 0, errors.New("Max heart rate must be greater than the resting heart rate")
+	}
+	k, b := 0.64, 1.92
+	if profile.Gender == GenderFemale {
+		k, b = 0.86, 1.67
+	}
+	var trimp float64
+	legs(func(heartRate float64, duration float64) {
+		heartRateReserve, _ := profile.HeartRateReserve(heartRate)
+		heartRateReserve = math.Max(0, math.Min(heartRateReserve, 1))
+		trimp += duration / 60 * heartRateReserve * k * math.Exp(b*heartRateReserve)
+	})
+	return trimp, nil
+}
+
+// edwardsTRIMP sums up the duration (min) in the zones 50-60%, 60-70%, 70-80%, 80-90% and 90-100% of the max heart rate weighted by 1, 2, 3, 4 and 5
+// See: S. Edwards, The Heart Rate Monitor Book (1993)
+func edwardsTRIMP(legs heartRateLegsFunc, profile *AthleteProfile) (float64, error) {
+	if profile.MaxHeartRate <= 0 {
+		return 0, errors.New("Max heart rate is not set")
+	}
+	var trimp float64
+	legs(func(heartRate float64, duration float64) {
+		weight := math.Floor(heartRate/profile.MaxHeartRate*10) - 4
+		weight = math.Max(0, math.Min(weight, 5))
+		trimp += duration / 60 * weight
+	})
+	return trimp, nil
+}
@@ -0,0 +1,81 @@
+package geo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mbecker/gpxs/generic"
+)
+
+// testHeartRateTrack returns a track with legs of 60 sec at the heart rates (bpm)
+func testHeartRateTrack(heartRates ...int) *GPXTrack {
+	points := make([]GPXPoint, len(heartRates)+1)
+	for index, heartRate := range heartRates {
+		points[index+1].Duration = 60
+		points[index+1].HeartRate = *generic.NewNullableInt(heartRate)
+	}
+	return &GPXTrack{Segments: []GPXTrackSegment{{Points: points}}}
+}
+
+func TestTimeInHeartRateZones(t *testing.T) {
+	profile := NewAthleteProfile("Test", GenderMale, 200, 50)
+	// The zones start at 0, 120, 140, 160 and 180 bpm; a heart rate at a boundary is in the upper zone
+	track := testHeartRateTrack(110, 120, 150, 150, 170, 190)
+	durations, err := track.TimeInHeartRateZones(profile)
+	want := []float64{60, 60, 120, 60, 60}
+	if err != nil || len(durations) != len(want) {
+		t.Fatalf("TimeInHeartRateZones: %v (%v), want %v", durations, err, want)
+	}
+	for index := range want {
+		if durations[index] != want[index] {
+			t.Errorf("TimeInHeartRateZones: %v, want %v", durations, want)
+			break
+		}
+	}
+
+	// A point without a heart rate is not counted
+	track.Segments[0].Points[1].HeartRate.SetNull()
+	gpx := &GPX{Tracks: []GPXTrack{*track}}
+	if durations, _ := gpx.TimeInHeartRateZones(profile); durations[0] != 0 {
+		t.Errorf("TimeInHeartRateZones without heart rate: %v, want 0 sec in zone 1", durations)
+	}
+}
+
+func TestBanisterTRIMP(t *testing.T) {
+	// 1 min at 50% of the heart rate reserve: 1 * 0.5 * k * e^(b * 0.5)
+	track := testHeartRateTrack(125)
+	trimp, err := track.BanisterTRIMP(NewAthleteProfile("Test", GenderMale, 200, 50))
+	if err != nil || math.Abs(trimp-0.8357428714953977) > 1e-12 {
+		t.Errorf("BanisterTRIMP male: %f (%v), want 0.835743", trimp, err)
+	}
+	trimp, err = track.BanisterTRIMP(NewAthleteProfile("Test", GenderFemale, 200, 50))
+	if err != nil || math.Abs(trimp-0.9910700407634154) > 1e-12 {
+		t.Errorf("BanisterTRIMP female: %f (%v), want 0.991070", trimp, err)
+	}
+
+	// The heart rate reserve is limited to 100%
+	gpx := &GPX{Tracks: []GPXTrack{*testHeartRateTrack(210, 210, 210, 210, 210)}}
+	trimp, err = gpx.BanisterTRIMP(NewAthleteProfile("Test", GenderMale, 200, 50))
+	if want := 5 * 0.64 * math.Exp(1.92); err != nil || math.Abs(trimp-want) > 1e-9 {
+		t.Errorf("BanisterTRIMP above max: %f (%v), want %f", trimp, err, want)
+	}
+	if _, err := track.BanisterTRIMP(NewAthleteProfile("Test", GenderMale, 50, 50)); err == nil {
+		t.Error("BanisterTRIMP with max == resting: want an error")
+	}
+}
+
+func TestEdwardsTRIMP(t *testing.T) {
+	// The weights 0 (< 50%), 1, 2, 3, 4, 5 and 5 (max) for 1 min each
+	track := testHeartRateTrack(90, 110, 130, 150, 170, 190, 200)
+	trimp, err := track.EdwardsTRIMP(NewAthleteProfile("Test", GenderMale, 200, 50))
+	if err != nil || trimp != 20 {
+		t.Errorf("EdwardsTRIMP: %f (%v), want 20", trimp, err)
+	}
+	gpx := &GPX{Tracks: []GPXTrack{*track, *track}}
+	if trimp, _ := gpx.EdwardsTRIMP(NewAthleteProfile("Test", GenderMale, 200, 50)); trimp != 40 {
+		t.Errorf("EdwardsTRIMP of the gpx: %f, want 40", trimp)
+	}
+	if _, err := track.EdwardsTRIMP(NewAthleteProfile("Test", GenderMale, 0, 50)); err == nil {
+		t.Error("EdwardsTRIMP without max heart rate: want an error")
+	}
+}
@@ -53,8 +53,9 @@ type SDData struct {
 type MovementData struct {
 	Count int // The count of points in this structs
 
-	StartTime NullTime
-	EndTime   NullTime
+	StartTime      NullTime
+	EndTime        NullTime
+	LocalStartTime NullTime // The start time in the time zone of the start location; see GPX.SetLocalTime and DefaultTimeZoneResolver
 
 	Duration float64 // The duration of the gpx, track, segment in sec
 	Distance float64 // The distance of the gpx, track, segment defined by Alogorithm
@@ -71,6 +72,24 @@ type MovementData struct {
 	MaxLongitude float64
 	MinEvelation float64
 	MaxEvelation float64
+
+	ElevationGain  float64 // The sum of the positive elevation changes (m)
+	ElevationLoss  float64 // The sum of the negative elevation changes (m) as a positive value
+	MaxGrade       float64 // The max grade of the points (0.05 == 5%)
+	AverageGrade   float64 // The net elevation change / distance
+	AscentDuration float64 // The duration (sec) of the points with a positive elevation change
+	VAM            float64 // The vertical ascent rate (m/h): ElevationGain / AscentDuration
+
+	GradeAdjustedDistance    float64 // The sum of the grade adjusted distances (m) of the points
+	AverageGradeAdjustedPace float64 // Duration / GradeAdjustedDistance (s/m)
+
+	AverageHeartRate  float64 // The time weighted average heart rate (bpm); 0 if the points do not have a heart rate
+	MaxHeartRate      float64 // The max heart rate (bpm)
+	heartRateDuration float64 // The duration (sec) of the points with a heart rate
+
+	AveragePower  float64 // The time weighted average power (W); 0 if the points do not have a power
+	MaxPower      float64 // The max power (W)
+	powerDuration float64 // The duration (sec) of the points with a power
 }
 
 func (ms *MovementStats) String() string {
@@ -88,20 +107,31 @@ func (md *MovementData) String(title string, prefix string) string {
 	t04, _ := time.ParseDuration(fmt.Sprintf("%ds", int64(md.AveragePace*16.666666666667*60)))
 	var result string
 	result = fmt.Sprintf("%s--- %s ---\n", prefix, title)
-	result += fmt.Sprintf("%sStartTime: %s\n", md.StartTime)
-	result += fmt.Sprintf("%EndTime: %s\n", md.EndTime)
+	result += fmt.Sprintf("%sStartTime: %v\n", prefix, md.StartTime.Time)
+	result += fmt.Sprintf("%sEndTime: %v\n", prefix, md.EndTime.Time)
+	result += fmt.Sprintf("%sLocalStartTime: %v\n", prefix, md.LocalStartTime.Time)
 	result += fmt.Sprintf("%sDuration: %s\n", prefix, t00)
 	result += fmt.Sprintf("%sDistance: %f km\n", prefix, md.Distance/1000.0)
 	result += fmt.Sprintf("%sMax Speed: %f m/sec -> %f km/h\n", prefix, md.MaxSpeed, md.MaxSpeed*3.6)
 	result += fmt.Sprintf("%sAverage Speed: %f m/sec -> %f km/h\n", prefix, md.AverageSpeed, md.AverageSpeed*3.6)
 	result += fmt.Sprintf("%sMax Pace: %f sec/m -> %s/km\n", prefix, md.MaxPace, t03)
 	result += fmt.Sprintf("%sAverage Pace: %f sec/m -> %s/km\n", prefix, md.AveragePace, t04)
-	result += fmt.Sprintf("%MinLatitude: %f\n", md.MinLatitude)
-	result += fmt.Sprintf("%MaxLatitude: %f\n", md.MaxLatitude)
-	result += fmt.Sprintf("%MinLongitude: %f\n", md.MinLongitude)
-	result += fmt.Sprintf("%MaxLongitude: %f\n", md.MaxLongitude)
-	result += fmt.Sprintf("%MinEvelation: %f\n", md.MinEvelation)
-	result += fmt.Sprintf("%MaxEvelation: %f\n", md.MaxEvelation)
+	result += fmt.Sprintf("%sMinLatitude: %f\n", prefix, md.MinLatitude)
+	result += fmt.Sprintf("%sMaxLatitude: %f\n", prefix, md.MaxLatitude)
+	result += fmt.Sprintf("%sMinLongitude: %f\n", prefix, md.MinLongitude)
+	result += fmt.Sprintf("%sMaxLongitude: %f\n", prefix, md.MaxLongitude)
+	result += fmt.Sprintf("%sMinEvelation: %f\n", prefix, md.MinEvelation)
+	result += fmt.Sprintf("%sMaxEvelation: %f\n", prefix, md.MaxEvelation)
+	result += fmt.Sprintf("%sElevation Gain: %f m\n", prefix, md.ElevationGain)
+	result += fmt.Sprintf("%sElevation Loss: %f m\n", prefix, md.ElevationLoss)
+	result += fmt.Sprintf("%sMax Grade: %f %%\n", prefix, md.MaxGrade*100)
+	result += fmt.Sprintf("%sAverage Grade: %f %%\n", prefix, md.AverageGrade*100)
+	result += fmt.Sprintf("%sVAM: %f m/h\n", prefix, md.VAM)
+	result += fmt.Sprintf("%sAverage Grade Adjusted Pace: %f sec/m\n", prefix, md.AverageGradeAdjustedPace)
+	result += fmt.Sprintf("%sAverage Heart Rate: %f bpm\n", prefix, md.AverageHeartRate)
+	result += fmt.Sprintf("%sMax Heart Rate: %f bpm\n", prefix, md.MaxHeartRate)
+	result += fmt.Sprintf("%sAverage Power: %f W\n", prefix, md.AveragePower)
+	result += fmt.Sprintf("%sMax Power: %f W\n", prefix, md.MaxPower)
 	result += fmt.Sprintf("%s------\n", prefix)
 	return result
 }
@@ -158,6 +188,38 @@ func (md *MovementData) SetValues(gpxPoint *GPXPoint, previousGpxPoint *GPXPoint
 	if (md.MaxEvelation == 0 && gpxPoint.Elevation.Value() > 0) || (md.MaxEvelation > gpxPoint.Elevation.Value()) {
 		md.MaxEvelation = gpxPoint.Elevation.Value()
 	}
+
+	// Elevation gain / loss, grade and VAM
+	if gpxPoint.ElevationChange > 0 {
+		md.ElevationGain += gpxPoint.ElevationChange
+		md.AscentDuration += gpxPoint.Duration
+	} else {
+		md.ElevationLoss -= gpxPoint.ElevationChange
+	}
+	if gpxPoint.Grade > md.MaxGrade {
+		md.MaxGrade = gpxPoint.Grade
+	}
+	md.setGradeValues()
+
+	// Grade adjusted pace
+	md.GradeAdjustedDistance += gpxPoint.GradeAdjustedDistance
+	if gradeAdjustedPace, err := alg.Pace(md.GradeAdjustedDistance, md.Duration); err == nil {
+		md.AverageGradeAdjustedPace = gradeAdjustedPace
+	}
+
+	// Heart rate
+	if gpxPoint.HeartRate.NotNull() {
+		heartRate := float64(gpxPoint.HeartRate.Value())
+		if heartRate > md.MaxHeartRate {
+			md.MaxHeartRate = heartRate
+		}
+		md.addHeartRate(heartRate, gpxPoint.Duration)
+	}
+
+	// Power
+	if power, ok := gpxPoint.EffectivePower(); ok {
+		md.addPower(power, gpxPoint.Duration)
+	}
 }
 
 func (md *MovementData) SetValuesFromMovementData(movementData *MovementData, count int, alg Algorithm) {
@@ -219,4 +281,92 @@ func (md *MovementData) SetValuesFromMovementData(movementData *MovementData, co
 	if (md.MaxEvelation == 0 && movementData.MaxEvelation > 0) || (md.MaxEvelation > movementData.MaxEvelation) {
 		md.MaxEvelation = movementData.MaxEvelation
 	}
+
+	// Elevation gain / loss, grade and VAM
+	md.ElevationGain += movementData.ElevationGain
+	md.ElevationLoss += movementData.ElevationLoss
+	md.AscentDuration += movementData.AscentDuration
+	if movementData.MaxGrade > md.MaxGrade {
+		md.MaxGrade = movementData.MaxGrade
+	}
+	md.setGradeValues()
+
+	// Grade adjusted pace
+	md.GradeAdjustedDistance += movementData.GradeAdjustedDistance
+	if gradeAdjustedPace, err := alg.Pace(md.GradeAdjustedDistance, md.Duration); err == nil {
+		md.AverageGradeAdjustedPace = gradeAdjustedPace
+	}
+
+	// Heart rate
+	if movementData.MaxHeartRate > md.MaxHeartRate {
+		md.MaxHeartRate = movementData.MaxHeartRate
+	}
+	md.addHeartRate(movementData.AverageHeartRate, movementData.heartRateDuration)
+
+	// Power
+	md.addPowerData(movementData)
+}
+
+// addHeartRate adds the (average) heart rate of the duration to the time weighted average heart rate
+func (md *MovementData) addHeartRate(heartRate float64, duration float64) {
+	if duration <= 0 {
+		return
+	}
+	md.AverageHeartRate = (md.AverageHeartRate*md.heartRateDuration + heartRate*duration) / (md.heartRateDuration + duration)
+	md.heartRateDuration += duration
+}
+
+// setGradeValues sets the average grade and VAM from the elevation gain / loss, distance and ascent duration
+func (md *MovementData) setGradeValues() {
+	if md.Distance > 0 {
+		md.AverageGrade = (md.ElevationGain - md.ElevationLoss) / md.Distance
+	}
+	if md.AscentDuration > 0 {
+		md.VAM = md.ElevationGain / md.AscentDuration * 3600
+	}
+}
+
+// addPower adds the power (W) of the duration (sec) to the max and time weighted average power
+func (md *MovementData) addPower(power float64, duration float64) {
+	if power > md.MaxPower {
+		md.MaxPower = power
+	}
+	if duration <= 0 {
+		return
+	}
+	md.AveragePower = (md.AveragePower*md.powerDuration + power*duration) / (md.powerDuration + duration)
+	md.powerDuration += duration
+}
+
+// addPowerData adds the max and average power of the movementData
+func (md *MovementData) addPowerData(movementData *MovementData) {
+	if movementData.MaxPower > md.MaxPower {
+		md.MaxPower = movementData.MaxPower
+	}
+	if movementData.powerDuration <= 0 {
+		return
+	}
+	md.AveragePower = (md.AveragePower*md.powerDuration + movementData.AveragePower*movementData.powerDuration) / (md.powerDuration + movementData.powerDuration)
+	md.powerDuration += movementData.powerDuration
+}
+
+// resetPower resets the power data
+func (md *MovementData) resetPower() {
+	md.AveragePower = 0
+	md.MaxPower = 0
+	md.powerDuration = 0
+}
+
+// resetPower resets the power data of the overall, moving and stopped data
+func (ms *MovementStats) resetPower() {
+	ms.OverallData.resetPower()
+	ms.MovingData.resetPower()
+	ms.StoppedData.resetPower()
+}
+
+// addPowerStats adds the power data of the overall, moving and stopped data
+func (ms *MovementStats) addPowerStats(movementStats *MovementStats) {
+	ms.OverallData.addPowerData(&movementStats.OverallData)
+	ms.MovingData.addPowerData(&movementStats.MovingData)
+	ms.StoppedData.addPowerData(&movementStats.StoppedData)
 }
@@ -0,0 +1,82 @@
+package geo
+
+import (
+	"errors"
+	"strings"
+)
+
+// Place is a named place like a city with its region and country
+type Place struct {
+	Name      string  // The name of the place, e.g. "Mainz"
+	Region    string  // The name of the region (first-order administrative division), e.g. "Rheinland-Pfalz"
+	Country   string  // The country code (ISO 3166-1 alpha-2), e.g. "DE"
+	Latitude  float64 // The latitude of the place
+	Longitude float64 // The longitude of the place
+	Distance  float64 // The distance (m) from the location to the place
+}
+
+// String returns the name, region and country of the place, e.g. "Mainz, Rheinland-Pfalz, DE"
+func (p *Place) String() string {
+	var parts []string
+	for _, part := range []string{p.Name, p.Region, p.Country} {
+		if len(part) > 0 {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// GPXPlaces contains the places of the start, the end and the waypoints of a gpx; a place is nil if it is not resolved
+type GPXPlaces struct {
+	Start     *Place
+	End       *Place
+	Waypoints []*Place // The places of the waypoints with the same index as GPX.Waypoints
+}
+
+// ReverseGeocode returns the places of the first and last track point and of each waypoint; the error of the geocoder is returned if neither the start nor the end is resolved
+func (gpx *GPX) ReverseGeocode(geocoder ReverseGeocoder) (GPXPlaces, error) {
+	var places GPXPlaces
+	points := gpx.Points()
+	if len(points) == 0 {
+		return places, errors.New("No track points found")
+	}
+	start, errStart := geocoder.ReverseGeocode(points[0].Latitude, points[0].Longitude)
+	end, errEnd := geocoder.ReverseGeocode(points[len(points)-1].Latitude, points[len(points)-1].Longitude)
+	if errStart != nil && errEnd != nil {
+		return places, errStart
+	}
+	places.Start, places.End = start, end
+
+	places.Waypoints = make([]*Place, len(gpx.Waypoints))
+	for index := range gpx.Waypoints {
+		if place, err := geocoder.ReverseGeocode(gpx.Waypoints[index].Latitude, gpx.Waypoints[index].Longitude); err == nil {
+			places.Waypoints[index] = place
+		}
+	}
+	return places, nil
+}
+
+// SetNameAndDescription sets an empty name of the gpx to the name of the start place (and of the end place if it is another place), e.g. "Mainz - Wiesbaden",
+// and an empty description to the start and end place, e.g. "Start: Mainz, Rheinland-Pfalz, DE; End: Wiesbaden, Hesse, DE"
+func (gpx *GPX) SetNameAndDescription(places GPXPlaces) {
+	if len(gpx.Name) == 0 {
+		var names []string
+		if places.Start != nil {
+			names = append(names, places.Start.Name)
+		}
+		if places.End != nil && (places.Start == nil || places.End.String() != places.Start.String()) {
+			names = append(names, places.End.Name)
+		}
+		gpx.Name = strings.Join(names, " - ")
+	}
+	if len(gpx.Description) == 0 {
+		var descriptions []string
+		if places.Start != nil {
+			descriptions = append(descriptions, "Start: "+places.Start.String())
+		}
+		if places.End != nil {
+			descriptions = append(descriptions, "End: "+places.End.String())
+		}
+		gpx.Description = strings.Join(descriptions, "; ")
+	}
+}
@@ -1,6 +1,8 @@
 package geo
 
 import (
+	"math"
+
 	"github.com/mbecker/gpxs/generic"
 )
 
@@ -16,6 +18,17 @@ type Point struct {
 	Speed    float64 // The speed (m/s) from the previous point to this point
 	Pace     float64 // The pace (m/s) from the previous point to this point
 
+	Bearing       generic.NullableFloat64 // The initial bearing (degree, 0 <= bearing < 360) from the previous to this point; a point which did not move keeps the bearing of the previous point
+	TurnAngle     float64                 // The change of direction (degree, -180 < angle <= 180) from the previous leg to this leg; positive is a right turn (clockwise)
+	HeadingChange float64                 // The cumulative change of direction (degree) since the first point; e.g. +360 is a full clockwise lap
+
+	ElevationChange float64 // The elevation difference (m) from the previous to this point; 0 if one of the points does not have an elevation
+	Grade           float64 // The grade (rise / horizontal distance; 0.05 == 5%) from the previous to this point
+	VerticalSpeed   float64 // The vertical speed (m/s) from the previous to this point
+
+	GradeAdjustedDistance float64 // The distance (m) on flat ground with the same energy cost as the distance from the previous to this point at the grade
+	GradeAdjustedPace     float64 // The pace (s/m) on flat ground with the same energy cost as the pace from the previous to this point at the grade
+
 	IsMoving bool // Is the poin in the moving data (true) or in the sopped data (false)
 }
 
@@ -68,4 +81,47 @@ func (pt *Point) SetPointData(prevPoint *Point, algorithm Algorithm) {
 		pace = 0
 	}
 	pt.Pace = pace
+
+	// Bearing (degree), turn angle and cumulative heading change
+	pt.Bearing.SetNull()
+	pt.TurnAngle = 0
+	pt.HeadingChange = prevPoint.HeadingChange
+	if distance > 0 {
+		if bearing, errBearing := algorithm.Bearing(pt, prevPoint); errBearing == nil {
+			pt.Bearing.SetValue(bearing)
+		}
+	}
+	if pt.Bearing.Null() {
+		// The point did not move: Keep the direction of the previous leg
+		pt.Bearing = prevPoint.Bearing
+	} else if prevPoint.Bearing.NotNull() {
+		pt.TurnAngle = TurnAngle(prevPoint.Bearing.Value(), pt.Bearing.Value())
+		pt.HeadingChange += pt.TurnAngle
+	}
+}
+
+// TurnAngle returns the change of direction (degree, -180 < angle <= 180) from the bearing fromBearing to the bearing toBearing; positive is a right turn (clockwise)
+func TurnAngle(fromBearing float64, toBearing float64) float64 {
+	angle := math.Remainder(toBearing-fromBearing, 360)
+	if angle == -180 {
+		return 180
+	}
+	return angle
+}
+
+// Minetti's polynomial of the energy cost of running; See: A. E. Minetti et al., Energy cost of walking and running at extreme uphill and downhill slopes, J Appl Physiol 93, 1039–1046 (2002)
+const (
+	minettiMinGrade = -0.45 // The min grade of the measurements
+	minettiMaxGrade = 0.45  // The max grade of the measurements
+)
+
+// MinettiEnergyCost returns the energy cost of running (J/(kg*m)) at the grade (0.05 == 5%); the grade is limited to -45% ... 45%
+func MinettiEnergyCost(grade float64) float64 {
+	i := math.Max(minettiMinGrade, math.Min(grade, minettiMaxGrade))
+	return 155.4*math.Pow(i, 5) - 30.4*math.Pow(i, 4) - 43.3*math.Pow(i, 3) + 46.3*math.Pow(i, 2) + 19.5*i + 3.6
+}
+
+// GradeAdjustmentFactor returns the ratio of the energy cost of running at the grade to the energy cost on flat ground; e.g. ~1.66 at 10% and ~0.6 at -10%
+func GradeAdjustmentFactor(grade float64) float64 {
+	return MinettiEnergyCost(grade) / MinettiEnergyCost(0)
 }
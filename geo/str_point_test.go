@@ -0,0 +1,98 @@
+package geo
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// testStart is the start time of the test tracks
+var testStart = time.Date(2020, 6, 1, 8, 0, 0, 0, time.UTC)
+
+func TestTurnAngle(t *testing.T) {
+	testCases := []struct {
+		from, to, angle float64
+	}{
+		{0, 90, 90},
+		{90, 0, -90},
+		{350, 10, 20},
+		{10, 350, -20},
+		{0, 180, 180},
+		{180, 0, 180},
+	}
+	for _, tc := range testCases {
+		if angle := TurnAngle(tc.from, tc.to); angle != tc.angle {
+			t.Errorf("TurnAngle(%v, %v): %v, want %v", tc.from, tc.to, angle, tc.angle)
+		}
+	}
+}
+
+func TestNormalizeBearing(t *testing.T) {
+	for bearing, want := range map[float64]float64{-90: 270, 360: 0, 725: 5, 0: 0} {
+		if normalized := NormalizeBearing(bearing); normalized != want {
+			t.Errorf("NormalizeBearing(%v): %v, want %v", bearing, normalized, want)
+		}
+	}
+	if bearing := InitialBearing(50, 8, 50, 9); math.Abs(bearing-89.617) > 1e-3 {
+		t.Errorf("InitialBearing east: %f, want 89.617", bearing)
+	}
+}
+
+func TestSetPointDataBearing(t *testing.T) {
+	// A clockwise square: North, east, south, west and north again; the fourth point is repeated (no movement)
+	gpx, err := NewGPX().Track("Square").
+		Point(50, 8, 100, testStart).
+		Point(50.001, 8, 100, testStart.Add(10*time.Second)).
+		Point(50.001, 8.0015, 100, testStart.Add(20*time.Second)).
+		Point(50, 8.0015, 100, testStart.Add(30*time.Second)).
+		Point(50, 8.0015, 100, testStart.Add(40*time.Second)).
+		Point(50, 8, 100, testStart.Add(50*time.Second)).
+		Point(50.001, 8, 100, testStart.Add(60*time.Second)).
+		Build(NewKarney("Karney", EllipsoidWGS84))
+	if err != nil {
+		t.Fatal(err)
+	}
+	points := gpx.Tracks[0].Segments[0].Points
+	want := []struct {
+		bearing, turnAngle, headingChange float64
+	}{
+		{0, 0, 0},
+		{0, 0, 0},
+		{90, 90, 90},
+		{180, 90, 180},
+		{180, 0, 180}, // Keeps the bearing of the previous point
+		{270, 90, 270},
+		{0, 90, 360},
+	}
+	if points[0].Bearing.NotNull() {
+		t.Errorf("Bearing of the first point: %v, want null", points[0].Bearing.Value())
+	}
+	for index := 1; index < len(points); index++ {
+		point := points[index]
+		if point.Bearing.Null() || math.Abs(TurnAngle(want[index].bearing, point.Bearing.Value())) > 0.01 {
+			t.Errorf("Point %d: Bearing %v, want %v", index, point.Bearing.Value(), want[index].bearing)
+		}
+		if math.Abs(point.TurnAngle-want[index].turnAngle) > 0.01 || math.Abs(point.HeadingChange-want[index].headingChange) > 0.01 {
+			t.Errorf("Point %d: Turn angle %f / heading change %f, want %v / %v", index, point.TurnAngle, point.HeadingChange, want[index].turnAngle, want[index].headingChange)
+		}
+	}
+}
+
+func TestGradeAdjustmentFactor(t *testing.T) {
+	testCases := []struct {
+		grade, cost, factor float64
+	}{
+		{0, 3.6, 1},
+		{0.1, 5.968214, 1.6578372222},
+		{-0.1, 2.151706, 0.5976961111},
+		{0.5, 19.4260145625, 19.4260145625 / 3.6}, // Limited to 45%
+	}
+	for _, tc := range testCases {
+		if cost := MinettiEnergyCost(tc.grade); math.Abs(cost-tc.cost) > 1e-9 {
+			t.Errorf("MinettiEnergyCost(%v): %f, want %f", tc.grade, cost, tc.cost)
+		}
+		if factor := GradeAdjustmentFactor(tc.grade); math.Abs(factor-tc.factor) > 1e-9 {
+			t.Errorf("GradeAdjustmentFactor(%v): %f, want %f", tc.grade, factor, tc.factor)
+		}
+	}
+}
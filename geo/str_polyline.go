@@ -0,0 +1,157 @@
+package geo
+
+import "math"
+
+// Polyline represents a path of points to calculate the distance (m) of a location to the path. Each leg is projected on its own plane (equirectangular projection
+// at the mean latitude of the leg with the origin at the first point of the leg), so the distances are accurate for long paths, too, if the legs are up to some kilometres.
+// Project uses the plane of the first point of the path.
+type Polyline struct {
+	points    []Point
+	x         []float64 // m east of the first point
+	y         []float64 // m north of the first point
+	legX      []float64 // m east from the point to the next point in the plane of the leg
+	legY      []float64 // m north from the point to the next point in the plane of the leg
+	legCosLat []float64 // The cosine of the mean latitude of the leg from the point to the next point
+	distances []float64 // The distance (m) along the path from the first point to the point
+	latitude  float64
+	longitude float64
+	cosLat    float64
+}
+
+// NewPolyline returns the polyline of the points
+func NewPolyline(points []Point) *Polyline {
+	pl := &Polyline{
+		points:    points,
+		x:         make([]float64, len(points)),
+		y:         make([]float64, len(points)),
+		legX:      make([]float64, len(points)),
+		legY:      make([]float64, len(points)),
+		legCosLat: make([]float64, len(points)),
+		distances: make([]float64, len(points)),
+	}
+	if len(points) == 0 {
+		return pl
+	}
+	pl.latitude = points[0].Latitude
+	pl.longitude = points[0].Longitude
+	pl.cosLat = math.Cos(pl.latitude * math.Pi / 180)
+	for i := range points {
+		pl.x[i], pl.y[i] = pl.Project(points[i].Latitude, points[i].Longitude)
+		if i > 0 {
+			leg := i - 1
+			pl.legCosLat[leg] = math.Cos((points[leg].Latitude + points[i].Latitude) / 2 * math.Pi / 180)
+			pl.legX[leg], pl.legY[leg] = pl.projectOnLeg(leg, points[i].Latitude, points[i].Longitude)
+			pl.distances[i] = pl.distances[leg] + math.Hypot(pl.legX[leg], pl.legY[leg])
+		}
+	}
+	return pl
+}
+
+// Project returns the position (m east, m north) of the latitude / longitude relative to the first point of the polyline
+func (pl *Polyline) Project(latitude float64, longitude float64) (float64, float64) {
+	radius := EllipsoidSphere.SemiMajorAxisA
+	dLon := math.Remainder(longitude-pl.longitude, 360)
+	return radius * dLon * math.Pi / 180 * pl.cosLat, radius * (latitude - pl.latitude) * math.Pi / 180
+}
+
+// projectOnLeg returns the position (m east, m north) of the latitude / longitude relative to the first point of the leg in the plane of the leg
+func (pl *Polyline) projectOnLeg(leg int, latitude float64, longitude float64) (float64, float64) {
+	radius := EllipsoidSphere.SemiMajorAxisA
+	origin := &pl.points[leg]
+	dLon := math.Remainder(longitude-origin.Longitude, 360)
+	return radius * dLon * math.Pi / 180 * pl.legCosLat[leg], radius * (latitude - origin.Latitude) * math.Pi / 180
+}
+
+// Points returns the points of the polyline
+func (pl *Polyline) Points() []Point {
+	return pl.points
+}
+
+// Length returns the length (m) of the polyline
+func (pl *Polyline) Length() float64 {
+	if len(pl.distances) == 0 {
+		return 0
+	}
+	return pl.distances[len(pl.distances)-1]
+}
+
+// Resample returns the points every distance (m) along the polyline including the first and last point; the latitude / longitude is interpolated linearly
+func (pl *Polyline) Resample(distance float64) []Point {
+	if len(pl.points) < 2 || distance <= 0 {
+		return pl.points
+	}
+	result := []Point{pl.points[0]}
+	leg := 1
+	for along := distance; along < pl.Length(); along += distance {
+		for pl.distances[leg] < along {
+			leg++
+		}
+		fraction := (along - pl.distances[leg-1]) / (pl.distances[leg] - pl.distances[leg-1])
+		previous, next := &pl.points[leg-1], &pl.points[leg]
+		point := Point{
+			Latitude:  previous.Latitude + fraction*(next.Latitude-previous.Latitude),
+			Longitude: previous.Longitude + fraction*math.Remainder(next.Longitude-previous.Longitude, 360),
+		}
+		result = append(result, point)
+	}
+	return append(result, pl.points[len(pl.points)-1])
+}
+
+// Locate returns the distance (m) of the location to the nearest position of the polyline and the distance (m) along the polyline to this position
+func (pl *Polyline) Locate(location *Point) (float64, float64) {
+	distance, along, _ := pl.locateFrom(location, 0, math.Inf(1))
+	return distance, along
+}
+
+// locateFrom returns the distance (m) of the location to the nearest position of the legs starting with the leg fromLeg (the leg from the point fromLeg to the next point),
+// the distance along the polyline to this position and the leg of this position; only legs starting before maxAlong (m) are used
+func (pl *Polyline) locateFrom(location *Point, fromLeg int, maxAlong float64) (float64, float64, int) {
+	if len(pl.points) == 0 {
+		return math.Inf(1), 0, 0
+	}
+	if len(pl.points) == 1 {
+		x, y := pl.Project(location.Latitude, location.Longitude)
+		return math.Hypot(x, y), 0, 0
+	}
+
+	minDistance, minAlong, minLeg := math.Inf(1), 0.0, fromLeg
+	for leg := fromLeg; leg < len(pl.points)-1 && pl.distances[leg] <= maxAlong; leg++ {
+		x, y := pl.projectOnLeg(leg, location.Latitude, location.Longitude)
+		dx, dy := pl.legX[leg], pl.legY[leg]
+		fraction := 0.0
+		if lengthSquared := dx*dx + dy*dy; lengthSquared > 0 {
+			fraction = math.Max(0, math.Min((x*dx+y*dy)/lengthSquared, 1))
+		}
+		distance := math.Hypot(x-fraction*dx, y-fraction*dy)
+		if distance < minDistance {
+			minDistance = distance
+			minAlong = pl.distances[leg] + fraction*(pl.distances[leg+1]-pl.distances[leg])
+			minLeg = leg
+		}
+	}
+	return minDistance, minAlong, minLeg
+}
+
+// polylineFollower follows the polyline forward with the locations of a recorded path
+type polylineFollower struct {
+	polyline *Polyline
+	corridor float64 // The max distance (m) of a location on the polyline
+	leg      int     // The leg of the reached progress
+	progress float64 // The distance (m) along the polyline which has been reached
+}
+
+// follow moves the progress forward to the location which travelled the distance (m) from the previous location. The location is searched near the reached progress;
+// if it is not within the corridor there it is searched anywhere ahead (rejoined == true, e.g. after a skipped part). The progress does not change if the location is off the polyline.
+func (pf *polylineFollower) follow(location *Point, travelled float64) (onPolyline bool, rejoined bool) {
+	distance, along, leg := pf.polyline.locateFrom(location, pf.leg, pf.progress+travelled+2*pf.corridor)
+	if distance > pf.corridor {
+		distance, along, leg = pf.polyline.locateFrom(location, pf.leg, math.Inf(1))
+		if distance > pf.corridor {
+			return false, false
+		}
+		rejoined = true
+	}
+	pf.leg = leg
+	pf.progress = math.Max(pf.progress, along)
+	return true, rejoined
+}
@@ -0,0 +1,55 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPolyline(t *testing.T) {
+	polyline := NewPolyline([]Point{{Latitude: 50, Longitude: 8}, {Latitude: 50.001, Longitude: 8}, {Latitude: 50.001, Longitude: 8.001}})
+	north := EllipsoidSphere.SemiMajorAxisA * math.Pi / 180 * 0.001 // m per 0.001°
+	east := north * math.Cos(50.001*math.Pi/180)                    // m per 0.001° on the second leg
+	if length := polyline.Length(); math.Abs(length-(north+east)) > 1e-6 {
+		t.Errorf("Length: %f, want %f", length, north+east)
+	}
+	// 10 m west of the middle of the first leg
+	distance, along := polyline.Locate(&Point{Latitude: 50.0005, Longitude: 8 - 10/(north*math.Cos(50.0005*math.Pi/180))*0.001})
+	if math.Abs(distance-10) > 1e-6 || math.Abs(along-north/2) > 1e-6 {
+		t.Errorf("Locate: %f / %f, want 10 / %f", distance, along, north/2)
+	}
+	if points := polyline.Resample(50); len(points) != 5 || math.Abs(points[2].Latitude-(50+100/north*0.001)) > 1e-9 {
+		t.Errorf("Resample: %d points, want 5 points every 50 m", len(points))
+	}
+}
+
+func TestPolylineLongPath(t *testing.T) {
+	alg := NewKarney("Karney", EllipsoidWGS84)
+	// 20° north in legs of 0.01° and 1° east at the top, so the last legs are far away from the first point
+	var points []Point
+	for i := 0; i < 2000; i++ {
+		points = append(points, Point{Latitude: 40 + float64(i)*0.01, Longitude: 8})
+	}
+	for i := 0; i <= 100; i++ {
+		points = append(points, Point{Latitude: 60, Longitude: 8 + float64(i)*0.01})
+	}
+	polyline := NewPolyline(points)
+	var length float64
+	for i := 1; i < len(points); i++ {
+		distance, _ := alg.Distance(&points[i-1], &points[i])
+		length += distance
+	}
+	if math.Abs(polyline.Length()-length)/length > 0.005 {
+		t.Errorf("Length: %f, want %f", polyline.Length(), length)
+	}
+
+	// 100 m north of the middle of the top
+	location := Point{Latitude: 60 + 100/EllipsoidSphere.SemiMajorAxisA*180/math.Pi, Longitude: 8.5}
+	distance, along := polyline.Locate(&location)
+	if math.Abs(distance-100) > 0.1 {
+		t.Errorf("Locate: Distance %f, want 100", distance)
+	}
+	top, _ := alg.Distance(&Point{Latitude: 60, Longitude: 8}, &Point{Latitude: 60, Longitude: 8.5})
+	if want := polyline.distances[2000] + top; math.Abs(along-want) > 0.005*top {
+		t.Errorf("Locate: Along %f, want %f", along, want)
+	}
+}
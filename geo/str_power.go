@@ -0,0 +1,173 @@
+package geo
+
+import (
+	"errors"
+	"math"
+)
+
+// Default values of the power estimator
+const (
+	DefaultTotalMass            = 85.0    // The mass (kg) of the rider and the bike
+	DefaultCdA                  = 0.32    // The drag area (m²) of a road bike on the hoods
+	DefaultRollingResistance    = 0.005   // The rolling resistance coefficient of road tires on asphalt
+	DefaultDrivetrainEfficiency = 0.976   // The ratio of the power at the wheel to the power at the pedals
+	DefaultAirTemperature       = 15.0    // The air temperature (°C) if a point does not have a temperature
+	DefaultMaxPower             = 2000.0  // The max power (W); higher values are caused by the noise of the speed
+	StandardGravity             = 9.80665 // m/s²
+)
+
+// Constants of the air density
+const (
+	seaLevelPressure    = 101325.0 // Pa
+	specificGasConstant = 287.058  // J/(kg*K) of dry air
+)
+
+// PowerEstimator estimates the cycling power of the points by the forces of gravity, rolling resistance, air resistance and acceleration;
+// the estimates are set in GPXPoint.EstimatedPower that they are not mixed up with the measured power of a power meter
+type PowerEstimator struct {
+	TotalMass            float64 // The mass (kg) of the rider and the bike
+	CdA                  float64 // The drag area (m²): drag coefficient * frontal area
+	RollingResistance    float64 // The rolling resistance coefficient Crr
+	DrivetrainEfficiency float64 // The ratio of the power at the wheel to the power at the pedals
+	AirTemperature       float64 // The air temperature (°C) if a point does not have a temperature
+	HeadWind             float64 // The speed (m/s) of the head wind; negative for a tail wind
+	MaxPower             float64 // The max power (W) of a point; 0 does not limit the power
+}
+
+// NewPowerEstimator returns a power estimator for the mass (kg) of the rider and the bike with default values; DefaultTotalMass if mass <= 0
+func NewPowerEstimator(totalMass float64) *PowerEstimator {
+	if totalMass <= 0 {
+		totalMass = DefaultTotalMass
+	}
+	return &PowerEstimator{
+		TotalMass:            totalMass,
+		CdA:                  DefaultCdA,
+		RollingResistance:    DefaultRollingResistance,
+		DrivetrainEfficiency: DefaultDrivetrainEfficiency,
+		AirTemperature:       DefaultAirTemperature,
+		MaxPower:             DefaultMaxPower,
+	}
+}
+
+// AirDensity returns the air density (kg/m³) at the elevation (m) and the temperature (°C) by the barometric formula
+func AirDensity(elevation float64, temperature float64) float64 {
+	pressure := seaLevelPressure * math.Pow(1-2.25577e-5*elevation, 5.25588)
+	return pressure / (specificGasConstant * (temperature + 273.15))
+}
+
+// Power returns the power (W) at the pedals for the speed (m/s), acceleration (m/s²), grade (0.05 == 5%) and air density (kg/m³); 0 if the rider does not pedal (negative power); limited to MaxPower
+func (pe *PowerEstimator) Power(speed float64, acceleration float64, grade float64, airDensity float64) float64 {
+	if speed <= 0 {
+		return 0
+	}
+	angle := math.Atan(grade)
+	gravity := pe.TotalMass * StandardGravity * math.Sin(angle)
+	rolling := pe.TotalMass * StandardGravity * math.Cos(angle) * pe.RollingResistance
+	airSpeed := speed + pe.HeadWind
+	aero := 0.5 * airDensity * pe.CdA * airSpeed * math.Abs(airSpeed)
+	inertia := pe.TotalMass * acceleration
+
+	efficiency := pe.DrivetrainEfficiency
+	if efficiency <= 0 {
+		efficiency = 1
+	}
+	power := math.Max((gravity+rolling+aero+inertia)*speed/efficiency, 0)
+	if pe.MaxPower > 0 {
+		power = math.Min(power, pe.MaxPower)
+	}
+	return power
+}
+
+// EffectivePower returns the measured power (W) of the point or the estimated power if the point does not have a measured power; false if the point has neither
+func (pt *GPXPoint) EffectivePower() (float64, bool) {
+	if pt.Power.NotNull() {
+		return pt.Power.Value(), true
+	}
+	if pt.EstimatedPower.NotNull() {
+		return pt.EstimatedPower.Value(), true
+	}
+	return 0, false
+}
+
+// EstimateSegment sets the estimated power of each point (except the first point) of the segment of a cycling activity; the point data and grade data must be set
+func (pe *PowerEstimator) EstimateSegment(seg *GPXTrackSegment) {
+	if len(seg.Points) > 0 {
+		seg.Points[0].EstimatedPower.SetNull()
+	}
+	for index := 1; index < len(seg.Points); index++ {
+		point := &seg.Points[index]
+		var acceleration float64
+		if point.Duration > 0 {
+			acceleration = (point.Speed - seg.Points[index-1].Speed) / point.Duration
+		}
+		temperature := pe.AirTemperature
+		if point.Temperature.NotNull() {
+			temperature = point.Temperature.Value()
+		}
+		airDensity := AirDensity(point.Elevation.Value(), temperature)
+		point.EstimatedPower.SetValue(pe.Power(point.Speed, acceleration, point.Grade, airDensity))
+	}
+}
+
+// Estimate sets the estimated power of the points of the cycling tracks (see ResolveActivityType; the type of the gpx is used for a track without a type) and updates the power of the movement stats (see GPX.UpdatePowerStats).
+// The estimated power of the points of other tracks is removed. Estimate returns the number of points with an estimated power or an error if there is no cycling track.
+func (pe *PowerEstimator) Estimate(gpx *GPX, alg Algorithm) (int, error) {
+	var estimated int
+	cycling := false
+	for trackNo := range gpx.Tracks {
+		track := &gpx.Tracks[trackNo]
+		trackType := track.Type
+		if len(trackType) == 0 {
+			trackType = gpx.Type
+		}
+		activityType, err := ResolveActivityType(trackType, alg)
+		isCycling := err == nil && activityType == ActivityTypeCycling
+		cycling = cycling || isCycling
+		for segmentNo := range track.Segments {
+			seg := &track.Segments[segmentNo]
+			if !isCycling {
+				for index := range seg.Points {
+					seg.Points[index].EstimatedPower.SetNull()
+				}
+				continue
+			}
+			pe.EstimateSegment(seg)
+			if len(seg.Points) > 1 {
+				estimated += len(seg.Points) - 1
+			}
+		}
+	}
+	gpx.UpdatePowerStats()
+	if !cycling {
+		return 0, errors.New("No cycling track")
+	}
+	return estimated, nil
+}
+
+// UpdatePowerStats sets the average and max power of the movement stats of the gpx, tracks and segments from the measured or estimated power of the points (see GPXPoint.EffectivePower), e.g. after the power was estimated
+func (gpx *GPX) UpdatePowerStats() {
+	gpx.MovementStats.resetPower()
+	for trackNo := range gpx.Tracks {
+		track := &gpx.Tracks[trackNo]
+		track.MovementStats.resetPower()
+		for segmentNo := range track.Segments {
+			seg := &track.Segments[segmentNo]
+			seg.MovementStats.resetPower()
+			for index := 1; index < len(seg.Points); index++ {
+				point := &seg.Points[index]
+				power, ok := point.EffectivePower()
+				if !ok {
+					continue
+				}
+				if point.IsMoving {
+					seg.MovementStats.MovingData.addPower(power, point.Duration)
+				} else {
+					seg.MovementStats.StoppedData.addPower(power, point.Duration)
+				}
+				seg.MovementStats.OverallData.addPower(power, point.Duration)
+			}
+			track.MovementStats.addPowerStats(&seg.MovementStats)
+		}
+		gpx.MovementStats.addPowerStats(&track.MovementStats)
+	}
+}
@@ -0,0 +1,115 @@
+package geo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mbecker/gpxs/generic"
+)
+
+func TestPowerEstimatorPower(t *testing.T) {
+	if density := AirDensity(0, 15); math.Abs(density-1.2249781262) > 1e-9 {
+		t.Errorf("AirDensity(0, 15): %f, want 1.224978", density)
+	}
+	if density := AirDensity(2000, 15); density >= AirDensity(0, 15) {
+		t.Errorf("AirDensity(2000, 15): %f, want less than at sea level", density)
+	}
+
+	pe := NewPowerEstimator(0)
+	density := AirDensity(0, 15)
+	testCases := []struct {
+		speed, acceleration, grade, power float64
+	}{
+		{10, 0, 0, 243.51922407076253},   // Rolling and air resistance
+		{5, 0, 0.05, 259.67623397689727}, // Climbing
+		{10, 0, -0.1, 0},                 // Not pedaling downhill
+		{0, 0, 0, 0},                     // Standing
+		{20, 5, 0, DefaultMaxPower},      // Limited to the max power
+	}
+	for _, tc := range testCases {
+		if power := pe.Power(tc.speed, tc.acceleration, tc.grade, density); math.Abs(power-tc.power) > 1e-9 {
+			t.Errorf("Power(%v, %v, %v): %f, want %f", tc.speed, tc.acceleration, tc.grade, power, tc.power)
+		}
+	}
+}
+
+// testPowerTrack returns a track of the activity type with 3 legs of 10 sec at 10 m/s on flat ground at sea level
+func testPowerTrack(activityType string) GPXTrack {
+	points := make([]GPXPoint, 4)
+	for index := range points {
+		points[index].Elevation = *generic.NewNullableFloat64(0)
+		points[index].Speed = 10
+		points[index].IsMoving = true
+		if index > 0 {
+			points[index].Distance = 100
+			points[index].Duration = 10
+		}
+	}
+	return GPXTrack{Type: activityType, Segments: []GPXTrackSegment{{Points: points}}}
+}
+
+func TestPowerEstimatorEstimate(t *testing.T) {
+	alg := NewKarney("Karney", EllipsoidWGS84)
+	pe := NewPowerEstimator(0)
+	// A ride with a measured power of 100 W at the last point and a run
+	gpx := &GPX{Tracks: []GPXTrack{testPowerTrack(ActivityTypeCycling), testPowerTrack("Running")}}
+	ride := gpx.Tracks[0].Segments[0].Points
+	ride[3].Power = *generic.NewNullableFloat64(100)
+	run := gpx.Tracks[1].Segments[0].Points
+	run[1].EstimatedPower = *generic.NewNullableFloat64(500) // An estimate of a previous run of the estimator
+
+	estimated, err := pe.Estimate(gpx, alg)
+	if err != nil || estimated != 3 {
+		t.Fatalf("Estimate: %d (%v), want 3", estimated, err)
+	}
+	// The measured power is kept and the estimate is stored separately
+	if ride[3].Power.Value() != 100 || math.Abs(ride[3].EstimatedPower.Value()-243.51922407076253) > 1e-9 || ride[0].EstimatedPower.NotNull() {
+		t.Errorf("Ride: Power %v / estimated power %v, want 100 / 243.519224", ride[3].Power.Value(), ride[3].EstimatedPower.Value())
+	}
+	if power, ok := ride[3].EffectivePower(); !ok || power != 100 {
+		t.Errorf("EffectivePower with a measured power: %f (%v), want 100", power, ok)
+	}
+	if power, ok := ride[1].EffectivePower(); !ok || math.Abs(power-243.51922407076253) > 1e-9 {
+		t.Errorf("EffectivePower without a measured power: %f (%v), want 243.519224", power, ok)
+	}
+	// The run does not get a cycling power; its old estimate is removed
+	for index := range run {
+		if _, ok := run[index].EffectivePower(); ok {
+			t.Errorf("Run: Point %d has a power", index)
+		}
+	}
+	// The power stats use the measured power or the estimate
+	if want := (2*243.51922407076253 + 100) / 3; math.Abs(gpx.MovementStats.MovingData.AveragePower-want) > 1e-9 || math.Abs(gpx.MovementStats.MovingData.MaxPower-243.51922407076253) > 1e-9 {
+		t.Errorf("Power stats: %f / %f, want average %f", gpx.MovementStats.MovingData.AveragePower, gpx.MovementStats.MovingData.MaxPower, want)
+	}
+
+	// A new estimate after an edit replaces the old estimates
+	ride[2].Speed = 0
+	pe.Estimate(gpx, alg)
+	if ride[2].EstimatedPower.Value() != 0 {
+		t.Errorf("Estimated power after an edit: %f, want 0", ride[2].EstimatedPower.Value())
+	}
+
+	if _, err := pe.Estimate(&GPX{Tracks: []GPXTrack{testPowerTrack(ActivityTypeRunning)}}, alg); err == nil {
+		t.Error("Estimate without a cycling track: want an error")
+	}
+}
+
+func TestEstimatedPowerEnergyMethod(t *testing.T) {
+	alg := NewKarney("Karney", EllipsoidWGS84)
+	gpx := &GPX{Tracks: []GPXTrack{testPowerTrack(ActivityTypeCycling)}}
+	track := &gpx.Tracks[0]
+	track.MovementStats.MovingData.Duration = 30
+	track.MovementStats.MovingData.AverageSpeed = 10
+	if _, err := NewPowerEstimator(0).Estimate(gpx, alg); err != nil {
+		t.Fatal(err)
+	}
+	profile := &AthleteProfile{Weight: 70}
+	// An estimated power does not select the power method; the power method uses the estimate
+	if energy, err := track.EstimateEnergy(profile, EnergyMethodAuto, alg); err != nil || energy.Method != EnergyMethodMET {
+		t.Errorf("EstimateEnergy Auto: %+v (%v), want MET", energy, err)
+	}
+	if energy, err := track.EstimateEnergy(profile, EnergyMethodPower, alg); err != nil || math.Abs(energy.Work-3*243.51922407076253*10/1000) > 1e-9 {
+		t.Errorf("EstimateEnergy Power: %+v (%v), want %f kJ", energy, err, 3*243.51922407076253*10/1000)
+	}
+}
@@ -0,0 +1,207 @@
+package geo
+
+import (
+	"math"
+	"math/rand"
+)
+
+// DefaultPrivacyRandomDistance is the default max random distance (m) which extends the privacy zones and the hidden distance of the start / end
+const DefaultPrivacyRandomDistance = 200.0
+
+// CircleZone is a privacy zone within the radius around the center
+type CircleZone struct {
+	Center Point
+	Radius float64 // The radius (m) around the center
+}
+
+// NewCircleZone returns a privacy zone within the radius (m) around the latitude / longitude
+func NewCircleZone(latitude float64, longitude float64, radius float64) *CircleZone {
+	return &CircleZone{
+		Center: Point{Latitude: latitude, Longitude: longitude},
+		Radius: radius,
+	}
+}
+
+// Contains returns if the distance of the location to the center is at most the radius
+func (cz *CircleZone) Contains(location *Point) bool {
+	x, y := NewPolyline([]Point{cz.Center}).Project(location.Latitude, location.Longitude)
+	return math.Hypot(x, y) <= cz.Radius
+}
+
+// Randomize returns a circle with a center moved by a random distance into a random direction and a radius which is extended by this and a random distance; it contains the circle
+func (cz *CircleZone) Randomize(maxDistance float64, random *rand.Rand) PrivacyZone {
+	shift := random.Float64() * maxDistance
+	direction := random.Float64() * 2 * math.Pi
+	radius := EllipsoidSphere.SemiMajorAxisA
+	return &CircleZone{
+		Center: Point{
+			Latitude:  cz.Center.Latitude + shift*math.Cos(direction)/radius*180/math.Pi,
+			Longitude: cz.Center.Longitude + shift*math.Sin(direction)/(radius*math.Cos(cz.Center.Latitude*math.Pi/180))*180/math.Pi,
+		},
+		Radius: cz.Radius + shift + random.Float64()*maxDistance,
+	}
+}
+
+// PolygonZone is a privacy zone within a polygon and the buffer around it
+type PolygonZone struct {
+	Points []Point // The vertices of the polygon; the last vertex is connected to the first vertex
+	Buffer float64 // The distance (m) around the polygon which is in the zone
+}
+
+// NewPolygonZone returns a privacy zone within the polygon of the vertices
+func NewPolygonZone(points []Point) *PolygonZone {
+	return &PolygonZone{Points: points}
+}
+
+// Contains returns if the location is inside the polygon (ray casting) or within the buffer of its boundary
+func (pz *PolygonZone) Contains(location *Point) bool {
+	if len(pz.Points) == 0 {
+		return false
+	}
+	ring := NewPolyline(append(append([]Point(nil), pz.Points...), pz.Points[0]))
+	x, y := ring.Project(location.Latitude, location.Longitude)
+	inside := false
+	for i, j := 0, len(pz.Points)-1; i < len(pz.Points); j, i = i, i+1 {
+		if (ring.y[i] > y) != (ring.y[j] > y) && x < (ring.x[j]-ring.x[i])*(y-ring.y[i])/(ring.y[j]-ring.y[i])+ring.x[i] {
+			inside = !inside
+		}
+	}
+	if inside || pz.Buffer <= 0 {
+		return inside
+	}
+	distance, _ := ring.Locate(location)
+	return distance <= pz.Buffer
+}
+
+// Randomize returns the polygon with a buffer which is extended by a random distance
+func (pz *PolygonZone) Randomize(maxDistance float64, random *rand.Rand) PrivacyZone {
+	return &PolygonZone{
+		Points: pz.Points,
+		Buffer: pz.Buffer + random.Float64()*maxDistance,
+	}
+}
+
+// PrivacyFilter hides the points within privacy zones and the start / end of the tracks, e.g. where the athlete lives, before a gpx is shared.
+// The zones and the hidden distances are extended by random distances (see PrivacyZone.Randomize) that the hidden location cannot be triangulated from the visible boundary.
+type PrivacyFilter struct {
+	Zones             []PrivacyZone
+	HideStartDistance float64 // The distance (m) from the start of each track which is hidden
+	HideEndDistance   float64 // The distance (m) to the end of each track which is hidden
+	RandomDistance    float64 // The max random distance (m) which extends the zones and the hidden distances of the start / end
+	Seed              int64   // The seed of the random distances: Keep a secret seed per athlete that the zones are extended the same way for all activities; the intersection of differently extended zones would reveal the zones
+	StripMetadata     bool    // Should the author and creator of the gpx be removed
+}
+
+// NewPrivacyFilter returns a privacy filter of the zones with the seed of the random distances and the default random distance
+func NewPrivacyFilter(seed int64, zones ...PrivacyZone) *PrivacyFilter {
+	return &PrivacyFilter{
+		Zones:          zones,
+		RandomDistance: DefaultPrivacyRandomDistance,
+		Seed:           seed,
+	}
+}
+
+// Apply removes the points of the tracks, routes and waypoints within the zones and the points of the hidden start / end distance of each track, strips the metadata if StripMetadata is set
+// and recalculates the statistics with the algorithm (see Recalculate). A track segment is separated where points are removed that the hidden part is not bridged.
+// Apply must be called before the gpx is exported (e.g. gxml.ToXML).
+func (pf *PrivacyFilter) Apply(gpx *GPX, alg Algorithm) {
+	random := rand.New(rand.NewSource(pf.Seed))
+	zones := make([]PrivacyZone, len(pf.Zones))
+	for i, zone := range pf.Zones {
+		zones[i] = zone.Randomize(pf.RandomDistance, random)
+	}
+	hideStart, hideEnd := pf.HideStartDistance, pf.HideEndDistance
+	if hideStart > 0 {
+		hideStart += random.Float64() * pf.RandomDistance
+	}
+	if hideEnd > 0 {
+		hideEnd += random.Float64() * pf.RandomDistance
+	}
+	hidden := func(point *GPXPoint) bool {
+		for _, zone := range zones {
+			if zone.Contains(&point.Point) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var tracks []GPXTrack
+	for trackNo := range gpx.Tracks {
+		track := gpx.Tracks[trackNo]
+		track.Segments = filterPrivacySegments(track.Segments, hideStart, hideEnd, hidden, alg)
+		if len(track.Segments) > 0 {
+			tracks = append(tracks, track)
+		}
+	}
+	gpx.Tracks = tracks
+	gpx.renumberTracks()
+
+	for routeNo := range gpx.Routes {
+		route := &gpx.Routes[routeNo]
+		var points []GPXPoint
+		for index := range route.Points {
+			if !hidden(&route.Points[index]) {
+				points = append(points, route.Points[index])
+			}
+		}
+		route.Points = points
+	}
+	var waypoints []GPXPoint
+	for index := range gpx.Waypoints {
+		if !hidden(&gpx.Waypoints[index]) {
+			waypoints = append(waypoints, gpx.Waypoints[index])
+		}
+	}
+	gpx.Waypoints = waypoints
+
+	if pf.StripMetadata {
+		gpx.AuthorName = ""
+		gpx.AuthorEmail = ""
+		gpx.AuthorLink = ""
+		gpx.AuthorLinkText = ""
+		gpx.AuthorLinkType = ""
+		gpx.Creator = ""
+	}
+	Recalculate(gpx, alg)
+}
+
+// filterPrivacySegments returns the segments without the hidden points and the points of the first hideStart / last hideEnd distance (m) of the track; a segment is separated at removed points
+func filterPrivacySegments(segments []GPXTrackSegment, hideStart float64, hideEnd float64, hidden func(point *GPXPoint) bool, alg Algorithm) []GPXTrackSegment {
+	// The distance from the start of the track of each point
+	distances := make([][]float64, len(segments))
+	var total float64
+	for segmentNo := range segments {
+		points := segments[segmentNo].Points
+		distances[segmentNo] = make([]float64, len(points))
+		for index := range points {
+			if index > 0 {
+				if distance, err := alg.Distance(&points[index].Point, &points[index-1].Point); err == nil {
+					total += distance
+				}
+			}
+			distances[segmentNo][index] = total
+		}
+	}
+
+	var result []GPXTrackSegment
+	for segmentNo := range segments {
+		var points []GPXPoint
+		for index := range segments[segmentNo].Points {
+			point := &segments[segmentNo].Points[index]
+			distance := distances[segmentNo][index]
+			if distance < hideStart || distance > total-hideEnd || hidden(point) {
+				if len(points) > 0 {
+					result = append(result, GPXTrackSegment{Points: points})
+					points = nil
+				}
+				continue
+			}
+			points = append(points, *point)
+		}
+		if len(points) > 0 {
+			result = append(result, GPXTrackSegment{Points: points})
+		}
+	}
+	return result
+}
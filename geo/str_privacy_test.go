@@ -0,0 +1,91 @@
+package geo
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestPrivacyZones(t *testing.T) {
+	circle := NewCircleZone(50, 8, 100)
+	metre := 1 / (EllipsoidSphere.SemiMajorAxisA * math.Pi / 180) // ° latitude per m
+	if !circle.Contains(&Point{Latitude: 50 + 99*metre, Longitude: 8}) || circle.Contains(&Point{Latitude: 50 - 101*metre, Longitude: 8}) {
+		t.Error("CircleZone.Contains: want the points within 100 m")
+	}
+
+	square := NewPolygonZone([]Point{{Latitude: 50, Longitude: 8}, {Latitude: 50.002, Longitude: 8}, {Latitude: 50.002, Longitude: 8.003}, {Latitude: 50, Longitude: 8.003}})
+	east := &Point{Latitude: 50.001, Longitude: 8.003 + 40*metre/math.Cos(50.001*math.Pi/180)} // 40 m east of the square
+	if !square.Contains(&Point{Latitude: 50.001, Longitude: 8.0015}) || square.Contains(east) {
+		t.Error("PolygonZone.Contains: want the points inside the polygon")
+	}
+	square.Buffer = 50
+	if !square.Contains(east) {
+		t.Error("PolygonZone.Contains: want the points within the buffer")
+	}
+
+	// A randomized zone contains the zone
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		randomized := circle.Randomize(200, random)
+		for direction := 0.0; direction < 2*math.Pi; direction += math.Pi / 4 {
+			boundary := &Point{Latitude: 50 + 100*metre*math.Cos(direction), Longitude: 8 + 100*metre*math.Sin(direction)/math.Cos(50*math.Pi/180)}
+			if !randomized.Contains(boundary) {
+				t.Errorf("Randomize: %+v does not contain %+v", randomized, boundary)
+			}
+		}
+		if randomized := square.Randomize(200, random).(*PolygonZone); randomized.Buffer < square.Buffer || randomized.Buffer > square.Buffer+200 {
+			t.Errorf("Randomize: Buffer %f, want 50 - 250", randomized.Buffer)
+		}
+	}
+}
+
+// testPrivacyGPX returns a gpx with a track of 41 points 0.0005° (≈ 56 m) north of each other, a route and two waypoints
+func testPrivacyGPX(t *testing.T) *GPX {
+	gpx := testPathGPX(t, testLine(50, 8, 50.02, 8, 40)...)
+	gpx.Creator, gpx.AuthorName = "Watch", "Athlete"
+	gpx.Routes = []GPXRoute{testRoute([][2]float64{{50, 8}, {50.01, 8}, {50.02, 8}})}
+	gpx.Waypoints = testRoute([][2]float64{{50, 8}, {50.01, 8.0005}}).Points
+	return gpx
+}
+
+func TestPrivacyFilter(t *testing.T) {
+	alg := NewKarney("Karney", EllipsoidWGS84)
+	gpx := testPrivacyGPX(t)
+	filter := NewPrivacyFilter(1, NewCircleZone(50.01, 8, 150))
+	filter.RandomDistance = 0
+	filter.HideStartDistance, filter.HideEndDistance = 200, 300
+	filter.StripMetadata = true
+	filter.Apply(gpx, alg)
+
+	// The first 200 m (points 0 - 3), the zone (points 18 - 22) and the last 300 m (points 35 - 40) are removed; the track is separated at the zone
+	if lengths := fmt.Sprint(testSegmentLengths(gpx)); lengths != "[[14 12]]" {
+		t.Fatalf("Apply: Points %s, want [[14 12]]", lengths)
+	}
+	segments := gpx.Tracks[0].Segments
+	if math.Abs(segments[0].Points[0].Latitude-50.002) > 1e-9 || math.Abs(segments[1].Points[0].Latitude-50.0115) > 1e-9 || segments[1].Points[0].Distance != 0 {
+		t.Errorf("Apply: First points %+v / %+v, want 50.002 / 50.0115", segments[0].Points[0].Point, segments[1].Points[0].Point)
+	}
+	if len(gpx.Routes[0].Points) != 2 || len(gpx.Waypoints) != 1 || gpx.Waypoints[0].Latitude != 50 {
+		t.Errorf("Apply: %d route points / waypoints %+v, want 2 / the waypoint outside the zone", len(gpx.Routes[0].Points), gpx.Waypoints)
+	}
+	if gpx.Creator != "" || gpx.AuthorName != "" || gpx.PointsCount != 26 {
+		t.Errorf("Apply: Creator %q / author %q / %d points, want the metadata removed and 26 points", gpx.Creator, gpx.AuthorName, gpx.PointsCount)
+	}
+
+	// The random distances only extend the hidden parts and are the same for the same seed
+	filter.RandomDistance = DefaultPrivacyRandomDistance
+	first, second := testPrivacyGPX(t), testPrivacyGPX(t)
+	filter.Apply(first, alg)
+	filter.Apply(second, alg)
+	if a, b := fmt.Sprint(testSegmentLengths(first)), fmt.Sprint(testSegmentLengths(second)); a != b {
+		t.Errorf("Apply with the same seed: Points %s / %s, want the same points", a, b)
+	}
+	for _, seg := range first.Tracks[0].Segments {
+		for _, point := range seg.Points {
+			if index := math.Round((point.Latitude - 50) / 0.0005); index < 4 || (index >= 18 && index <= 22) || index > 34 {
+				t.Errorf("Apply with random distances: Point %v is not hidden", index)
+			}
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// RouteAdherenceOptions defines when a recorded point is off the planned route
+type RouteAdherenceOptions struct {
+	OffRouteThreshold float64 // The min cross-track distance (m) of an off-route point
+}
+
+// DefaultRouteAdherenceOptions are the default options of the route adherence
+var DefaultRouteAdherenceOptions = RouteAdherenceOptions{
+	OffRouteThreshold: 50,
+}
+
+// RouteProgress represents the position of a recorded point relative to the route
+type RouteProgress struct {
+	SegmentIndex int // The index of the segment in GPXTrack.Segments
+	Index        int // The index of the point in GPXTrackSegment.Points
+	Time         NullTime
+	Deviation    float64 // The cross-track distance (m) of the point to the nearest position of the whole route
+	Progress     float64 // The distance (m) along the route which has been reached
+	OnRoute      bool    // Is the deviation below the off-route threshold
+}
+
+// OffRouteSection represents consecutive recorded points off the route
+type OffRouteSection struct {
+	SegmentIndex int // The index of the segment in GPXTrack.Segments
+	StartIndex   int // The index of the first off-route point in GPXTrackSegment.Points
+	EndIndex     int // The index of the last off-route point in GPXTrackSegment.Points
+	StartTime    NullTime
+	EndTime      NullTime
+	Distance     float64 // The distance (m) recorded off the route
+	Duration     float64 // The duration (sec) off the route
+	MaxDeviation float64 // The max cross-track distance (m) of the points
+}
+
+// RouteAdherence represents how a recorded track follows a planned route
+type RouteAdherence struct {
+	RouteLength       float64 // The length (m) of the route
+	CompletedDistance float64 // The distance (m) of the route which was followed on route
+	CompletedPercent  float64 // CompletedDistance / RouteLength * 100
+
+	MaxDeviation     float64 // The max cross-track distance (m) of the points
+	AverageDeviation float64 // The average cross-track distance (m) of the points
+
+	Progress         []RouteProgress // The progress along the route of each recorded point
+	OffRouteSections []OffRouteSection
+}
+
+// RouteAdherence (GPX) returns the route adherence of each track to the route with the index in GPX.Routes
+func (gpx *GPX) RouteAdherence(routeIndex int, options RouteAdherenceOptions) ([]RouteAdherence, error) {
+	if len(gpx.Routes) == 0 {
+		return nil, errors.New("No route found")
+	}
+	if routeIndex < 0 || routeIndex >= len(gpx.Routes) {
+		return nil, fmt.Errorf("Route %d not found; the gpx has %d routes", routeIndex, len(gpx.Routes))
+	}
+	result := make([]RouteAdherence, len(gpx.Tracks))
+	for trackNo := range gpx.Tracks {
+		result[trackNo] = gpx.Tracks[trackNo].RouteAdherence(&gpx.Routes[routeIndex], options)
+	}
+	return result, nil
+}
+
+// RouteAdherence (GPXTrack) returns the cross-track distance of every point to the route, the off-route sections and the progress along the route.
+// The deviation is the distance to the nearest position of the whole route, e.g. a point which goes back along an already followed part of the route is on the route.
+// The progress only moves forward along the route; a point which rejoins the route after a skipped part continues the progress from there.
+// For the progress a point is searched near the progress of the previous point; only a point which is not on the route there is searched along the whole route ahead.
+func (track *GPXTrack) RouteAdherence(route *GPXRoute, options RouteAdherenceOptions) RouteAdherence {
+	points := make([]Point, len(route.Points))
+	for i := range route.Points {
+		points[i] = route.Points[i].Point
+	}
+	polyline := NewPolyline(points)
+	threshold := options.OffRouteThreshold
+
+	result := RouteAdherence{RouteLength: polyline.Length()}
+	follower := polylineFollower{polyline: polyline, corridor: threshold}
+	var section *OffRouteSection
+	var sumDeviation float64
+	started := false
+
+	for segmentNo := range track.Segments {
+		seg := &track.Segments[segmentNo]
+		for index := range seg.Points {
+			point := &seg.Points[index]
+			// Follow the route forward; a skipped part of the route is not completed
+			previousProgress := follower.progress
+			onRoute, rejoined := follower.follow(&point.Point, point.Distance)
+			deviation, _ := polyline.Locate(&point.Point)
+			if onRoute {
+				if started && !rejoined && index > 0 {
+					result.CompletedDistance += follower.progress - previousProgress
+				}
+				started = true
+			}
+
+			result.Progress = append(result.Progress, RouteProgress{
+				SegmentIndex: segmentNo,
+				Index:        index,
+				Time:         point.Timestamp,
+				Deviation:    deviation,
+				Progress:     follower.progress,
+				OnRoute:      deviation <= threshold,
+			})
+			sumDeviation += deviation
+			result.MaxDeviation = math.Max(result.MaxDeviation, deviation)
+
+			// Off-route sections
+			if deviation <= threshold {
+				section = nil
+				continue
+			}
+			if section == nil || section.SegmentIndex != segmentNo {
+				result.OffRouteSections = append(result.OffRouteSections, OffRouteSection{
+					SegmentIndex: segmentNo,
+					StartIndex:   index,
+					StartTime:    point.Timestamp,
+				})
+				section = &result.OffRouteSections[len(result.OffRouteSections)-1]
+			} else {
+				section.Distance += point.Distance
+				section.Duration += point.Duration
+			}
+			section.EndIndex = index
+			section.EndTime = point.Timestamp
+			section.MaxDeviation = math.Max(section.MaxDeviation, deviation)
+		}
+	}
+
+	if len(result.Progress) > 0 {
+		result.AverageDeviation = sumDeviation / float64(len(result.Progress))
+	}
+	if result.RouteLength > 0 {
+		result.CompletedPercent = math.Min(result.CompletedDistance/result.RouteLength*100, 100)
+	}
+	return result
+}
@@ -0,0 +1,105 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+// testRoute returns a route of the locations
+func testRoute(locations [][2]float64) GPXRoute {
+	route := GPXRoute{Points: make([]GPXPoint, len(locations))}
+	for index, location := range locations {
+		route.Points[index].Latitude, route.Points[index].Longitude = location[0], location[1]
+	}
+	return route
+}
+
+func TestRouteAdherence(t *testing.T) {
+	// A route of 1 km north in legs of 0.0005°; the track follows the route to 50.002, skips the route 286 m east of it and rejoins at 50.0075 (every 0.0005° ≈ 56 m)
+	path := testLine(50, 8, 50.002, 8, 4)
+	path = append(path, testLine(50.0025, 8.004, 50.007, 8.004, 9)...)
+	path = append(path, testLine(50.0075, 8, 50.009, 8, 3)...)
+	gpx := testPathGPX(t, path...)
+	gpx.Routes = []GPXRoute{testRoute(testLine(49, 8, 49.5, 8, 10)), testRoute(testLine(50, 8, 50.009, 8, 18))}
+
+	result, err := gpx.RouteAdherence(1, DefaultRouteAdherenceOptions)
+	if err != nil || len(result) != 1 {
+		t.Fatalf("RouteAdherence: %v (%v), want one result", result, err)
+	}
+	adherence := result[0]
+	north := EllipsoidSphere.SemiMajorAxisA * math.Pi / 180 * 0.0005 // m per 0.0005°
+	if math.Abs(adherence.RouteLength-18*north) > 1e-6 {
+		t.Errorf("Route length: %f, want %f", adherence.RouteLength, 18*north)
+	}
+	// The skipped part from 50.002 to 50.0075 is not completed
+	if completed := 7 * north; math.Abs(adherence.CompletedDistance-completed) > 1e-6 || math.Abs(adherence.CompletedPercent-completed/adherence.RouteLength*100) > 1e-6 {
+		t.Errorf("Completed distance: %f (%f%%), want %f", adherence.CompletedDistance, adherence.CompletedPercent, completed)
+	}
+	deviation := 0.004 * math.Pi / 180 * EllipsoidSphere.SemiMajorAxisA * math.Cos(50.00475*math.Pi/180)
+	if math.Abs(adherence.MaxDeviation-deviation) > 1 {
+		t.Errorf("Max deviation: %f, want %f", adherence.MaxDeviation, deviation)
+	}
+	if len(adherence.OffRouteSections) != 1 {
+		t.Fatalf("Off-route sections: %+v, want 1", adherence.OffRouteSections)
+	}
+	if section := adherence.OffRouteSections[0]; section.StartIndex != 5 || section.EndIndex != 14 || section.Duration != 90 {
+		t.Errorf("Off-route section: %+v, want the points 5 - 14 in 90 sec", section)
+	}
+	if progress := adherence.Progress; len(progress) != len(path) || !progress[4].OnRoute || progress[5].OnRoute || math.Abs(progress[15].Progress-15*north) > 1e-6 {
+		t.Errorf("Progress: %+v", progress)
+	}
+
+	if _, err := gpx.RouteAdherence(2, DefaultRouteAdherenceOptions); err == nil {
+		t.Error("RouteAdherence of the route 2: want an error")
+	}
+	gpx.Routes = nil
+	if _, err := gpx.RouteAdherence(0, DefaultRouteAdherenceOptions); err == nil {
+		t.Error("RouteAdherence without a route: want an error")
+	}
+}
+
+func TestRouteAdherenceOutAndBack(t *testing.T) {
+	// A route 500 m north and back; the track turns back after 300 m
+	route := testRoute(append(testLine(50, 8, 50.0045, 8, 9), testLine(50.004, 8, 50, 8, 8)...))
+	path := append(testLine(50, 8, 50.0027, 8, 6), testLine(50.00225, 8, 50, 8, 5)...)
+	adherence := testPathGPX(t, path...).Tracks[0].RouteAdherence(&route, DefaultRouteAdherenceOptions)
+	// All points are on the route; the progress does not move back and continues on the way back of the route
+	for index, progress := range adherence.Progress {
+		if !progress.OnRoute {
+			t.Errorf("Point %d: Deviation %f, want on the route", index, progress.Deviation)
+		}
+		if index > 0 && progress.Progress < adherence.Progress[index-1].Progress {
+			t.Errorf("Point %d: Progress %f, want at least %f", index, progress.Progress, adherence.Progress[index-1].Progress)
+		}
+	}
+	if last := adherence.Progress[len(path)-1]; math.Abs(last.Progress-adherence.RouteLength) > 1e-6 || last.Deviation > 1e-6 {
+		t.Errorf("Last point: Progress %f / deviation %f, want %f / 0", last.Progress, last.Deviation, adherence.RouteLength)
+	}
+	// The skipped 2 x 200 m at the top are not completed
+	if completed := adherence.CompletedDistance; completed > adherence.RouteLength-300 {
+		t.Errorf("Completed distance: %f, want at most %f", completed, adherence.RouteLength-300)
+	}
+}
+
+func TestRouteAdherenceBacktrack(t *testing.T) {
+	// A route of 1 km north; the track goes back 333 m on the route and follows the route to the end again
+	route := testRoute(testLine(50, 8, 50.009, 8, 18))
+	path := append(testLine(50, 8, 50.006, 8, 12), testLine(50.0055, 8, 50.003, 8, 5)...)
+	path = append(path, testLine(50.0035, 8, 50.009, 8, 11)...)
+	adherence := testPathGPX(t, path...).Tracks[0].RouteAdherence(&route, DefaultRouteAdherenceOptions)
+	// The points going back are on the already followed part of the route
+	if len(adherence.OffRouteSections) != 0 || adherence.MaxDeviation > 1e-6 {
+		t.Errorf("Off-route sections: %+v / max deviation %f, want none / 0", adherence.OffRouteSections, adherence.MaxDeviation)
+	}
+	for index, progress := range adherence.Progress {
+		if !progress.OnRoute {
+			t.Errorf("Point %d: Deviation %f, want on the route", index, progress.Deviation)
+		}
+		if index > 0 && progress.Progress < adherence.Progress[index-1].Progress {
+			t.Errorf("Point %d: Progress %f, want at least %f", index, progress.Progress, adherence.Progress[index-1].Progress)
+		}
+	}
+	if math.Abs(adherence.CompletedDistance-adherence.RouteLength) > 1e-6 {
+		t.Errorf("Completed distance: %f, want %f", adherence.CompletedDistance, adherence.RouteLength)
+	}
+}
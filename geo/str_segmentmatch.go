@@ -0,0 +1,170 @@
+package geo
+
+import "math"
+
+// SegmentMatchOptions defines when a part of a track is a pass through a segment
+type SegmentMatchOptions struct {
+	StartTolerance float64 // The max distance (m) of the first point of a pass to the start of the segment
+	EndTolerance   float64 // The max distance (m) of the last point of a pass to the end of the segment
+	CorridorWidth  float64 // The max distance (m) of all points of a pass to the segment
+}
+
+// DefaultSegmentMatchOptions are the default options to match a segment
+var DefaultSegmentMatchOptions = SegmentMatchOptions{
+	StartTolerance: 25,
+	EndTolerance:   25,
+	CorridorWidth:  50,
+}
+
+// SegmentEffort represents a pass through a segment
+type SegmentEffort struct {
+	TrackIndex   int // The index of the track in GPX.Tracks
+	SegmentIndex int // The index of the segment in GPXTrack.Segments
+	StartIndex   int // The index of the first point of the pass in GPXTrackSegment.Points
+	EndIndex     int // The index of the last point of the pass in GPXTrackSegment.Points
+
+	StartTime NullTime
+	EndTime   NullTime
+
+	Distance           float64 // The distance (m) of the pass
+	ElapsedTime        float64 // The duration (sec) from the first to the last point
+	MovingTime         float64 // The duration (sec) of the moving points
+	AverageSpeed       float64 // Distance / ElapsedTime (m/s)
+	AverageMovingSpeed float64 // Distance / MovingTime (m/s)
+}
+
+// segmentMatcher contains the segment to match
+type segmentMatcher struct {
+	polyline *Polyline
+	start    Point
+	end      Point
+	options  SegmentMatchOptions
+	alg      Algorithm
+}
+
+// SegmentEfforts returns all passes of the tracks through the segment defined by the points of the route, e.g. a favourite climb
+func (gpx *GPX) SegmentEfforts(segment *GPXRoute, options SegmentMatchOptions, alg Algorithm) []SegmentEffort {
+	points := make([]Point, len(segment.Points))
+	for i := range segment.Points {
+		points[i] = segment.Points[i].Point
+	}
+	return gpx.MatchSegment(points, options, alg)
+}
+
+// MatchSegment returns all passes of the tracks through the segment defined by the points; a pass does not span two track segments
+func (gpx *GPX) MatchSegment(segment []Point, options SegmentMatchOptions, alg Algorithm) []SegmentEffort {
+	var efforts []SegmentEffort
+	if len(segment) < 2 {
+		return efforts
+	}
+	matcher := &segmentMatcher{
+		polyline: NewPolyline(segment),
+		start:    segment[0],
+		end:      segment[len(segment)-1],
+		options:  options,
+		alg:      alg,
+	}
+	for trackNo := range gpx.Tracks {
+		for segmentNo := range gpx.Tracks[trackNo].Segments {
+			for _, effort := range matcher.match(&gpx.Tracks[trackNo].Segments[segmentNo]) {
+				effort.TrackIndex = trackNo
+				effort.SegmentIndex = segmentNo
+				efforts = append(efforts, effort)
+			}
+		}
+	}
+	return efforts
+}
+
+// distance returns the distance (m) of the point to the location or +Inf if the distance cannot be calculated
+func (sm *segmentMatcher) distance(point *GPXPoint, location *Point) float64 {
+	distance, err := sm.alg.Distance(&point.Point, location)
+	if err != nil {
+		return math.Inf(1)
+	}
+	return distance
+}
+
+// nearest returns the index of the point nearest to the location of the points from index on which are within the tolerance (m) of the location
+func (sm *segmentMatcher) nearest(points []GPXPoint, index int, location *Point, tolerance float64) (int, int) {
+	nearest := index
+	minDistance := sm.distance(&points[index], location)
+	next := index + 1
+	for ; next < len(points); next++ {
+		distance := sm.distance(&points[next], location)
+		if distance > tolerance {
+			break
+		}
+		if distance < minDistance {
+			minDistance = distance
+			nearest = next
+		}
+	}
+	return nearest, next
+}
+
+// match returns the passes of the track segment through the segment
+func (sm *segmentMatcher) match(seg *GPXTrackSegment) []SegmentEffort {
+	var efforts []SegmentEffort
+	points := seg.Points
+	for index := 0; index < len(points); {
+		if sm.distance(&points[index], &sm.start) > sm.options.StartTolerance {
+			index++
+			continue
+		}
+		// The start of a pass is the point nearest to the start of the segment
+		startIndex, next := sm.nearest(points, index, &sm.start, sm.options.StartTolerance)
+		endIndex, ok := sm.follow(points, startIndex)
+		if !ok {
+			index = next
+			continue
+		}
+		efforts = append(efforts, sm.effort(points, startIndex, endIndex))
+		index = endIndex + 1
+	}
+	return efforts
+}
+
+// follow walks the points from the start index along the segment and returns the index of the end of the pass; false if a point leaves the corridor
+// or skips a part of the segment before the end is reached
+func (sm *segmentMatcher) follow(points []GPXPoint, startIndex int) (int, bool) {
+	length := sm.polyline.Length()
+	follower := polylineFollower{polyline: sm.polyline, corridor: sm.options.CorridorWidth}
+	for index := startIndex + 1; index < len(points); index++ {
+		point := &points[index]
+		if onSegment, rejoined := follower.follow(&point.Point, point.Distance); !onSegment || rejoined {
+			return 0, false
+		}
+
+		if follower.progress >= length-sm.options.EndTolerance && sm.distance(point, &sm.end) <= sm.options.EndTolerance {
+			// The end of a pass is the point nearest to the end of the segment
+			endIndex, _ := sm.nearest(points, index, &sm.end, sm.options.EndTolerance)
+			return endIndex, true
+		}
+	}
+	return 0, false
+}
+
+// effort returns the effort of the points from the start index to the end index
+func (sm *segmentMatcher) effort(points []GPXPoint, startIndex int, endIndex int) SegmentEffort {
+	effort := SegmentEffort{
+		StartIndex: startIndex,
+		EndIndex:   endIndex,
+		StartTime:  points[startIndex].Timestamp,
+		EndTime:    points[endIndex].Timestamp,
+	}
+	for index := startIndex + 1; index <= endIndex; index++ {
+		effort.Distance += points[index].Distance
+		effort.ElapsedTime += points[index].Duration
+		if points[index].IsMoving {
+			effort.MovingTime += points[index].Duration
+		}
+	}
+	if speed, err := sm.alg.Speed(effort.Distance, effort.ElapsedTime); err == nil {
+		effort.AverageSpeed = speed
+	}
+	if speed, err := sm.alg.Speed(effort.Distance, effort.MovingTime); err == nil {
+		effort.AverageMovingSpeed = speed
+	}
+	return effort
+}
@@ -0,0 +1,90 @@
+package geo
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// testPathGPX returns a gpx with a track of the latitude / longitude pairs 10 sec apart
+func testPathGPX(t *testing.T, path ...[2]float64) *GPX {
+	return testPathGPXAt(t, testStart, path...)
+}
+
+// testPathGPXAt returns a gpx with a track of the latitude / longitude pairs 10 sec apart starting at the time
+func testPathGPXAt(t *testing.T, start time.Time, path ...[2]float64) *GPX {
+	builder := NewGPX().Track("Path")
+	for index, location := range path {
+		builder.Point(location[0], location[1], 100, start.Add(time.Duration(index)*10*time.Second))
+	}
+	gpx, err := builder.Build(NewKarney("Karney", EllipsoidWGS84))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return gpx
+}
+
+// testLine returns the locations from the latitude / longitude to the latitude / longitude in the steps (°)
+func testLine(fromLat float64, fromLon float64, toLat float64, toLon float64, steps int) [][2]float64 {
+	line := make([][2]float64, steps+1)
+	for index := range line {
+		fraction := float64(index) / float64(steps)
+		line[index] = [2]float64{fromLat + fraction*(toLat-fromLat), fromLon + fraction*(toLon-fromLon)}
+	}
+	return line
+}
+
+func TestMatchSegment(t *testing.T) {
+	alg := NewKarney("Karney", EllipsoidWGS84)
+	// A segment of 1 km north
+	segment := []Point{{Latitude: 50, Longitude: 8}, {Latitude: 50.005, Longitude: 8}, {Latitude: 50.009, Longitude: 8}}
+
+	// Two passes north with the way back south 140 m east of the segment; every 0.0005° (≈ 56 m)
+	var path [][2]float64
+	path = append(path, testLine(49.998, 8, 50.011, 8, 26)...)
+	path = append(path, testLine(50.011, 8.002, 49.998, 8.002, 26)...)
+	path = append(path, testLine(49.998, 8, 50.011, 8, 26)...)
+	efforts := testPathGPX(t, path...).MatchSegment(segment, DefaultSegmentMatchOptions, alg)
+	if len(efforts) != 2 {
+		t.Fatalf("MatchSegment: %d efforts, want 2", len(efforts))
+	}
+	for index, want := range [][2]int{{4, 22}, {58, 76}} {
+		effort := efforts[index]
+		if effort.StartIndex != want[0] || effort.EndIndex != want[1] {
+			t.Errorf("Effort %d: Points %d - %d, want %d - %d", index, effort.StartIndex, effort.EndIndex, want[0], want[1])
+		}
+		if effort.ElapsedTime != 180 || math.Abs(effort.Distance-1000.8) > 1 || math.Abs(effort.AverageSpeed-effort.Distance/180) > 1e-9 {
+			t.Errorf("Effort %d: Distance %f in %f sec, want 1000.8 m in 180 sec", index, effort.Distance, effort.ElapsedTime)
+		}
+		if !effort.StartTime.Time.Equal(testStart.Add(time.Duration(want[0]) * 10 * time.Second)) {
+			t.Errorf("Effort %d: Start time %v", index, effort.StartTime.Time)
+		}
+	}
+
+	// The pass south does not match the direction of the segment
+	if efforts := testPathGPX(t, testLine(50.011, 8, 49.998, 8, 26)...).MatchSegment(segment, DefaultSegmentMatchOptions, alg); len(efforts) != 0 {
+		t.Errorf("MatchSegment south: %+v, want none", efforts)
+	}
+	// A detour 200 m east of the segment leaves the corridor
+	path = testLine(49.998, 8, 50.004, 8, 12)
+	path = append(path, testLine(50.004, 8.003, 50.006, 8.003, 4)...)
+	path = append(path, testLine(50.006, 8, 50.011, 8, 10)...)
+	if efforts := testPathGPX(t, path...).MatchSegment(segment, DefaultSegmentMatchOptions, alg); len(efforts) != 0 {
+		t.Errorf("MatchSegment with a detour: %+v, want none", efforts)
+	}
+}
+
+func TestMatchSegmentShortcut(t *testing.T) {
+	alg := NewKarney("Karney", EllipsoidWGS84)
+	// A segment 500 m north, 140 m east and 500 m south
+	segment := []Point{{Latitude: 50, Longitude: 8}, {Latitude: 50.0045, Longitude: 8}, {Latitude: 50.0045, Longitude: 8.002}, {Latitude: 50, Longitude: 8.002}}
+	loop := append(testLine(50, 8, 50.0045, 8, 9), testLine(50.0045, 8.002, 50, 8.002, 9)...)
+	if efforts := testPathGPX(t, loop...).MatchSegment(segment, DefaultSegmentMatchOptions, alg); len(efforts) != 1 || efforts[0].EndIndex != 19 {
+		t.Errorf("MatchSegment: %+v, want one effort to the point 19", efforts)
+	}
+	// A track which crosses to the way back in the middle skips the top of the segment
+	shortcut := append(testLine(50, 8, 50.002, 8, 4), testLine(50.002, 8.002, 50, 8.002, 4)...)
+	if efforts := testPathGPX(t, shortcut...).MatchSegment(segment, DefaultSegmentMatchOptions, alg); len(efforts) != 0 {
+		t.Errorf("MatchSegment with a shortcut: %+v, want none", efforts)
+	}
+}
@@ -0,0 +1,240 @@
+package geo
+
+import (
+	"math"
+	"time"
+)
+
+// projectPaths projects the points of both paths on the plane of the first point of a
+func projectPaths(a []Point, b []Point) ([][2]float64, [][2]float64) {
+	polyline := NewPolyline(a[:1])
+	project := func(points []Point) [][2]float64 {
+		result := make([][2]float64, len(points))
+		for i := range points {
+			result[i][0], result[i][1] = polyline.Project(points[i].Latitude, points[i].Longitude)
+		}
+		return result
+	}
+	return project(a), project(b)
+}
+
+// planeDistance returns the distance (m) of two projected points
+func planeDistance(p [2]float64, q [2]float64) float64 {
+	return math.Hypot(p[0]-q[0], p[1]-q[1])
+}
+
+// DiscreteFrechetDistance returns the discrete Fréchet distance (m) of the paths: The min of the max distance of two points walking both paths forward; +Inf if a path is empty
+// See: T. Eiter, H. Mannila, Computing Discrete Fréchet Distance (1994)
+func DiscreteFrechetDistance(a []Point, b []Point) float64 {
+	return warp(a, b, func(cost float64, previous float64) float64 { return math.Max(cost, previous) })
+}
+
+// DynamicTimeWarping returns the dynamic time warping distance (m) of the paths: The min sum of the distances of two points walking both paths forward; +Inf if a path is empty
+func DynamicTimeWarping(a []Point, b []Point) float64 {
+	return warp(a, b, func(cost float64, previous float64) float64 { return cost + previous })
+}
+
+// warp returns the min accumulated cost of the coupling of both paths with the dynamic programming of the Fréchet distance / DTW; only two rows are stored
+func warp(a []Point, b []Point, accumulate func(cost float64, previous float64) float64) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return math.Inf(1)
+	}
+	pa, pb := projectPaths(a, b)
+	previousRow := make([]float64, len(pb))
+	row := make([]float64, len(pb))
+	for i := range pa {
+		for j := range pb {
+			cost := planeDistance(pa[i], pb[j])
+			switch {
+			case i == 0 && j == 0:
+				row[j] = cost
+			case i == 0:
+				row[j] = accumulate(cost, row[j-1])
+			case j == 0:
+				row[j] = accumulate(cost, previousRow[j])
+			default:
+				row[j] = accumulate(cost, math.Min(previousRow[j], math.Min(previousRow[j-1], row[j-1])))
+			}
+		}
+		previousRow, row = row, previousRow
+	}
+	return previousRow[len(pb)-1]
+}
+
+// HausdorffDistance returns the Hausdorff distance (m) of the paths: The max distance of a point of one path to the nearest point of the other path; +Inf if a path is empty
+func HausdorffDistance(a []Point, b []Point) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return math.Inf(1)
+	}
+	pa, pb := projectPaths(a, b)
+	directed := func(from [][2]float64, to [][2]float64) float64 {
+		var result float64
+		for i := range from {
+			minDistance := math.Inf(1)
+			for j := range to {
+				if distance := planeDistance(from[i], to[j]); distance < minDistance {
+					minDistance = distance
+					if minDistance <= result {
+						// Cannot increase the max any more
+						break
+					}
+				}
+			}
+			result = math.Max(result, minDistance)
+		}
+		return result
+	}
+	return math.Max(directed(pa, pb), directed(pb, pa))
+}
+
+// SimilarityOptions defines when two activities are duplicates or the same route
+type SimilarityOptions struct {
+	ResampleDistance    float64 // The distance (m) of the resampled points to compare the paths; 0 uses the recorded points
+	MaxFrechetDistance  float64 // The max discrete Fréchet distance (m) of the same route
+	MaxLengthDifference float64 // The max difference of the distances as a ratio of the longer distance (0.2 == 20%) of the same route
+	MinTimeOverlap      float64 // The min overlap of the time as a ratio of the shorter duration (0.8 == 80%) of duplicates
+}
+
+// DefaultSimilarityOptions are the default options to detect duplicates and recurring routes
+var DefaultSimilarityOptions = SimilarityOptions{
+	ResampleDistance:    25,
+	MaxFrechetDistance:  100,
+	MaxLengthDifference: 0.2,
+	MinTimeOverlap:      0.8,
+}
+
+// activityPath contains the resampled path of an activity
+type activityPath struct {
+	points []Point
+	length float64
+	start  time.Time
+	end    time.Time
+	timed  bool
+}
+
+// newActivityPath returns the resampled path of the gpx
+func newActivityPath(gpx *GPX, options SimilarityOptions) *activityPath {
+	polyline := NewPolyline(gpx.Points())
+	path := &activityPath{
+		points: polyline.Resample(options.ResampleDistance),
+		length: polyline.Length(),
+	}
+	overall := gpx.MovementStats.OverallData
+	if overall.StartTime.Valid && overall.EndTime.Valid {
+		path.start, path.end, path.timed = *overall.StartTime.Time, *overall.EndTime.Time, true
+	}
+	return path
+}
+
+// sameRoute returns if both paths have similar length and the Fréchet distance is within the options
+func (path *activityPath) sameRoute(other *activityPath, options SimilarityOptions) bool {
+	if len(path.points) == 0 || len(other.points) == 0 {
+		return false
+	}
+	if longer := math.Max(path.length, other.length); longer > 0 && math.Abs(path.length-other.length)/longer > options.MaxLengthDifference {
+		return false
+	}
+	// The Fréchet distance is at least the distance of the start points and of the end points
+	pa, pb := projectPaths([]Point{path.points[0], path.points[len(path.points)-1]}, []Point{other.points[0], other.points[len(other.points)-1]})
+	if planeDistance(pa[0], pb[0]) > options.MaxFrechetDistance || planeDistance(pa[1], pb[1]) > options.MaxFrechetDistance {
+		return false
+	}
+	return DiscreteFrechetDistance(path.points, other.points) <= options.MaxFrechetDistance
+}
+
+// timeOverlap returns the overlap of the time of both paths as a ratio of the shorter duration
+func (path *activityPath) timeOverlap(other *activityPath) float64 {
+	if !path.timed || !other.timed {
+		return 0
+	}
+	start, end := path.start, path.end
+	if other.start.After(start) {
+		start = other.start
+	}
+	if other.end.Before(end) {
+		end = other.end
+	}
+	shorter := math.Min(path.end.Sub(path.start).Seconds(), other.end.Sub(other.start).Seconds())
+	if shorter <= 0 {
+		if start.Equal(end) {
+			return 1
+		}
+		return 0
+	}
+	return math.Max(end.Sub(start).Seconds(), 0) / shorter
+}
+
+// IsSameRoute returns if both activities follow the same route in the same direction
+func IsSameRoute(a *GPX, b *GPX, options SimilarityOptions) bool {
+	return newActivityPath(a, options).sameRoute(newActivityPath(b, options), options)
+}
+
+// IsDuplicate returns if both activities are the same recording, e.g. uploaded from different apps: The times overlap and the routes are the same
+func IsDuplicate(a *GPX, b *GPX, options SimilarityOptions) bool {
+	pa, pb := newActivityPath(a, options), newActivityPath(b, options)
+	return pa.timeOverlap(pb) >= options.MinTimeOverlap && pa.sameRoute(pb, options)
+}
+
+// FindDuplicates returns the groups of the indices of the activities which are duplicates; activities without a duplicate are not returned
+func FindDuplicates(activities []*GPX, options SimilarityOptions) [][]int {
+	var result [][]int
+	for _, group := range groupActivities(activities, options, true) {
+		if len(group) > 1 {
+			result = append(result, group)
+		}
+	}
+	return result
+}
+
+// GroupRecurringRoutes returns the groups of the indices of the activities which follow the same route; each activity is in exactly one group
+func GroupRecurringRoutes(activities []*GPX, options SimilarityOptions) [][]int {
+	return groupActivities(activities, options, false)
+}
+
+// groupActivities groups the activities which are the same route (and overlap in time if duplicates == true) with a union-find
+func groupActivities(activities []*GPX, options SimilarityOptions, duplicates bool) [][]int {
+	paths := make([]*activityPath, len(activities))
+	for i, activity := range activities {
+		paths[i] = newActivityPath(activity, options)
+	}
+
+	parents := make([]int, len(activities))
+	for i := range parents {
+		parents[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parents[i] != i {
+			parents[i] = find(parents[i])
+		}
+		return parents[i]
+	}
+
+	for i := range paths {
+		for j := i + 1; j < len(paths); j++ {
+			if find(i) == find(j) {
+				continue
+			}
+			if duplicates && paths[i].timeOverlap(paths[j]) < options.MinTimeOverlap {
+				continue
+			}
+			if paths[i].sameRoute(paths[j], options) {
+				parents[find(j)] = find(i)
+			}
+		}
+	}
+
+	var groups [][]int
+	groupIndex := make(map[int]int)
+	for i := range paths {
+		root := find(i)
+		index, ok := groupIndex[root]
+		if !ok {
+			index = len(groups)
+			groupIndex[root] = index
+			groups = append(groups, nil)
+		}
+		groups[index] = append(groups[index], i)
+	}
+	return groups
+}
@@ -0,0 +1,68 @@
+package geo
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testPoints returns the points of the locations
+func testPoints(locations [][2]float64) []Point {
+	points := make([]Point, len(locations))
+	for index, location := range locations {
+		points[index] = Point{Latitude: location[0], Longitude: location[1]}
+	}
+	return points
+}
+
+func TestPathDistances(t *testing.T) {
+	// Two paths of 5 points 0.0005° (≈ 56 m) apart, 0.0002° (≈ 14 m) east of each other
+	a := testPoints(testLine(50, 8, 50.002, 8, 4))
+	b := testPoints(testLine(50, 8.0002, 50.002, 8.0002, 4))
+	offset := EllipsoidSphere.SemiMajorAxisA * 0.0002 * math.Pi / 180 * math.Cos(50*math.Pi/180)
+	if distance := DiscreteFrechetDistance(a, b); math.Abs(distance-offset) > 1e-6 {
+		t.Errorf("DiscreteFrechetDistance: %f, want %f", distance, offset)
+	}
+	if distance := DynamicTimeWarping(a, b); math.Abs(distance-5*offset) > 1e-6 {
+		t.Errorf("DynamicTimeWarping: %f, want %f", distance, 5*offset)
+	}
+	if distance := HausdorffDistance(a, b); math.Abs(distance-offset) > 1e-6 {
+		t.Errorf("HausdorffDistance: %f, want %f", distance, offset)
+	}
+
+	// The reversed path has the same Hausdorff distance; the Fréchet distance is at least the distance of the start points
+	reversed := testPoints(testLine(50.002, 8.0002, 50, 8.0002, 4))
+	if distance := HausdorffDistance(a, reversed); math.Abs(distance-offset) > 1e-6 {
+		t.Errorf("HausdorffDistance reversed: %f, want %f", distance, offset)
+	}
+	if distance := DiscreteFrechetDistance(a, reversed); distance < 222 {
+		t.Errorf("DiscreteFrechetDistance reversed: %f, want at least 222", distance)
+	}
+	if distance := DiscreteFrechetDistance(a, nil); !math.IsInf(distance, 1) {
+		t.Errorf("DiscreteFrechetDistance of an empty path: %f, want +Inf", distance)
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	north := testLine(50, 8, 50.009, 8, 18)
+	activities := []*GPX{
+		testPathGPX(t, north...),
+		testPathGPX(t, testLine(50, 8.0003, 50.009, 8.0003, 36)...), // The same recording of another device
+		testPathGPXAt(t, testStart.Add(24*time.Hour), north...),     // The next day
+		testPathGPX(t, testLine(50.009, 8, 50, 8, 18)...),           // The opposite direction
+		testPathGPX(t, testLine(51, 8, 51.009, 8, 18)...),           // Another place
+	}
+	if !IsSameRoute(activities[0], activities[2], DefaultSimilarityOptions) || IsSameRoute(activities[0], activities[3], DefaultSimilarityOptions) {
+		t.Error("IsSameRoute: want the same route for the next day and not for the opposite direction")
+	}
+	if !IsDuplicate(activities[0], activities[1], DefaultSimilarityOptions) || IsDuplicate(activities[0], activities[2], DefaultSimilarityOptions) {
+		t.Error("IsDuplicate: want a duplicate for the other device and not for the next day")
+	}
+	if groups := FindDuplicates(activities, DefaultSimilarityOptions); !reflect.DeepEqual(groups, [][]int{{0, 1}}) {
+		t.Errorf("FindDuplicates: %v, want [[0 1]]", groups)
+	}
+	if groups := GroupRecurringRoutes(activities, DefaultSimilarityOptions); !reflect.DeepEqual(groups, [][]int{{0, 1, 2}, {3}, {4}}) {
+		t.Errorf("GroupRecurringRoutes: %v, want [[0 1 2] [3] [4]]", groups)
+	}
+}
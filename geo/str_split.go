@@ -0,0 +1,231 @@
+package geo
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/mbecker/gpxs/generic"
+)
+
+// Split distances (m)
+const (
+	SplitKilometre = 1000.0
+	SplitMile      = 1609.344
+)
+
+// Split represents a part of a track like a kilometre split or a lap
+type Split struct {
+	Number        int     // The number of the split starting with 1
+	StartDistance float64 // The distance (m) from the start of the track to the start of the split
+
+	StartTime NullTime // The (interpolated) time at the start of the split
+	EndTime   NullTime // The (interpolated) time at the end of the split
+
+	Distance   float64 // The distance (m) of the split
+	Duration   float64 // The elapsed duration (sec) of the split
+	MovingTime float64 // The duration (sec) of the moving points of the split
+
+	Pace       float64 // The pace (s/m) of the elapsed duration
+	MovingPace float64 // The pace (s/m) of the moving time
+
+	ElevationGain float64 // The sum of the positive elevation changes (m)
+	ElevationLoss float64 // The sum of the negative elevation changes (m) as a positive value
+
+	AverageHeartRate generic.NullableFloat64 // The time weighted average heart rate (bpm); null if the points do not have a heart rate
+}
+
+// splitBuilder sums up the (parts of the) points of a split
+type splitBuilder struct {
+	split             Split
+	heartRateSum      float64
+	heartRateDuration float64
+}
+
+// add adds the fraction (0 <= fraction <= 1) of the leg from the previous point to the point
+func (sb *splitBuilder) add(point *GPXPoint, fraction float64) {
+	duration := point.Duration * fraction
+	sb.split.Distance += point.Distance * fraction
+	sb.split.Duration += duration
+	if point.IsMoving {
+		sb.split.MovingTime += duration
+	}
+
+	elevationChange := point.ElevationChange * fraction
+	if elevationChange > 0 {
+		sb.split.ElevationGain += elevationChange
+	} else {
+		sb.split.ElevationLoss -= elevationChange
+	}
+
+	if point.HeartRate.NotNull() && duration > 0 {
+		sb.heartRateSum += float64(point.HeartRate.Value()) * duration
+		sb.heartRateDuration += duration
+	}
+}
+
+// isEmpty returns if nothing was added to the split
+func (sb *splitBuilder) isEmpty() bool {
+	return sb.split.Distance == 0 && sb.split.Duration == 0
+}
+
+// finish sets the end time, pace and average heart rate and returns the split
+func (sb *splitBuilder) finish(endTime NullTime, alg Algorithm) Split {
+	sb.split.EndTime = endTime
+	if pace, err := alg.Pace(sb.split.Distance, sb.split.Duration); err == nil {
+		sb.split.Pace = pace
+	}
+	if movingPace, err := alg.Pace(sb.split.Distance, sb.split.MovingTime); err == nil {
+		sb.split.MovingPace = movingPace
+	}
+	if sb.heartRateDuration > 0 {
+		sb.split.AverageHeartRate.SetValue(sb.heartRateSum / sb.heartRateDuration)
+	}
+	return sb.split
+}
+
+// interpolateTime returns the time at the fraction of the leg from the previous point to the point
+func interpolateTime(previousPoint *GPXPoint, point *GPXPoint, fraction float64) NullTime {
+	var result NullTime
+	if previousPoint.Timestamp.Valid && point.Timestamp.Valid {
+		leg := point.Timestamp.Time.Sub(*previousPoint.Timestamp.Time)
+		t := previousPoint.Timestamp.Time.Add(time.Duration(math.Round(float64(leg) * fraction)))
+		result.SetTime(&t)
+	} else if fraction == 1 && point.Timestamp.Valid {
+		result.SetTime(point.Timestamp.Time)
+	}
+	return result
+}
+
+// crossingFunc returns the fraction (0 <= fraction <= 1) of the leg from the previous point to the point at which the next split starts or false if the leg does not cross the next split.
+// startDistance is the distance (m) from the start of the track to the previous point.
+type crossingFunc func(previousPoint *GPXPoint, point *GPXPoint, startDistance float64) (float64, bool)
+
+// split walks all points of the track and splits the legs at the fractions returned by crossing
+func (track *GPXTrack) split(crossing crossingFunc, alg Algorithm) []Split {
+	var splits []Split
+	var sb splitBuilder
+	var distance float64
+	var lastPoint *GPXPoint
+
+	for segmentNo := range track.Segments {
+		points := track.Segments[segmentNo].Points
+		for index := range points {
+			point := &points[index]
+			if index == 0 {
+				// A new segment continues the split; the gap between the segments is not part of any split
+				if lastPoint == nil {
+					sb.split.StartTime = point.Timestamp
+				}
+				lastPoint = point
+				continue
+			}
+			previousPoint := &points[index-1]
+
+			from := 0.0
+			for {
+				fraction, ok := crossing(previousPoint, point, distance)
+				if !ok {
+					break
+				}
+				fraction = math.Max(from, math.Min(fraction, 1))
+				sb.add(point, fraction-from)
+				from = fraction
+
+				splitTime := interpolateTime(previousPoint, point, fraction)
+				if !sb.isEmpty() {
+					sb.split.Number = len(splits) + 1
+					splits = append(splits, sb.finish(splitTime, alg))
+				}
+				sb = splitBuilder{}
+				sb.split.StartDistance = distance + point.Distance*fraction
+				sb.split.StartTime = splitTime
+			}
+			sb.add(point, 1-from)
+			distance += point.Distance
+			lastPoint = point
+		}
+	}
+
+	if !sb.isEmpty() {
+		sb.split.Number = len(splits) + 1
+		splits = append(splits, sb.finish(lastPoint.Timestamp, alg))
+	}
+	return splits
+}
+
+// Splits returns the splits of the track every distance (m), e.g. SplitKilometre or SplitMile; the last split contains the remaining distance.
+// The start and end times of the splits are interpolated between the points. The point data must be set (see Point.SetPointData).
+func (track *GPXTrack) Splits(distance float64, alg Algorithm) []Split {
+	if distance <= 0 {
+		return nil
+	}
+	next := distance
+	return track.split(func(previousPoint *GPXPoint, point *GPXPoint, startDistance float64) (float64, bool) {
+		if point.Distance <= 0 || startDistance+point.Distance < next {
+			return 0, false
+		}
+		fraction := (next - startDistance) / point.Distance
+		next += distance
+		return fraction, true
+	}, alg)
+}
+
+// Laps returns the laps of the track started at the markers, e.g. the lap start times of a TCX or FIT file.
+// Markers before the first point or after the last point of the track are ignored.
+func (track *GPXTrack) Laps(markers []time.Time, alg Algorithm) []Split {
+	sorted := make([]time.Time, len(markers))
+	copy(sorted, markers)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Before(sorted[j]) })
+
+	next := 0
+	return track.split(func(previousPoint *GPXPoint, point *GPXPoint, startDistance float64) (float64, bool) {
+		if next >= len(sorted) || !previousPoint.Timestamp.Valid || !point.Timestamp.Valid {
+			return 0, false
+		}
+		marker := sorted[next]
+		if marker.After(*point.Timestamp.Time) {
+			return 0, false
+		}
+		next++
+		// A marker before the leg (e.g. between two segments) starts the lap at the beginning of the leg
+		if !marker.After(*previousPoint.Timestamp.Time) {
+			return 0, true
+		}
+		return float64(marker.Sub(*previousPoint.Timestamp.Time)) / float64(point.Timestamp.Time.Sub(*previousPoint.Timestamp.Time)), true
+	}, alg)
+}
+
+// LapsFromWaypoints returns the laps of the track started at the waypoints. The time of a waypoint is used as the lap marker;
+// if the waypoint does not have a time the time of the nearest point of the track is used.
+func (track *GPXTrack) LapsFromWaypoints(waypoints []GPXPoint, alg Algorithm) []Split {
+	var markers []time.Time
+	for index := range waypoints {
+		waypoint := &waypoints[index]
+		if waypoint.Timestamp.Valid {
+			markers = append(markers, *waypoint.Timestamp.Time)
+			continue
+		}
+		if nearest := track.nearestPoint(&waypoint.Point, alg); nearest != nil && nearest.Timestamp.Valid {
+			markers = append(markers, *nearest.Timestamp.Time)
+		}
+	}
+	return track.Laps(markers, alg)
+}
+
+// nearestPoint returns the point of the track with the smallest distance to the location or nil if the track has no points
+func (track *GPXTrack) nearestPoint(location *Point, alg Algorithm) *GPXPoint {
+	var nearest *GPXPoint
+	minDistance := math.Inf(1)
+	for segmentNo := range track.Segments {
+		points := track.Segments[segmentNo].Points
+		for index := range points {
+			distance, err := alg.Distance(&points[index].Point, location)
+			if err == nil && distance < minDistance {
+				minDistance = distance
+				nearest = &points[index]
+			}
+		}
+	}
+	return nearest
+}
@@ -0,0 +1,121 @@
+package geo
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/mbecker/gpxs/generic"
+)
+
+// testSplitTrack returns a track of 6 moving points 400 m and 100 sec apart (2000 m in 500 sec); the heart rate is 120 bpm for the first two legs and 150 bpm after,
+// the elevation rises 20 m on the third leg
+func testSplitTrack() *GPXTrack {
+	points := make([]GPXPoint, 6)
+	for index := range points {
+		point := &points[index]
+		t := testStart.Add(time.Duration(index) * 100 * time.Second)
+		point.Timestamp.SetTime(&t)
+		point.Latitude = 50 + float64(index)*0.0036
+		point.Longitude = 8
+		point.IsMoving = true
+		if index > 0 {
+			point.Distance = 400
+			point.Duration = 100
+			point.HeartRate = *generic.NewNullableInt(120)
+		}
+		if index > 2 {
+			point.HeartRate = *generic.NewNullableInt(150)
+		}
+	}
+	points[3].ElevationChange = 20
+	return &GPXTrack{Segments: []GPXTrackSegment{{Points: points}}}
+}
+
+func TestSplits(t *testing.T) {
+	alg := NewKarney("Karney", EllipsoidWGS84)
+	splits := testSplitTrack().Splits(SplitKilometre, alg)
+	if len(splits) != 2 {
+		t.Fatalf("Splits: %d, want 2", len(splits))
+	}
+	want := []struct {
+		start, end     time.Duration
+		startDistance  float64
+		averageHR      float64
+		elevationGain  float64
+		distance, pace float64
+	}{
+		{0, 250 * time.Second, 0, (200*120 + 50*150) / 250.0, 10, 1000, 0.25},
+		{250 * time.Second, 500 * time.Second, 1000, 150, 10, 1000, 0.25},
+	}
+	for index, split := range splits {
+		w := want[index]
+		if split.Number != index+1 || split.StartDistance != w.startDistance {
+			t.Errorf("Split %d: Number %d / start distance %f, want %d / %f", index, split.Number, split.StartDistance, index+1, w.startDistance)
+		}
+		// The crossing times are interpolated between the points
+		if !split.StartTime.Time.Equal(testStart.Add(w.start)) || !split.EndTime.Time.Equal(testStart.Add(w.end)) {
+			t.Errorf("Split %d: %v - %v, want %v - %v", index, split.StartTime.Time, split.EndTime.Time, testStart.Add(w.start), testStart.Add(w.end))
+		}
+		if math.Abs(split.Distance-w.distance) > 1e-9 || math.Abs(split.Duration-250) > 1e-9 || math.Abs(split.MovingTime-250) > 1e-9 {
+			t.Errorf("Split %d: Distance %f / duration %f / moving time %f, want %f / 250 / 250", index, split.Distance, split.Duration, split.MovingTime, w.distance)
+		}
+		if math.Abs(split.Pace-w.pace) > 1e-9 || math.Abs(split.MovingPace-w.pace) > 1e-9 {
+			t.Errorf("Split %d: Pace %f / moving pace %f, want %f", index, split.Pace, split.MovingPace, w.pace)
+		}
+		if math.Abs(split.ElevationGain-w.elevationGain) > 1e-9 || split.ElevationLoss != 0 {
+			t.Errorf("Split %d: Elevation gain / loss %f / %f, want %f / 0", index, split.ElevationGain, split.ElevationLoss, w.elevationGain)
+		}
+		if split.AverageHeartRate.Null() || math.Abs(split.AverageHeartRate.Value()-w.averageHR) > 1e-9 {
+			t.Errorf("Split %d: Average heart rate %v, want %f", index, split.AverageHeartRate.Value(), w.averageHR)
+		}
+	}
+
+	// The last split contains the remaining distance
+	splits = testSplitTrack().Splits(SplitMile, alg)
+	if len(splits) != 2 || math.Abs(splits[1].Distance-(2000-SplitMile)) > 1e-9 {
+		t.Errorf("Mile splits: %v, want 2 splits with %f m in the last split", splits, 2000-SplitMile)
+	}
+	if splits := testSplitTrack().Splits(0, alg); splits != nil {
+		t.Errorf("Splits(0): %v, want nil", splits)
+	}
+}
+
+func TestLaps(t *testing.T) {
+	alg := NewKarney("Karney", EllipsoidWGS84)
+	// The markers are sorted; a marker before the start of the track is ignored
+	markers := []time.Time{testStart.Add(400 * time.Second), testStart.Add(-time.Hour), testStart.Add(150 * time.Second)}
+	laps := testSplitTrack().Laps(markers, alg)
+	distances := []float64{600, 1000, 400}
+	if len(laps) != len(distances) {
+		t.Fatalf("Laps: %d, want %d", len(laps), len(distances))
+	}
+	for index, lap := range laps {
+		if math.Abs(lap.Distance-distances[index]) > 1e-9 {
+			t.Errorf("Lap %d: Distance %f, want %f", index, lap.Distance, distances[index])
+		}
+	}
+	if !laps[1].StartTime.Time.Equal(testStart.Add(150*time.Second)) || !laps[2].EndTime.Time.Equal(testStart.Add(500*time.Second)) {
+		t.Errorf("Lap times: %v / %v", laps[1].StartTime.Time, laps[2].EndTime.Time)
+	}
+}
+
+func TestLapsFromWaypoints(t *testing.T) {
+	alg := NewKarney("Karney", EllipsoidWGS84)
+	track := testSplitTrack()
+	// A waypoint with a time and a waypoint without a time at the fourth point (300 sec)
+	var timed, located GPXPoint
+	at := testStart.Add(100 * time.Second)
+	timed.Timestamp.SetTime(&at)
+	located.Latitude, located.Longitude = track.Segments[0].Points[3].Latitude+0.0001, 8
+	laps := track.LapsFromWaypoints([]GPXPoint{timed, located}, alg)
+	distances := []float64{400, 800, 800}
+	if len(laps) != len(distances) {
+		t.Fatalf("Laps: %d, want %d", len(laps), len(distances))
+	}
+	for index, lap := range laps {
+		if math.Abs(lap.Distance-distances[index]) > 1e-9 {
+			t.Errorf("Lap %d: Distance %f, want %f", index, lap.Distance, distances[index])
+		}
+	}
+}
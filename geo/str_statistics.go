@@ -0,0 +1,135 @@
+package geo
+
+import (
+	"math"
+	"strings"
+)
+
+// Recalculate sets all statistics of the gpx with the algorithm from the points, e.g. after the gpx was parsed, built, edited or to compare algorithms without parsing the file again:
+// The activity type of tracks without a type (by the track name, see Algorithm.CheckActivityType), the point data, the grade data, the moving / stopped state of the points
+// and the movement stats of the segments, tracks and gpx with the local start time of the DefaultTimeZoneResolver (see GPX.SetLocalTime).
+// Values of other analyses like GPX.SetEnergy or PowerEstimator.Estimate must be set again afterwards.
+func Recalculate(gpx *GPX, algorithm Algorithm) {
+	for trackNo := range gpx.Tracks {
+		track := &gpx.Tracks[trackNo]
+		if len(track.Type) == 0 {
+			// Strava defines the activity type with a number ("1" == Cycling, "4" = Hiking, "9" == Running); other parties like Garmin / Runkeeper have the activity type as a descriptive text in the track name
+			if activityType, err := algorithm.CheckActivityType(strings.ToLower(track.Name)); err == nil {
+				track.Type = activityType
+			}
+		}
+		if len(gpx.Type) == 0 {
+			gpx.Type = track.Type
+		}
+	}
+	gpx.SetStatistics(algorithm)
+	if DefaultTimeZoneResolver != nil {
+		// The local start time stays unset if the time zone is unknown, e.g. it is not in the time zone database
+		gpx.SetLocalTime(DefaultTimeZoneResolver)
+	}
+}
+
+// SetStatistics (GPXTrackSegment) sets the point data (distance, duration, speed, pace, bearing), the grade data and the moving / stopped state of each point
+// and the movement stats of the segment with the algorithm
+func (seg *GPXTrackSegment) SetStatistics(algorithm Algorithm) {
+	seg.MovementStats = MovementStats{}
+	if len(seg.Points) == 0 {
+		return
+	}
+
+	// The first point does not have a previous point
+	first := &seg.Points[0]
+	first.Distance, first.Duration, first.Speed, first.Pace = 0, 0, 0, 0
+	first.TurnAngle, first.HeadingChange = 0, 0
+	first.Bearing.SetNull()
+	first.IsMoving = true
+	if first.Timestamp.Valid {
+		seg.MovementStats.OverallData.StartTime.SetTime(first.Timestamp.Time)
+		seg.MovementStats.MovingData.StartTime.SetTime(first.Timestamp.Time)
+	}
+
+	var sumSpeed float64
+	for index := 1; index < len(seg.Points); index++ {
+		seg.Points[index].Point.SetPointData(&seg.Points[index-1].Point, algorithm)
+		sumSpeed += seg.Points[index].Speed
+	}
+
+	// Set the grade data of the points: Elevation change, grade, vertical speed (smoothed over the distance algorithm.GradeWindow())
+	seg.SetGradeData(algorithm.GradeWindow())
+
+	// Moving points: Either by the standard deviation of the speed or by the algorithm's custom moving points
+	var isMoving func(index int) bool
+	if algorithm.ShouldStandardDeviation() {
+		// The mean μ, the variance and the standard deviation of the speed of all points
+		μ := sumSpeed / float64(len(seg.Points))
+		var squaredDeviationSum float64
+		for index := 1; index < len(seg.Points); index++ {
+			squaredDeviationSum += math.Pow(seg.Points[index].Speed-μ, 2)
+		}
+		standardDeviation := math.Sqrt(squaredDeviationSum / float64(len(seg.Points)))
+
+		// The points with a speed above x1 are moving; if the standard deviation is 0 (mostly too few points) all points with a speed are moving
+		x1, x2 := 0.0, 0.0
+		if standardDeviation > 0 {
+			x1 = μ - algorithm.Sigma()*standardDeviation
+			x2 = μ + algorithm.Sigma()*standardDeviation
+		}
+		seg.MovementStats.SD = SDData{Valid: true, X1: x1, X2: x2}
+		isMoving = func(index int) bool {
+			return seg.Points[index].Speed > 0 && x1 <= seg.Points[index].Speed
+		}
+	} else {
+		isMoving = func(index int) bool {
+			return algorithm.CustomMovingPoints(&seg.Points[index], &seg.Points[index-1], algorithm) == nil
+		}
+	}
+
+	for index := 1; index < len(seg.Points); index++ {
+		gpxPoint := &seg.Points[index]
+		previousPoint := &seg.Points[index-1]
+		gpxPoint.IsMoving = isMoving(index)
+		if gpxPoint.IsMoving {
+			seg.MovementStats.MovingData.SetValues(gpxPoint, previousPoint, index, algorithm)
+		} else {
+			seg.MovementStats.StoppedData.SetValues(gpxPoint, previousPoint, index, algorithm)
+		}
+		seg.MovementStats.OverallData.SetValues(gpxPoint, previousPoint, index, algorithm)
+	}
+}
+
+// SetStatistics (GPXTrack) sets the statistics of all segments (see GPXTrackSegment.SetStatistics) and the movement stats of the track
+func (track *GPXTrack) SetStatistics(algorithm Algorithm) {
+	track.MovementStats = MovementStats{}
+	for segmentNo := range track.Segments {
+		seg := &track.Segments[segmentNo]
+		seg.SetStatistics(algorithm)
+		track.MovementStats.addMovementStats(&seg.MovementStats, segmentNo, algorithm)
+	}
+}
+
+// SetStatistics (GPX) sets the statistics of all tracks (see GPXTrack.SetStatistics), the movement stats and the points count of the gpx
+func (gpx *GPX) SetStatistics(algorithm Algorithm) {
+	gpx.MovementStats = MovementStats{}
+	gpx.PointsCount = 0
+	for trackNo := range gpx.Tracks {
+		track := &gpx.Tracks[trackNo]
+		track.SetStatistics(algorithm)
+		gpx.MovementStats.addMovementStats(&track.MovementStats, trackNo, algorithm)
+		for segmentNo := range track.Segments {
+			gpx.PointsCount += len(track.Segments[segmentNo].Points)
+		}
+	}
+}
+
+// addMovementStats adds the overall, moving and stopped data of the movement stats; the start time is the first valid start time
+func (ms *MovementStats) addMovementStats(movementStats *MovementStats, count int, algorithm Algorithm) {
+	if !ms.OverallData.StartTime.Valid {
+		ms.OverallData.StartTime = movementStats.OverallData.StartTime
+	}
+	if !ms.MovingData.StartTime.Valid {
+		ms.MovingData.StartTime = movementStats.MovingData.StartTime
+	}
+	ms.OverallData.SetValuesFromMovementData(&movementStats.OverallData, count, algorithm)
+	ms.MovingData.SetValuesFromMovementData(&movementStats.MovingData, count, algorithm)
+	ms.StoppedData.SetValuesFromMovementData(&movementStats.StoppedData, count, algorithm)
+}
@@ -0,0 +1,56 @@
+package geo
+
+import "time"
+
+// shift adds the duration to the time; a new time is set because the time may be shared with other NullTimes
+func (nt *NullTime) shift(d time.Duration) {
+	if !nt.Valid {
+		return
+	}
+	t := nt.Time.Add(d)
+	nt.Time = &t
+}
+
+// shiftTime adds the duration to the start, end and local start time of the overall, moving and stopped data
+func (ms *MovementStats) shiftTime(d time.Duration) {
+	for _, md := range []*MovementData{&ms.OverallData, &ms.MovingData, &ms.StoppedData} {
+		md.StartTime.shift(d)
+		md.EndTime.shift(d)
+		md.LocalStartTime.shift(d)
+	}
+}
+
+// shiftTimestamp returns the timestamp plus the duration; nil and zero timestamps (no time) are returned unchanged
+func shiftTimestamp(t *time.Time, d time.Duration) *time.Time {
+	if t == nil || t.IsZero() {
+		return t
+	}
+	shifted := t.Add(d)
+	return &shifted
+}
+
+// ShiftTime adds the duration to all times of the gpx (points, tracks, routes, waypoints and movement stats), e.g. for a device with a wrong clock; the durations do not change
+func (gpx *GPX) ShiftTime(d time.Duration) {
+	gpx.Timestamp = shiftTimestamp(gpx.Timestamp, d)
+	gpx.MovementStats.shiftTime(d)
+	for trackNo := range gpx.Tracks {
+		track := &gpx.Tracks[trackNo]
+		track.Timestamp = shiftTimestamp(track.Timestamp, d)
+		track.MovementStats.shiftTime(d)
+		for segmentNo := range track.Segments {
+			seg := &track.Segments[segmentNo]
+			seg.MovementStats.shiftTime(d)
+			for index := range seg.Points {
+				seg.Points[index].Timestamp.shift(d)
+			}
+		}
+	}
+	for routeNo := range gpx.Routes {
+		for index := range gpx.Routes[routeNo].Points {
+			gpx.Routes[routeNo].Points[index].Timestamp.shift(d)
+		}
+	}
+	for index := range gpx.Waypoints {
+		gpx.Waypoints[index].Timestamp.shift(d)
+	}
+}
@@ -0,0 +1,28 @@
+package geo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestShiftTime(t *testing.T) {
+	gpx, err := NewGPX().Point(50, 8, 100, testStart).Point(50.001, 8, 100, testStart.Add(time.Minute)).
+		Waypoint("Start", 50, 8, 100, testStart).Build(NewKarney("Karney", EllipsoidWGS84))
+	if err != nil {
+		t.Fatal(err)
+	}
+	shared := gpx.Tracks[0].Segments[0].Points[0].Timestamp.Time
+	gpx.ShiftTime(time.Hour)
+	want := testStart.Add(time.Hour)
+	points := gpx.Tracks[0].Segments[0].Points
+	if !points[0].Timestamp.Time.Equal(want) || !gpx.Waypoints[0].Timestamp.Time.Equal(want) || !gpx.Timestamp.Equal(want) {
+		t.Errorf("ShiftTime: %v / %v / %v, want %v", points[0].Timestamp.Time, gpx.Waypoints[0].Timestamp.Time, gpx.Timestamp, want)
+	}
+	// Each time is shifted once although the start times share the time of the point
+	if start := gpx.MovementStats.OverallData.StartTime.Time; !start.Equal(want) || !shared.Equal(testStart) {
+		t.Errorf("ShiftTime: Start time %v, want %v", start, want)
+	}
+	if points[1].Duration != 60 || gpx.MovementStats.OverallData.Duration != 60 {
+		t.Errorf("ShiftTime: Duration %f, want 60", gpx.MovementStats.OverallData.Duration)
+	}
+}
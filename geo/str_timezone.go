@@ -0,0 +1,280 @@
+package geo
+
+import (
+	"bytes"
+	_ "embed" // The simplified time zone boundaries (timezones.geojson)
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"time"
+	_ "time/tzdata" // The time zone database that the time zones can be loaded on every system (see time.LoadLocation)
+)
+
+//go:embed timezones.geojson
+var timeZonesGeoJSON []byte
+
+var (
+	defaultTimeZoneBoundaries     *TimeZoneBoundaries
+	defaultTimeZoneBoundariesOnce sync.Once
+)
+
+// DefaultTimeZoneResolver resolves the local start time of the movement stats after the statistics are recalculated (see Recalculate).
+// It is the embedded time zone boundaries of DefaultTimeZoneBoundaries by default; set it to nil that the local start time is not set or to more exact boundaries of LoadTimeZoneBoundaries.
+var DefaultTimeZoneResolver TimeZoneResolver = embeddedTimeZoneResolver{}
+
+// embeddedTimeZoneResolver resolves the time zone by DefaultTimeZoneBoundaries; the boundaries are loaded when the first time zone is resolved
+type embeddedTimeZoneResolver struct{}
+
+// TimeZone (embeddedTimeZoneResolver) returns the time zone of the latitude / longitude in the embedded time zone boundaries
+func (embeddedTimeZoneResolver) TimeZone(latitude float64, longitude float64) (*time.Location, error) {
+	return DefaultTimeZoneBoundaries().TimeZone(latitude, longitude)
+}
+
+// DefaultTimeZoneBoundaries returns the embedded time zone boundaries: Simplified outlines of the IANA time zones in the format of the timezone-boundary-builder project
+// (a few dozen vertices per country, state or region); locations close to a border may resolve the neighbouring time zone. Load the exact boundaries with LoadTimeZoneBoundaries if needed.
+func DefaultTimeZoneBoundaries() *TimeZoneBoundaries {
+	defaultTimeZoneBoundariesOnce.Do(func() {
+		tzb, err := LoadTimeZoneBoundaries(bytes.NewReader(timeZonesGeoJSON))
+		if err != nil {
+			panic(fmt.Sprintf("Invalid embedded time zone boundaries: %v", err))
+		}
+		defaultTimeZoneBoundaries = tzb
+	})
+	return defaultTimeZoneBoundaries
+}
+
+// NauticalTimeZone returns the fixed time zone of the longitude (15 degree per hour), e.g. at sea or outside of known boundaries
+func NauticalTimeZone(longitude float64) *time.Location {
+	hours := int(math.Round(math.Remainder(longitude, 360) / 15))
+	return time.FixedZone(fmt.Sprintf("UTC%+d", hours), hours*3600)
+}
+
+// timeZonePolygon contains the exterior ring of a polygon of a time zone; the ring is tested in degrees of latitude / longitude like the boundaries are defined
+// (e.g. GeoJSON) that no projection is needed for each location and rings which span more than 180 degree of longitude are supported
+type timeZonePolygon struct {
+	latitudes  []float64
+	longitudes []float64
+
+	minLatitude  float64
+	maxLatitude  float64
+	minLongitude float64
+	maxLongitude float64
+}
+
+// newTimeZonePolygon returns the polygon of the vertices
+func newTimeZonePolygon(points []Point) *timeZonePolygon {
+	polygon := &timeZonePolygon{
+		latitudes:    make([]float64, len(points)),
+		longitudes:   make([]float64, len(points)),
+		minLatitude:  math.Inf(1),
+		maxLatitude:  math.Inf(-1),
+		minLongitude: math.Inf(1),
+		maxLongitude: math.Inf(-1),
+	}
+	for i, point := range points {
+		polygon.latitudes[i] = point.Latitude
+		polygon.longitudes[i] = point.Longitude
+		polygon.minLatitude = math.Min(polygon.minLatitude, point.Latitude)
+		polygon.maxLatitude = math.Max(polygon.maxLatitude, point.Latitude)
+		polygon.minLongitude = math.Min(polygon.minLongitude, point.Longitude)
+		polygon.maxLongitude = math.Max(polygon.maxLongitude, point.Longitude)
+	}
+	return polygon
+}
+
+// contains returns if the latitude / longitude is inside the polygon (ray casting)
+func (tzp *timeZonePolygon) contains(latitude float64, longitude float64) bool {
+	if latitude < tzp.minLatitude || latitude > tzp.maxLatitude || longitude < tzp.minLongitude || longitude > tzp.maxLongitude {
+		return false
+	}
+	inside := false
+	for i, j := 0, len(tzp.latitudes)-1; i < len(tzp.latitudes); j, i = i, i+1 {
+		if (tzp.latitudes[i] > latitude) != (tzp.latitudes[j] > latitude) &&
+			longitude < (tzp.longitudes[j]-tzp.longitudes[i])*(latitude-tzp.latitudes[i])/(tzp.latitudes[j]-tzp.latitudes[i])+tzp.longitudes[i] {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// timeZoneBoundary contains the polygons of a time zone
+type timeZoneBoundary struct {
+	name     string // The IANA name of the time zone, e.g. "Europe/Berlin"
+	polygons []*timeZonePolygon
+
+	minLatitude  float64
+	maxLatitude  float64
+	minLongitude float64
+	maxLongitude float64
+}
+
+// area returns the area (degree²) of the bounding box; the smallest boundary containing a location is used that enclaves win over the surrounding time zone
+func (tzb *timeZoneBoundary) area() float64 {
+	return (tzb.maxLatitude - tzb.minLatitude) * (tzb.maxLongitude - tzb.minLongitude)
+}
+
+// contains returns if the latitude / longitude (-180..180) is inside a polygon of the time zone
+func (tzb *timeZoneBoundary) contains(latitude float64, longitude float64) bool {
+	if latitude < tzb.minLatitude || latitude > tzb.maxLatitude || longitude < tzb.minLongitude || longitude > tzb.maxLongitude {
+		return false
+	}
+	for _, polygon := range tzb.polygons {
+		if polygon.contains(latitude, longitude) {
+			return true
+		}
+	}
+	return false
+}
+
+// TimeZoneBoundaries resolves the time zone of a location by the boundaries (polygons) of the time zones and implements the TimeZoneResolver interface.
+// A location outside of all boundaries has the nautical time zone (see NauticalTimeZone). The embedded boundaries are DefaultTimeZoneBoundaries; load others with LoadTimeZoneBoundaries.
+type TimeZoneBoundaries struct {
+	boundaries []*timeZoneBoundary
+}
+
+// Add adds the polygon to the boundary of the time zone with the IANA name, e.g. "Europe/Berlin"; the longitudes of the polygon are -180..180 degree
+func (tzb *TimeZoneBoundaries) Add(name string, polygon []Point) {
+	if len(polygon) < 3 {
+		return
+	}
+	var boundary *timeZoneBoundary
+	for _, b := range tzb.boundaries {
+		if b.name == name {
+			boundary = b
+		}
+	}
+	if boundary == nil {
+		boundary = &timeZoneBoundary{
+			name:         name,
+			minLatitude:  math.Inf(1),
+			maxLatitude:  math.Inf(-1),
+			minLongitude: math.Inf(1),
+			maxLongitude: math.Inf(-1),
+		}
+		tzb.boundaries = append(tzb.boundaries, boundary)
+	}
+	tzp := newTimeZonePolygon(polygon)
+	boundary.polygons = append(boundary.polygons, tzp)
+	boundary.minLatitude = math.Min(boundary.minLatitude, tzp.minLatitude)
+	boundary.maxLatitude = math.Max(boundary.maxLatitude, tzp.maxLatitude)
+	boundary.minLongitude = math.Min(boundary.minLongitude, tzp.minLongitude)
+	boundary.maxLongitude = math.Max(boundary.maxLongitude, tzp.maxLongitude)
+}
+
+// TimeZone returns the time zone of the smallest boundary which contains the latitude / longitude or the nautical time zone;
+// an error is returned if the time zone is not in the time zone database (see time.LoadLocation)
+func (tzb *TimeZoneBoundaries) TimeZone(latitude float64, longitude float64) (*time.Location, error) {
+	longitude = math.Remainder(longitude, 360)
+	var found *timeZoneBoundary
+	for _, boundary := range tzb.boundaries {
+		if (found == nil || boundary.area() < found.area()) && boundary.contains(latitude, longitude) {
+			found = boundary
+		}
+	}
+	if found == nil {
+		return NauticalTimeZone(longitude), nil
+	}
+	return time.LoadLocation(found.name)
+}
+
+// LoadTimeZoneBoundaries reads the time zone boundaries from a GeoJSON feature collection, e.g. of the timezone-boundary-builder project:
+// The IANA name of the time zone is the property "tzid" of each feature with a Polygon or MultiPolygon geometry; holes of the polygons are ignored.
+func LoadTimeZoneBoundaries(r io.Reader) (*TimeZoneBoundaries, error) {
+	var collection struct {
+		Features []struct {
+			Properties struct {
+				TzID string `json:"tzid"`
+			} `json:"properties"`
+			Geometry struct {
+				Type        string          `json:"type"`
+				Coordinates json.RawMessage `json:"coordinates"`
+			} `json:"geometry"`
+		} `json:"features"`
+	}
+	if err := json.NewDecoder(r).Decode(&collection); err != nil {
+		return nil, err
+	}
+
+	tzb := &TimeZoneBoundaries{}
+	for _, feature := range collection.Features {
+		if len(feature.Properties.TzID) == 0 {
+			return nil, errors.New("Feature without the property tzid")
+		}
+		var polygons [][][][]float64
+		switch feature.Geometry.Type {
+		case "Polygon":
+			var polygon [][][]float64
+			if err := json.Unmarshal(feature.Geometry.Coordinates, &polygon); err != nil {
+				return nil, err
+			}
+			polygons = append(polygons, polygon)
+		case "MultiPolygon":
+			if err := json.Unmarshal(feature.Geometry.Coordinates, &polygons); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("Unsupported geometry %s of the time zone %s", feature.Geometry.Type, feature.Properties.TzID)
+		}
+		for _, polygon := range polygons {
+			if len(polygon) == 0 {
+				continue
+			}
+			// The first ring is the exterior ring of [longitude, latitude] positions
+			points := make([]Point, 0, len(polygon[0]))
+			for _, position := range polygon[0] {
+				if len(position) < 2 {
+					return nil, fmt.Errorf("Invalid position of the time zone %s", feature.Properties.TzID)
+				}
+				points = append(points, Point{Latitude: position[1], Longitude: position[0]})
+			}
+			tzb.Add(feature.Properties.TzID, points)
+		}
+	}
+	return tzb, nil
+}
+
+// setLocalStartTime sets the local start time of the overall, moving and stopped data in the time zone
+func (ms *MovementStats) setLocalStartTime(location *time.Location) {
+	for _, md := range []*MovementData{&ms.OverallData, &ms.MovingData, &ms.StoppedData} {
+		md.LocalStartTime = NullTime{}
+		if md.StartTime.Valid {
+			localStartTime := md.StartTime.Time.In(location)
+			md.LocalStartTime.SetTime(&localStartTime)
+		}
+	}
+}
+
+// SetLocalTime sets the local start time of the movement stats of the gpx, tracks and segments in the time zone of the first point of each track; the gpx has the time zone of the first track.
+// The resolver is e.g. DefaultTimeZoneBoundaries or the time zone boundaries of LoadTimeZoneBoundaries. Recalculate sets the local start time with the DefaultTimeZoneResolver;
+// the local start time of another resolver must be set again after the statistics are recalculated.
+func (gpx *GPX) SetLocalTime(resolver TimeZoneResolver) error {
+	if resolver == nil {
+		return errors.New("No time zone resolver")
+	}
+	var gpxLocation *time.Location
+	for trackNo := range gpx.Tracks {
+		track := &gpx.Tracks[trackNo]
+		points := track.Points()
+		if len(points) == 0 {
+			continue
+		}
+		location, err := resolver.TimeZone(points[0].Latitude, points[0].Longitude)
+		if err != nil {
+			return err
+		}
+		if gpxLocation == nil {
+			gpxLocation = location
+		}
+		track.MovementStats.setLocalStartTime(location)
+		for segmentNo := range track.Segments {
+			track.Segments[segmentNo].MovementStats.setLocalStartTime(location)
+		}
+	}
+	if gpxLocation != nil {
+		gpx.MovementStats.setLocalStartTime(gpxLocation)
+	}
+	return nil
+}
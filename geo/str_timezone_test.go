@@ -0,0 +1,204 @@
+package geo
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// testTimeZonesGeoJSON contains a box of Europe/Berlin with the enclave Europe/Busingen and a MultiPolygon of Europe/London
+const testTimeZonesGeoJSON = `{
+ "type": "FeatureCollection",
+ "features": [
+  {"type": "Feature", "properties": {"tzid": "Europe/Berlin"}, "geometry": {"type": "Polygon", "coordinates": [[[6, 47], [15, 47], [15, 55], [6, 55], [6, 47]]]}},
+  {"type": "Feature", "properties": {"tzid": "Europe/Busingen"}, "geometry": {"type": "Polygon", "coordinates": [[[8.6, 47.6], [8.8, 47.6], [8.8, 47.8], [8.6, 47.8], [8.6, 47.6]]]}},
+  {"type": "Feature", "properties": {"tzid": "Europe/London"}, "geometry": {"type": "MultiPolygon", "coordinates": [
+   [[[-6, 50], [2, 50], [2, 56], [-6, 56], [-6, 50]]],
+   [[[-7, 57], [-1, 57], [-1, 61], [-7, 61], [-7, 57]]]
+  ]}}
+ ]
+}`
+
+func TestNauticalTimeZone(t *testing.T) {
+	for longitude, want := range map[float64]string{0: "UTC+0", 7.4: "UTC+0", 7.6: "UTC+1", -100: "UTC-7", 179: "UTC+12", 181: "UTC-12"} {
+		if zone := NauticalTimeZone(longitude); zone.String() != want {
+			t.Errorf("NauticalTimeZone(%v): %s, want %s", longitude, zone, want)
+		}
+	}
+}
+
+func TestTimeZoneBoundaries(t *testing.T) {
+	boundaries, err := LoadTimeZoneBoundaries(strings.NewReader(testTimeZonesGeoJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		latitude, longitude float64
+		want                string
+	}{
+		{50, 8, "Europe/Berlin"},
+		{47.7, 8.7, "Europe/Busingen"}, // The enclave wins over the surrounding time zone
+		{51.5, 0, "Europe/London"},
+		{58, -4, "Europe/London"}, // The second polygon
+		{40, -4, "UTC+0"},         // Outside of all boundaries
+		{40, 100, "UTC+7"},
+	}
+	for _, tc := range testCases {
+		zone, err := boundaries.TimeZone(tc.latitude, tc.longitude)
+		if err != nil || zone.String() != tc.want {
+			t.Errorf("TimeZone(%v, %v): %v (%v), want %s", tc.latitude, tc.longitude, zone, err, tc.want)
+		}
+	}
+
+	// A zone which is not in the time zone database
+	boundaries.Add("Europe/Atlantis", []Point{{Latitude: 0, Longitude: 0}, {Latitude: 0, Longitude: 1}, {Latitude: 1, Longitude: 1}})
+	if _, err := boundaries.TimeZone(0.1, 0.9); err == nil {
+		t.Error("TimeZone of an unknown time zone: want an error")
+	}
+}
+
+func TestLoadTimeZoneBoundariesErrors(t *testing.T) {
+	testCases := map[string]string{
+		"Invalid JSON": `{"features": [`,
+		"Without tzid": `{"features": [{"properties": {}, "geometry": {"type": "Polygon", "coordinates": []}}]}`,
+		"Point":        `{"features": [{"properties": {"tzid": "Europe/Berlin"}, "geometry": {"type": "Point", "coordinates": [8, 50]}}]}`,
+		"Position":     `{"features": [{"properties": {"tzid": "Europe/Berlin"}, "geometry": {"type": "Polygon", "coordinates": [[[8], [9, 50], [9, 51]]]}}]}`,
+	}
+	for name, geoJSON := range testCases {
+		if _, err := LoadTimeZoneBoundaries(strings.NewReader(geoJSON)); err == nil {
+			t.Errorf("%s: want an error", name)
+		}
+	}
+}
+
+func TestSetLocalTime(t *testing.T) {
+	boundaries, err := LoadTimeZoneBoundaries(strings.NewReader(testTimeZonesGeoJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gpx, err := NewGPX().Track("Berlin").Point(52.5, 13.4, 30, testStart).Point(52.501, 13.4, 30, testStart.Add(time.Minute)).
+		Track("Sea").Point(45, -30, 0, testStart).Point(45.001, -30, 0, testStart.Add(time.Minute)).
+		Build(NewKarney("Karney", EllipsoidWGS84))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gpx.SetLocalTime(nil); err == nil {
+		t.Error("SetLocalTime without a resolver: want an error")
+	}
+	if err := gpx.SetLocalTime(boundaries); err != nil {
+		t.Fatal(err)
+	}
+	// 08:00 UTC is 10:00 in Berlin (summer time) and 06:00 in the nautical time zone UTC-2
+	testCases := []struct {
+		name  string
+		data  *MovementData
+		clock string
+	}{
+		{"GPX", &gpx.MovementStats.OverallData, "10:00 CEST"},
+		{"Track Berlin", &gpx.Tracks[0].MovementStats.MovingData, "10:00 CEST"},
+		{"Segment Berlin", &gpx.Tracks[0].Segments[0].MovementStats.OverallData, "10:00 CEST"},
+		{"Track Sea", &gpx.Tracks[1].MovementStats.OverallData, "06:00 UTC-2"},
+	}
+	for _, tc := range testCases {
+		if !tc.data.LocalStartTime.Valid || tc.data.LocalStartTime.Time.Format("15:04 MST") != tc.clock || !tc.data.LocalStartTime.Time.Equal(testStart) {
+			t.Errorf("%s: Local start time %v, want %s", tc.name, tc.data.LocalStartTime.Time, tc.clock)
+		}
+	}
+}
+
+func TestTimeZoneBoundariesLargeMultiPolygon(t *testing.T) {
+	// A ring over 220 degree of longitude (100°W to 120°E) with a jagged northern border of 20000 teeth between 50°N and 51°N
+	const teeth = 20000
+	toothLongitude := func(i int) float64 {
+		return -100 + 220*float64(i)/teeth
+	}
+	ring := []Point{{Latitude: 40, Longitude: 120}, {Latitude: 40, Longitude: -100}}
+	for i := 0; i <= teeth; i++ {
+		ring = append(ring, Point{Latitude: 50 + float64(i%2), Longitude: toothLongitude(i)})
+	}
+	boundaries := &TimeZoneBoundaries{}
+	boundaries.Add("Asia/Kamchatka", ring)
+	// The polygons on both sides of the antimeridian
+	boundaries.Add("Asia/Kamchatka", []Point{{Latitude: 40, Longitude: 170}, {Latitude: 40, Longitude: 180}, {Latitude: 60, Longitude: 180}, {Latitude: 60, Longitude: 170}})
+	boundaries.Add("Asia/Kamchatka", []Point{{Latitude: 40, Longitude: -180}, {Latitude: 40, Longitude: -170}, {Latitude: 60, Longitude: -170}, {Latitude: 60, Longitude: -180}})
+
+	testCases := []struct {
+		latitude, longitude float64
+		want                string
+	}{
+		{45, 0, "Asia/Kamchatka"},
+		{45, -99, "Asia/Kamchatka"},
+		{45, 119, "Asia/Kamchatka"},
+		{45, 121, "UTC+8"},
+		{50.9, toothLongitude(10001), "Asia/Kamchatka"}, // The tip of a tooth
+		{50.9, toothLongitude(10000), "UTC+1"},          // Between two teeth
+		{50, 150, "UTC+10"},
+		{50, 179.9, "Asia/Kamchatka"},
+		{50, -179.9, "Asia/Kamchatka"},
+		{50, 185, "Asia/Kamchatka"}, // -175
+	}
+	for _, tc := range testCases {
+		zone, err := boundaries.TimeZone(tc.latitude, tc.longitude)
+		if err != nil || zone.String() != tc.want {
+			t.Errorf("TimeZone(%v, %v): %v (%v), want %s", tc.latitude, tc.longitude, zone, err, tc.want)
+		}
+	}
+}
+
+func TestDefaultTimeZoneBoundaries(t *testing.T) {
+	boundaries := DefaultTimeZoneBoundaries()
+	if len(boundaries.boundaries) < 300 {
+		t.Fatalf("DefaultTimeZoneBoundaries: %d time zones, want at least 300", len(boundaries.boundaries))
+	}
+	// All embedded time zones are in the time zone database
+	for _, boundary := range boundaries.boundaries {
+		if _, err := time.LoadLocation(boundary.name); err != nil {
+			t.Errorf("LoadLocation(%s): %v", boundary.name, err)
+		}
+	}
+
+	testCases := []struct {
+		latitude, longitude float64
+		want                string
+	}{
+		{50.0, 8.27, "Europe/Berlin"},  // Mainz
+		{48.57, 7.82, "Europe/Berlin"}, // Kehl
+		{48.58, 7.75, "Europe/Paris"},  // Strasbourg
+		{31.55, 74.34, "Asia/Karachi"}, // Lahore
+		{31.63, 74.87, "Asia/Kolkata"}, // Amritsar
+		{27.72, 85.32, "Asia/Kathmandu"},
+		{23.81, 90.41, "Asia/Dhaka"},
+		{43.12, 131.89, "Asia/Vladivostok"},
+		{40.71, -74.01, "America/New_York"},
+		{31.76, -106.44, "America/Denver"}, // El Paso
+		{-33.87, 151.21, "Australia/Sydney"},
+		{-16.8, -179.97, "Pacific/Fiji"}, // East of the antimeridian
+		{45, -30, "UTC-2"},               // Atlantic Ocean
+	}
+	for _, tc := range testCases {
+		zone, err := boundaries.TimeZone(tc.latitude, tc.longitude)
+		if err != nil || zone.String() != tc.want {
+			t.Errorf("TimeZone(%v, %v): %v (%v), want %s", tc.latitude, tc.longitude, zone, err, tc.want)
+		}
+	}
+}
+
+func TestRecalculateLocalTime(t *testing.T) {
+	// Build recalculates the statistics with the local start time of the DefaultTimeZoneResolver
+	gpx, err := NewGPX().Track("Mainz").Point(50, 8.27, 100, testStart).Point(50.001, 8.27, 100, testStart.Add(time.Minute)).
+		Build(NewKarney("Karney", EllipsoidWGS84))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if localStartTime := gpx.MovementStats.OverallData.LocalStartTime; !localStartTime.Valid || localStartTime.Time.Location().String() != "Europe/Berlin" {
+		t.Errorf("Recalculate: Local start time %v, want the time in Europe/Berlin", localStartTime.Time)
+	}
+
+	defaultResolver := DefaultTimeZoneResolver
+	DefaultTimeZoneResolver = nil
+	defer func() { DefaultTimeZoneResolver = defaultResolver }()
+	Recalculate(gpx, NewKarney("Karney", EllipsoidWGS84))
+	if gpx.MovementStats.OverallData.LocalStartTime.Valid {
+		t.Error("Recalculate without a DefaultTimeZoneResolver: want no local start time")
+	}
+}
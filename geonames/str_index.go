@@ -0,0 +1,165 @@
+// Package geonames resolves the places of locations offline (reverse geocoding, see geo.ReverseGeocoder) by the cities datasets of GeoNames (https://www.geonames.org), e.g. "cities1000.txt"
+package geonames
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mbecker/gpxs/geo"
+)
+
+const (
+	// DefaultCellSize is the default size (degree) of the cells of the spatial index
+	DefaultCellSize = 1.0
+	// DefaultMaxDistance is the default max distance (m) from a location to its place
+	DefaultMaxDistance = 50000.0
+)
+
+// Index is a spatial index of the places of a GeoNames cities dataset and implements the geo.ReverseGeocoder interface:
+// The places are grouped into cells of CellSize x CellSize degree; the nearest place is searched in rings of cells around the cell of the location.
+type Index struct {
+	MaxDistance float64 // The max distance (m) from a location to its place
+
+	cellSize float64
+	cells    map[[2]int][]geo.Place
+}
+
+// NewIndex returns an empty index with cells of the size (degree)
+func NewIndex(cellSize float64) *Index {
+	if cellSize <= 0 {
+		cellSize = DefaultCellSize
+	}
+	return &Index{
+		MaxDistance: DefaultMaxDistance,
+		cellSize:    cellSize,
+		cells:       make(map[[2]int][]geo.Place),
+	}
+}
+
+// cell returns the cell of the latitude / longitude; the longitude is wrapped into [-180, 180)
+func (idx *Index) cell(latitude float64, longitude float64) [2]int {
+	return [2]int{int(math.Floor(latitude / idx.cellSize)), idx.column(int(math.Floor(longitude / idx.cellSize)))}
+}
+
+// column returns the column wrapped into the columns of [-180, 180)
+func (idx *Index) column(column int) int {
+	columns := int(math.Ceil(360 / idx.cellSize))
+	first := int(math.Floor(-180 / idx.cellSize))
+	return first + ((column-first)%columns+columns)%columns
+}
+
+// Add adds the place to the index
+func (idx *Index) Add(place geo.Place) {
+	cell := idx.cell(place.Latitude, place.Longitude)
+	idx.cells[cell] = append(idx.cells[cell], place)
+}
+
+// ReverseGeocode returns the nearest place of the latitude / longitude within the max distance with the distance (m) to the place
+func (idx *Index) ReverseGeocode(latitude float64, longitude float64) (*geo.Place, error) {
+	center := idx.cell(latitude, longitude)
+	var nearest *geo.Place
+	bestDistance := math.Inf(1)
+	maxRing := int(math.Ceil(180 / idx.cellSize))
+	for ring := 0; ring <= maxRing; ring++ {
+		// The min distance of the cells of the ring and beyond: ring - 1 cells between the location and the ring in latitude or in longitude.
+		// The places of the ring are at most ring + 1 cells nearer to the pole than the location; the great circle across the longitudes is at least
+		// the chord at that latitude, which is shorter than the distance in latitude
+		poleward := math.Min(90, math.Abs(latitude)+float64(ring+1)*idx.cellSize)
+		longitudes := math.Min(180, float64(ring-1)*idx.cellSize) * math.Pi / 180
+		minDistance := 2 * geo.EllipsoidSphere.SemiMajorAxisA * math.Sin(longitudes/2) * math.Cos(poleward*math.Pi/180)
+		if minDistance > bestDistance || minDistance > idx.MaxDistance {
+			break
+		}
+		for row := center[0] - ring; row <= center[0]+ring; row++ {
+			for column := center[1] - ring; column <= center[1]+ring; column++ {
+				if row != center[0]-ring && row != center[0]+ring && column != center[1]-ring && column != center[1]+ring {
+					continue // Inside of the ring
+				}
+				places := idx.cells[[2]int{row, idx.column(column)}]
+				for index := range places {
+					distance := geo.HaversineDistance(latitude, longitude, places[index].Latitude, places[index].Longitude, geo.EllipsoidSphere.SemiMajorAxisA)
+					if distance < bestDistance {
+						bestDistance = distance
+						nearest = &places[index]
+					}
+				}
+			}
+		}
+	}
+	if nearest == nil || bestDistance > idx.MaxDistance {
+		return nil, fmt.Errorf("No place within %.0f m of %f, %f", idx.MaxDistance, latitude, longitude)
+	}
+	place := *nearest
+	place.Distance = bestDistance
+	return &place, nil
+}
+
+// Read returns the index of the places of a GeoNames cities dataset (tab-separated: geonameid, name, asciiname, alternatenames, latitude, longitude, feature class, feature code, country code, cc2, admin1 code, ...).
+// The names of the regions are read from the GeoNames "admin1CodesASCII.txt" (tab-separated: country code.admin1 code, name, ...) if admin1 is not nil.
+func Read(cities io.Reader, admin1 io.Reader) (*Index, error) {
+	regions := make(map[string]string)
+	if admin1 != nil {
+		scanner := bufio.NewScanner(admin1)
+		for scanner.Scan() {
+			fields := strings.Split(scanner.Text(), "\t")
+			if len(fields) >= 2 {
+				regions[fields[0]] = fields[1]
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	idx := NewIndex(DefaultCellSize)
+	scanner := bufio.NewScanner(cities)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024) // The alternate names of large cities are long
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) < 11 {
+			return nil, fmt.Errorf("Invalid line %d of the cities: %d fields", lineNo, len(fields))
+		}
+		latitude, errLatitude := strconv.ParseFloat(fields[4], 64)
+		longitude, errLongitude := strconv.ParseFloat(fields[5], 64)
+		if errLatitude != nil || errLongitude != nil {
+			return nil, fmt.Errorf("Invalid coordinates in line %d of the cities", lineNo)
+		}
+		idx.Add(geo.Place{
+			Name:      fields[1],
+			Region:    regions[fields[8]+"."+fields[10]],
+			Country:   fields[8],
+			Latitude:  latitude,
+			Longitude: longitude,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// Load returns the index of the GeoNames cities file (e.g. "cities1000.txt") with the region names of the admin1 file (e.g. "admin1CodesASCII.txt"; optional, may be empty)
+func Load(citiesFile string, admin1File string) (*Index, error) {
+	cities, err := os.Open(citiesFile)
+	if err != nil {
+		return nil, err
+	}
+	defer cities.Close()
+	if len(admin1File) == 0 {
+		return Read(cities, nil)
+	}
+	admin1, err := os.Open(admin1File)
+	if err != nil {
+		return nil, err
+	}
+	defer admin1.Close()
+	return Read(cities, admin1)
+}
@@ -0,0 +1,142 @@
+package geonames
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mbecker/gpxs/geo"
+)
+
+// testCities is a GeoNames cities dataset with the places Mainz, Wiesbaden and Frankfurt
+const testCities = "2874225\tMainz\tMainz\t\t49.98419\t8.2791\tP\tPPLA\tDE\t\t08\n" +
+	"2809346\tWiesbaden\tWiesbaden\t\t50.08258\t8.24932\tP\tPPLA\tDE\t\t05\n" +
+	"\n" +
+	"2925533\tFrankfurt am Main\tFrankfurt am Main\tFrankfurt\t50.11552\t8.68417\tP\tPPLA2\tDE\t\t05\n"
+
+// testAdmin1 are the GeoNames admin1 codes of the regions of the test cities
+const testAdmin1 = "DE.05\tHesse\tHesse\t2905330\nDE.08\tRheinland-Pfalz\tRheinland-Pfalz\t2847618\n"
+
+// testIndex returns an index of the places with the cell size (degree)
+func testIndex(cellSize float64, places ...geo.Place) *Index {
+	idx := NewIndex(cellSize)
+	for _, place := range places {
+		idx.Add(place)
+	}
+	return idx
+}
+
+func TestReverseGeocode(t *testing.T) {
+	idx := testIndex(DefaultCellSize,
+		geo.Place{Name: "South", Latitude: 50.5, Longitude: 8.5},
+		geo.Place{Name: "North", Latitude: 51.01, Longitude: 8.5},
+		geo.Place{Name: "East", Latitude: 0, Longitude: 179.99},
+		geo.Place{Name: "West", Latitude: 0, Longitude: -179.5},
+		geo.Place{Name: "Arctic", Latitude: 70.0, Longitude: 11.99},
+		geo.Place{Name: "Arctic East", Latitude: 70.5, Longitude: 12.05},
+	)
+	testCases := []struct {
+		latitude, longitude float64
+		name                string
+	}{
+		{50.6, 8.5, "South"},
+		{50.99, 8.5, "North"}, // The nearest place is in the next cell
+		{0, -179.99, "East"},  // Across the date line
+		{0, -179.9, "East"},   // Across the date line; the place in the same cell is farther
+		{0, 180, "East"},      // The longitude is wrapped
+		{0, -179.6, "West"},
+		{50.99, 368.5, "North"},     // The longitude is wrapped
+		{50.5, 8.5 + 1e-9, "South"}, // At the place
+		{70.5, 10.9, "Arctic East"}, // The nearest place is two cells east; the place in the same row one cell east is farther
+	}
+	for _, tc := range testCases {
+		place, err := idx.ReverseGeocode(tc.latitude, tc.longitude)
+		if err != nil {
+			t.Errorf("ReverseGeocode(%v, %v): %v", tc.latitude, tc.longitude, err)
+			continue
+		}
+		distance := geo.HaversineDistance(tc.latitude, tc.longitude, place.Latitude, place.Longitude, geo.EllipsoidSphere.SemiMajorAxisA)
+		if place.Name != tc.name || math.Abs(place.Distance-distance) > 1e-6 {
+			t.Errorf("ReverseGeocode(%v, %v): %s in %f m, want %s in %f m", tc.latitude, tc.longitude, place.Name, place.Distance, tc.name, distance)
+		}
+	}
+}
+
+func TestReverseGeocodeMaxDistance(t *testing.T) {
+	// The place is about 35.8 km east of the location; the search spans several rings of small cells
+	idx := testIndex(0.1, geo.Place{Name: "East", Latitude: 50, Longitude: 8.5})
+	if place, err := idx.ReverseGeocode(50, 8); err != nil || place.Name != "East" || math.Abs(place.Distance-35750) > 100 {
+		t.Errorf("ReverseGeocode: %v (%v), want East in about 35750 m", place, err)
+	}
+	idx.MaxDistance = 30000
+	if place, err := idx.ReverseGeocode(50, 8); err == nil {
+		t.Errorf("ReverseGeocode with max distance 30 km: %v, want an error", place)
+	}
+	// A place near the pole
+	idx = testIndex(DefaultCellSize, geo.Place{Name: "Pole", Latitude: 89.9, Longitude: -170})
+	if place, err := idx.ReverseGeocode(89.9, 10); err != nil || place.Name != "Pole" {
+		t.Errorf("ReverseGeocode near the pole: %v (%v), want Pole", place, err)
+	}
+	if place, err := NewIndex(-1).ReverseGeocode(50, 8); err == nil {
+		t.Errorf("ReverseGeocode of an empty index: %v, want an error", place)
+	}
+}
+
+func TestRead(t *testing.T) {
+	idx, err := Read(strings.NewReader(testCities), strings.NewReader(testAdmin1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	place, err := idx.ReverseGeocode(50.05, 8.25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if place.String() != "Wiesbaden, Hesse, DE" || place.Latitude != 50.08258 || place.Longitude != 8.24932 {
+		t.Errorf("ReverseGeocode: %s at %v, %v, want Wiesbaden, Hesse, DE at 50.08258, 8.24932", place.String(), place.Latitude, place.Longitude)
+	}
+	// Without the admin1 codes
+	idx, err = Read(strings.NewReader(testCities), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if place, err := idx.ReverseGeocode(50, 8.3); err != nil || place.Name != "Mainz" || place.Region != "" {
+		t.Errorf("ReverseGeocode without admin1: %v (%v), want Mainz without region", place, err)
+	}
+
+	for name, cities := range map[string]string{
+		"Fields":      "2874225\tMainz\tMainz\n",
+		"Coordinates": "2874225\tMainz\tMainz\t\tnorth\t8.2791\tP\tPPLA\tDE\t\t08\n",
+	} {
+		if _, err := Read(strings.NewReader(cities), nil); err == nil {
+			t.Errorf("%s: want an error", name)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	citiesFile, admin1File := filepath.Join(dir, "cities1000.txt"), filepath.Join(dir, "admin1CodesASCII.txt")
+	if err := os.WriteFile(citiesFile, []byte(testCities), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(admin1File, []byte(testAdmin1), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, admin1 := range []string{admin1File, ""} {
+		idx, err := Load(citiesFile, admin1)
+		if err != nil {
+			t.Errorf("Load(%s): %v", admin1, err)
+			continue
+		}
+		if place, err := idx.ReverseGeocode(50.1, 8.7); err != nil || place.Name != "Frankfurt am Main" {
+			t.Errorf("Load(%s): %v (%v), want Frankfurt am Main", admin1, place, err)
+		}
+	}
+	for _, files := range [][2]string{{filepath.Join(dir, "missing.txt"), ""}, {citiesFile, filepath.Join(dir, "missing.txt")}} {
+		if _, err := Load(files[0], files[1]); err == nil {
+			t.Errorf("Load(%s, %s): want an error", files[0], files[1])
+		}
+	}
+}
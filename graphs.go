@@ -3,33 +3,23 @@ package main
 import (
 	"fmt"
 	"image/color"
-	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/mbecker/gpxs/geo"
-	"github.com/mbecker/gpxs/gpxs"
 	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/plotter"
 	"gonum.org/v1/plot/vg"
 	"gonum.org/v1/plot/vg/draw"
 )
 
-func parseFilesForPlot(gpxFilesDirectory string, graphFilesDirectory string, files []os.FileInfo, alg geo.Algorithm) {
-	for _, file := range files {
-
-		if file.IsDir() == false && filepath.Ext(file.Name()) == ".gpx" {
-			gpxDoc, err := gpxs.ParseFile(filepath.Join(gpxFilesDirectory, file.Name()), alg)
-			if err != nil {
-				panic(err)
-			}
-			var extension = filepath.Ext(file.Name())
-			var name = file.Name()[0 : len(file.Name())-len(extension)]
-			fileName := fmt.Sprintf("%s-graph-%s.png", name, alg.String())
-			graphPath := filepath.Join(graphFilesDirectory, fileName)
-			title := fmt.Sprintf("%s - %s", name, alg.String())
-			createPlot(gpxDoc, title, graphPath)
-		}
+func parseFilesForPlot(graphFilesDirectory string, gpxFiles []gpxFile, alg geo.Algorithm) {
+	for _, file := range gpxFiles {
+		// The statistics of the algorithm are recalculated by parseFiles
+		fileName := fmt.Sprintf("%s-graph-%s.png", file.name, alg.String())
+		graphPath := filepath.Join(graphFilesDirectory, fileName)
+		title := fmt.Sprintf("%s - %s", file.name, alg.String())
+		createPlot(file.gpx, title, graphPath)
 	}
 }
 
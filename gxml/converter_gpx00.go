@@ -1,38 +1,20 @@
 package gxml
 
 import (
-	"fmt"
-	"math"
-	"strings"
-
 	"github.com/mbecker/gpxs/generic"
 	"github.com/mbecker/gpxs/geo"
 )
 
 /* Converter for all baseline xml elements defined in gpx00 */
 
-//Converter00GPX00DocTracks return the geo.GPXTrack
-func Converter00GPX00DocTracks(gpxDoc *geo.GPX, gpx00DocTracks []*GPX00GpxTrk, algorithm geo.Algorithm) {
+//Converter00GPX00DocTracks converts the tracks to gpxDoc.Tracks; the statistics are set by geo.Recalculate
+func Converter00GPX00DocTracks(gpxDoc *geo.GPX, gpx00DocTracks []*GPX00GpxTrk) {
 	if gpx00DocTracks == nil {
 		return
 	}
-	gpxDoc.PointsCount = 0
 	gpxDoc.Tracks = make([]geo.GPXTrack, len(gpx00DocTracks))
-	gpxDoc.MovementStats = geo.MovementStats{
-		OverallData: geo.MovementData{},
-		MovingData:  geo.MovementData{},
-		StoppedData: geo.MovementData{},
-		SD:          geo.SDData{},
-	}
-	// gpxDoc.Points = make([]*geo.GPXPoint, 1000)
 	for trackNo, track := range gpx00DocTracks {
 		gpxTrack := new(geo.GPXTrack)
-		gpxTrack.MovementStats = geo.MovementStats{
-			OverallData: geo.MovementData{},
-			MovingData:  geo.MovementData{},
-			StoppedData: geo.MovementData{},
-			SD:          geo.SDData{},
-		}
 
 		// Get Track name and append it to the gpxTrackNames to return
 		gpxTrack.Name = track.Name
@@ -51,192 +33,23 @@ func Converter00GPX00DocTracks(gpxDoc *geo.GPX, gpx00DocTracks []*GPX00GpxTrk, a
 			gpxTrack.Number = track.Number.Value()
 		}
 
-		// Check Type of Activity: Strava defines the activity type with a nuber ("1" == Cycling, "4" = Hiking, "9" == Running,); other parties like Garmin / Runkeeper has the activity tpye as a descriptive text in the track.Name
+		// The activity type: Strava defines the activity type with a nuber ("1" == Cycling, "4" = Hiking, "9" == Running,); if it is empty geo.Recalculate checks the track name
 		gpxTrack.Type = track.Type
-		if len(gpxTrack.Type) == 0 {
-			activityName := strings.ToLower(track.Name)
-			activityType, err := algorithm.CheckActivityType(activityName)
-			if err == nil {
-				gpxTrack.Type = activityType
-			}
-		}
-		if len(gpxDoc.Type) == 0 {
-			gpxDoc.Type = gpxTrack.Type
-		}
 
 		if track.Segments != nil {
 			gpxTrack.Segments = make([]geo.GPXTrackSegment, len(track.Segments))
-
 			for segmentNo, segment := range track.Segments {
-				gpxSegment := geo.GPXTrackSegment{}
-				gpxSegment.MovementStats = geo.MovementStats{
-					OverallData: geo.MovementData{},
-					MovingData:  geo.MovementData{},
-					StoppedData: geo.MovementData{},
-					SD:          geo.SDData{},
-				}
-
 				if segment.Points != nil {
-					// Make a slice for gpxSegment.Points with the size of the slize segment.Points (xml) to store all GPXPoints
-					gpxSegment.Points = make([]geo.GPXPoint, len(segment.Points))
-
-					// Set the first point in the slice gpxSegment.Points that the second one can use this point for caluclation of distance, duration
-					var prevPoint geo.GPXPoint
-					gpxDoc.PointsCount++
-					gpxSegment.Points[0] = *convertPointFromGpx00(segment.Points[0])
-					gpxSegment.Points[0].IsMoving = true
-
-					// Set the time for gpxDoc, track, segment
-					gpxDoc.MovementStats.OverallData.StartTime.SetTime(gpxSegment.Points[0].Timestamp.Time)
-					gpxDoc.MovementStats.MovingData.StartTime.SetTime(gpxSegment.Points[0].Timestamp.Time)
-
-					gpxTrack.MovementStats.OverallData.StartTime.SetTime(gpxSegment.Points[0].Timestamp.Time)
-					gpxTrack.MovementStats.MovingData.StartTime.SetTime(gpxSegment.Points[0].Timestamp.Time)
-
-					gpxSegment.MovementStats.OverallData.StartTime.SetTime(gpxSegment.Points[0].Timestamp.Time)
-					gpxSegment.MovementStats.MovingData.StartTime.SetTime(gpxSegment.Points[0].Timestamp.Time)
-
-					// Define sumSpeed for Standard Deviation (maybe useful for other algorthm as well?)
-					var sumSpeed float64
-
-					// Loop all points and set the data of each point like duration, distance, speed, etc.
-					for index := 1; index < len(segment.Points); index++ {
-						prevPoint = gpxSegment.Points[index-1]
-
-						// Get XML Point and convert into GPXPoint
-						gpxPoint := *convertPointFromGpx00(segment.Points[index])
-
-						// Set the point data: Duration, Distance, Speed, Pace
-						gpxPoint.Point.SetPointData(&prevPoint.Point, algorithm)
-
-						// ! Important for Standard deviation ! Add the duration / distance to the overall duration to know how long the duration / distance for the all points are (All point == Segment OverallData)
-						sumSpeed += gpxPoint.Speed
-
-						// Add GPXPoint to Slice
-						gpxDoc.PointsCount++
-						gpxSegment.Points[index] = gpxPoint
+					gpxTrack.Segments[segmentNo].Points = make([]geo.GPXPoint, len(segment.Points))
+					for pointNo, point := range segment.Points {
+						gpxTrack.Segments[segmentNo].Points[pointNo] = *convertPointFromGpx00(point)
 					}
-
-					// Create a slice with the length (not capacity!) of the slice gpxSegment.Points to save the pointer to the memory where the the GPXPoint is stored
-					gpxSegment.MovementStats.MovingData.StartTime.SetTime(gpxSegment.Points[0].Timestamp.Time)
-
-					var previousGPXPoint geo.GPXPoint
-
-					if algorithm.ShouldStandardDeviation() {
-						/**
-							STANDARD DEVIATION -- BEGIN
-						**/
-						fmt.Println("Sum Speed: ", sumSpeed)
-						fmt.Println("Len seg points: ", len(gpxSegment.Points))
-
-						// Standard deviation to have a baseline of points for the calculations
-						// 1. Define the mean mu (μ) for a population series: All summed values / count of values
-						μ := sumSpeed / float64(len(gpxSegment.Points))
-						fmt.Println("mu: ", μ)
-
-						// 2.a) Define Deviation for each point: (x1−μ)
-						// 2.b) Square each deviation: (x1−μ)^2
-						// 2.c) Sum all squared deviation from each point
-						var squaredDeviationSum float64 // Sum of all squared deviation from each point
-						for index := 1; index < len(gpxSegment.Points); index++ {
-							point := gpxSegment.Points[index]
-							squaredDeviationSum += math.Pow(point.Speed-μ, 2)
-						}
-
-						// 3. Define the variance of the population: Divide the sum of all squared deviation of each points by the number of the population (in the previous step we used all point except the first one: len(seg.Points)-1)
-						/**
-						 * TODO:
-						 * - [x] Check that "len(gpxSegment.Points)-1" or "len(gpxSegment.Points)" should be used
-						 *			In previous step we are starting from point "1" (not "0") but spedd (distance/duration) is defined as follows: The speed from point0 to point1 is set in point1
-						 */
-						variance := squaredDeviationSum / float64((len(gpxSegment.Points)))
-						fmt.Println("Variance: ", variance)
-
-						// 4. Define the standard deviation
-						standardDeviation := math.Sqrt(variance)
-						fmt.Println("Standard Deviation: ", standardDeviation)
-
-						// 5. Define the the x1 and x2 value in which the points should be (sigma σ defines the range)
-						// If the standardDeviation is Zero then one of the previos parameters == 0 ( here of course "standardDeviation", ... ); that's mostly because too few points in a segment
-						x1 := 0.0
-						x2 := 0.0
-						if standardDeviation > 0 {
-							x1 = μ - algorithm.Sigma()*standardDeviation
-							x2 = μ + algorithm.Sigma()*standardDeviation
-						}
-						fmt.Println("x1: ", x1)
-						fmt.Println("x2: ", x2)
-
-						gpxSegment.MovementStats.SD.Valid = true
-						gpxSegment.MovementStats.SD.X1 = x1
-						gpxSegment.MovementStats.SD.X2 = x2
-
-						// Filter all points which belongs to the standard deviation area
-						for index := 1; index < len(gpxSegment.Points); index++ {
-							previousGPXPoint = gpxSegment.Points[index-1]
-
-							// The point's data is already be set by the previous loop to get the summed speed
-							gpxPoint := gpxSegment.Points[index]
-
-							// pointXValue, _ := algorithm.Speed(gpxPoint.Point.Distance, prevPoint.Point.Duration)
-							// Check if point's Speed above the Standard Deviation
-							// The speed of the point must be of course > 0 to be a moving point
-							// If the SD'x x1 is Zero then one of the previos parameters == 0; that's mostly because too few points in a segment
-							// The statement ' pointXValue <= x2' is not needed because all points above the limit is always oving; means just the point's speed is quite fast
-							if gpxPoint.Speed > 0 && x1 <= gpxPoint.Speed {
-								// Point is in standard deviation area
-								gpxSegment.Points[index].IsMoving = true
-								gpxSegment.MovementStats.MovingData.SetValues(&gpxPoint, &prevPoint, index, algorithm)
-							} else {
-								// Point is not in standard deviation area
-								gpxSegment.Points[index].IsMoving = false
-								gpxSegment.MovementStats.StoppedData.SetValues(&gpxPoint, &prevPoint, index, algorithm)
-							}
-							gpxSegment.MovementStats.OverallData.SetValues(&gpxPoint, &prevPoint, index, algorithm)
-						}
-					} else {
-						/**
-							Do not use Standard Deviation - Begin
-						**/
-						for index := 1; index < len(gpxSegment.Points); index++ {
-							previousGPXPoint = gpxSegment.Points[index-1]
-							gpxPoint := gpxSegment.Points[index]
-
-							// Custom Moving Points
-							err := algorithm.CustomMovingPoints(&gpxPoint, &previousGPXPoint, algorithm)
-							if err != nil {
-								// Error says: Do not use the point for "MovingData"; use the oint for "StoppedData"
-								gpxSegment.Points[index].IsMoving = false
-								gpxSegment.MovementStats.StoppedData.SetValues(&gpxPoint, &prevPoint, index, algorithm)
-							} else {
-								// TODO: the gpxPoint Data should be set by algorithm.CustomMovingPoints
-								gpxSegment.Points[index].IsMoving = true
-								gpxSegment.MovementStats.MovingData.SetValues(&gpxPoint, &prevPoint, index, algorithm)
-							}
-							gpxSegment.MovementStats.OverallData.SetValues(&gpxPoint, &prevPoint, index, algorithm)
-
-						}
-					}
-
-					// Append gpxSegent to gpxTrackSegments
-					gpxTrack.Segments[segmentNo] = gpxSegment
-					gpxTrack.MovementStats.OverallData.SetValuesFromMovementData(&gpxSegment.MovementStats.OverallData, segmentNo, algorithm)
-					gpxTrack.MovementStats.MovingData.SetValuesFromMovementData(&gpxSegment.MovementStats.MovingData, segmentNo, algorithm)
-					gpxTrack.MovementStats.StoppedData.SetValuesFromMovementData(&gpxSegment.MovementStats.StoppedData, segmentNo, algorithm)
-
 				}
 			}
-
 		}
 
 		gpxDoc.Tracks[trackNo] = *gpxTrack
-
-		// gpxDoc MovinngData
-		gpxDoc.MovementStats.OverallData.SetValuesFromMovementData(&gpxTrack.MovementStats.OverallData, trackNo, algorithm)
-		gpxDoc.MovementStats.MovingData.SetValuesFromMovementData(&gpxTrack.MovementStats.MovingData, trackNo, algorithm)
-		gpxDoc.MovementStats.StoppedData.SetValuesFromMovementData(&gpxTrack.MovementStats.StoppedData, trackNo, algorithm)
 	}
-
 }
 
 // Set00GPX00DocWaypoint sets the gpxDoc.Waypoint if the xml has points (GPX00GpxPoint)
@@ -323,16 +136,35 @@ func convertPointFromGpx00(original *GPX00GpxPoint) *geo.GPXPoint {
 	if original.DGpsID != nil {
 		result.DGpsID = *generic.NewNullableInt(*original.DGpsID)
 	}
+	if original.Extensions != nil {
+		if original.Extensions.Power != nil {
+			result.Power = *generic.NewNullableFloat64(*original.Extensions.Power)
+		}
+		if original.Extensions.BarometricAltitude != nil {
+			result.BarometricElevation = *generic.NewNullableFloat64(*original.Extensions.BarometricAltitude)
+		}
+		if trackPointExtension := original.Extensions.TrackPointExtension; trackPointExtension != nil {
+			if trackPointExtension.HeartRate != nil {
+				result.HeartRate = *generic.NewNullableInt(*trackPointExtension.HeartRate)
+			}
+			if trackPointExtension.Cadence != nil {
+				result.Cadence = *generic.NewNullableInt(*trackPointExtension.Cadence)
+			}
+			if trackPointExtension.Atemp != nil {
+				result.Temperature = *generic.NewNullableFloat64(*trackPointExtension.Atemp)
+			}
+		}
+	}
 	return result
 }
 
-func convertPointToGpx00(original *geo.GPXPoint) *GPX00GpxPoint {
+func convertPointToGpx00(original *geo.GPXPoint, precision TimePrecision) *GPX00GpxPoint {
 	result := new(GPX00GpxPoint)
 	result.Lat = original.Latitude
 	result.Lon = original.Longitude
 	result.Ele = original.Elevation
 	if original.Timestamp.Valid {
-		result.Timestamp = formatGPXTime(original.Timestamp.Time)
+		result.Timestamp = formatGPXTime(original.Timestamp.Time, precision)
 	}
 	result.MagVar = original.MagneticVariation
 	result.GeoIdHeight = original.GeoidHeight
@@ -369,5 +201,31 @@ func convertPointToGpx00(original *geo.GPXPoint) *GPX00GpxPoint {
 		value := original.DGpsID.Value()
 		result.DGpsID = &value
 	}
+	if original.HeartRate.NotNull() || original.Cadence.NotNull() || original.Temperature.NotNull() || original.Power.NotNull() || original.BarometricElevation.NotNull() {
+		result.Extensions = &GPX00GpxPointExtensions{}
+		if original.Power.NotNull() {
+			value := original.Power.Value()
+			result.Extensions.Power = &value
+		}
+		if original.BarometricElevation.NotNull() {
+			value := original.BarometricElevation.Value()
+			result.Extensions.BarometricAltitude = &value
+		}
+		if original.HeartRate.NotNull() || original.Cadence.NotNull() || original.Temperature.NotNull() {
+			result.Extensions.TrackPointExtension = &GPX00GpxTrackPointExtension{XMLNs: TrackPointExtensionNs}
+			if original.HeartRate.NotNull() {
+				value := original.HeartRate.Value()
+				result.Extensions.TrackPointExtension.HeartRate = &value
+			}
+			if original.Cadence.NotNull() {
+				value := original.Cadence.Value()
+				result.Extensions.TrackPointExtension.Cadence = &value
+			}
+			if original.Temperature.NotNull() {
+				value := original.Temperature.Value()
+				result.Extensions.TrackPointExtension.Atemp = &value
+			}
+		}
+	}
 	return result
 }
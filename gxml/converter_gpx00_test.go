@@ -0,0 +1,72 @@
+package gxml
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mbecker/gpxs/geo"
+)
+
+// testExtensionsGPX is a gpx with the point extensions written by Garmin and Strava
+const testExtensionsGPX = `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="test" xmlns="http://www.topografix.com/GPX/1/1" xmlns:gpxtpx="http://www.garmin.com/xmlschemas/TrackPointExtension/v1">
+ <trk>
+  <name>Ride</name>
+  <type>1</type>
+  <trkseg>
+   <trkpt lat="50.0" lon="8.0">
+    <ele>100</ele>
+    <time>2020-06-01T08:00:00Z</time>
+    <extensions>
+     <power>210</power>
+     <gpxtpx:TrackPointExtension>
+      <gpxtpx:atemp>21.5</gpxtpx:atemp>
+      <gpxtpx:hr>140</gpxtpx:hr>
+      <gpxtpx:cad>85</gpxtpx:cad>
+     </gpxtpx:TrackPointExtension>
+    </extensions>
+   </trkpt>
+   <trkpt lat="50.001" lon="8.0">
+    <ele>101</ele>
+    <time>2020-06-01T08:00:10Z</time>
+   </trkpt>
+  </trkseg>
+ </trk>
+</gpx>`
+
+func TestPointExtensions(t *testing.T) {
+	gpx, err := ParseString(testExtensionsGPX, geo.NewKarney("Karney", geo.EllipsoidWGS84))
+	if err != nil {
+		t.Fatal(err)
+	}
+	points := gpx.Tracks[0].Segments[0].Points
+	point := points[0]
+	if point.HeartRate.Value() != 140 || point.Cadence.Value() != 85 || point.Power.Value() != 210 {
+		t.Errorf("Extensions: Heart rate %d / cadence %d / power %f, want 140 / 85 / 210", point.HeartRate.Value(), point.Cadence.Value(), point.Power.Value())
+	}
+	if points[1].HeartRate.NotNull() || points[1].Cadence.NotNull() || points[1].Power.NotNull() {
+		t.Error("Point without extensions: want null values")
+	}
+
+	// The extensions are written again; a point without extensions does not get an extensions element
+	bytes, err := ToXML(gpx, ToXmlParams{Version: "1.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	xml := string(bytes)
+	for _, element := range []string{"<power>210</power>", "<hr>140</hr>", "<cad>85</cad>", TrackPointExtensionNs} {
+		if !strings.Contains(xml, element) {
+			t.Errorf("ToXML: %s not found in %s", element, xml)
+		}
+	}
+	if count := strings.Count(xml, "<extensions>"); count != 1 {
+		t.Errorf("ToXML: %d extensions, want 1", count)
+	}
+	parsed, err := ParseBytes(bytes, geo.NewKarney("Karney", geo.EllipsoidWGS84))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if point := parsed.Tracks[0].Segments[0].Points[0]; point.HeartRate.Value() != 140 || point.Cadence.Value() != 85 || point.Power.Value() != 210 {
+		t.Errorf("Parsed again: Heart rate %d / cadence %d / power %f, want 140 / 85 / 210", point.HeartRate.Value(), point.Cadence.Value(), point.Power.Value())
+	}
+}
@@ -18,7 +18,7 @@ const defaultCreator = "https://github.com/tkrajina/gpxgo"
 // Gpx 1.0 Stuff
 // ----------------------------------------------------------------------------------------------------
 
-func convertToGpx10Models(gpxDoc *geo.GPX) *GPX10Gpx {
+func convertToGpx10Models(gpxDoc *geo.GPX, precision TimePrecision) *GPX10Gpx {
 	gpx10Doc := &GPX10Gpx{}
 
 	//gpx10Doc.XMLNs = gpxDoc.XMLNs
@@ -47,7 +47,7 @@ func convertToGpx10Models(gpxDoc *geo.GPX) *GPX10Gpx {
 	}
 
 	if !gpxDoc.Timestamp.IsZero() {
-		gpx10Doc.Time = formatGPXTime(gpxDoc.Timestamp)
+		gpx10Doc.Time = formatGPXTime(gpxDoc.Timestamp, precision)
 	}
 
 	gpx10Doc.Keywords = gpxDoc.Keywords
@@ -55,7 +55,7 @@ func convertToGpx10Models(gpxDoc *geo.GPX) *GPX10Gpx {
 	if gpxDoc.Waypoints != nil {
 		gpx10Doc.Waypoints = make([]*GPX00GpxPoint, len(gpxDoc.Waypoints))
 		for waypointNo, waypoint := range gpxDoc.Waypoints {
-			gpx10Doc.Waypoints[waypointNo] = convertPointToGpx00(&waypoint)
+			gpx10Doc.Waypoints[waypointNo] = convertPointToGpx00(&waypoint, precision)
 		}
 	}
 
@@ -79,7 +79,7 @@ func convertToGpx10Models(gpxDoc *geo.GPX) *GPX10Gpx {
 			if route.Points != nil {
 				r.Points = make([]*GPX00GpxPoint, len(route.Points))
 				for pointNo, point := range route.Points {
-					r.Points[pointNo] = convertPointToGpx00(&point)
+					r.Points[pointNo] = convertPointToGpx00(&point, precision)
 				}
 			}
 		}
@@ -104,7 +104,7 @@ func convertToGpx10Models(gpxDoc *geo.GPX) *GPX10Gpx {
 					if segment.Points != nil {
 						gpx10Segment.Points = make([]*GPX00GpxPoint, len(segment.Points))
 						for pointNo, point := range segment.Points {
-							gpx10Point := convertPointToGpx00(&point)
+							gpx10Point := convertPointToGpx00(&point, precision)
 							// TODO:
 							//gpx10Point.Speed = point.Speed
 							//gpx10Point.Speed = point.Speed
@@ -121,7 +121,7 @@ func convertToGpx10Models(gpxDoc *geo.GPX) *GPX10Gpx {
 	return gpx10Doc
 }
 
-func convertFromGpx10Models(gpx10Doc *GPX10Gpx, algorithm geo.Algorithm) *geo.GPX {
+func convertFromGpx10Models(gpx10Doc *GPX10Gpx) *geo.GPX {
 	gpxDoc := new(geo.GPX)
 
 	gpxDoc.XMLNs = gpx10Doc.XMLNs
@@ -153,7 +153,7 @@ func convertFromGpx10Models(gpx10Doc *GPX10Gpx, algorithm geo.Algorithm) *geo.GP
 	Set00GPX00DocRoutes(gpxDoc, gpx10Doc.Routes)
 
 	// 1.) Copy if exists GPX00Tracks (gpx10Doc.Tracks) to gpxDoc.Tracks; 2.) If the gpxDoc.Name is empty the assign the track name (FIFO)
-	Converter00GPX00DocTracks(gpxDoc, gpx10Doc.Tracks, algorithm)
+	Converter00GPX00DocTracks(gpxDoc, gpx10Doc.Tracks)
 
 	return gpxDoc
 }
@@ -11,7 +11,7 @@ import (
 // Gpx 1.1 Stuff
 // ----------------------------------------------------------------------------------------------------
 
-func convertToGpx11Models(gpxDoc *geo.GPX) *GPX11Gpx {
+func convertToGpx11Models(gpxDoc *geo.GPX, precision TimePrecision) *GPX11Gpx {
 	gpx11Doc := &GPX11Gpx{}
 
 	gpx11Doc.Version = "1.1"
@@ -64,7 +64,7 @@ func convertToGpx11Models(gpxDoc *geo.GPX) *GPX11Gpx {
 	}
 
 	if !gpxDoc.Timestamp.IsZero() {
-		gpx11Doc.Timestamp = formatGPXTime(gpxDoc.Timestamp)
+		gpx11Doc.Timestamp = formatGPXTime(gpxDoc.Timestamp, precision)
 	}
 
 	gpx11Doc.Keywords = gpxDoc.Keywords
@@ -72,7 +72,7 @@ func convertToGpx11Models(gpxDoc *geo.GPX) *GPX11Gpx {
 	if gpxDoc.Waypoints != nil {
 		gpx11Doc.Waypoints = make([]*GPX00GpxPoint, len(gpxDoc.Waypoints))
 		for waypointNo, waypoint := range gpxDoc.Waypoints {
-			gpx11Doc.Waypoints[waypointNo] = convertPointToGpx00(&waypoint)
+			gpx11Doc.Waypoints[waypointNo] = convertPointToGpx00(&waypoint, precision)
 		}
 	}
 
@@ -96,7 +96,7 @@ func convertToGpx11Models(gpxDoc *geo.GPX) *GPX11Gpx {
 			if route.Points != nil {
 				r.Points = make([]*GPX00GpxPoint, len(route.Points))
 				for pointNo, point := range route.Points {
-					r.Points[pointNo] = convertPointToGpx00(&point)
+					r.Points[pointNo] = convertPointToGpx00(&point, precision)
 				}
 			}
 		}
@@ -120,7 +120,7 @@ func convertToGpx11Models(gpxDoc *geo.GPX) *GPX11Gpx {
 					if segment.Points != nil {
 						gpx11Segment.Points = make([]*GPX00GpxPoint, len(segment.Points))
 						for pointNo, point := range segment.Points {
-							gpx11Segment.Points[pointNo] = convertPointToGpx00(&point)
+							gpx11Segment.Points[pointNo] = convertPointToGpx00(&point, precision)
 						}
 					}
 					gpx11Track.Segments[segmentNo] = gpx11Segment
@@ -133,7 +133,7 @@ func convertToGpx11Models(gpxDoc *geo.GPX) *GPX11Gpx {
 	return gpx11Doc
 }
 
-func convertFromGpx11Models(gpx11Doc *GPX11Gpx, algorithm geo.Algorithm) *geo.GPX {
+func convertFromGpx11Models(gpx11Doc *GPX11Gpx) *geo.GPX {
 
 	gpxDoc := new(geo.GPX)
 
@@ -188,7 +188,7 @@ func convertFromGpx11Models(gpx11Doc *GPX11Gpx, algorithm geo.Algorithm) *geo.GP
 	Set00GPX00DocRoutes(gpxDoc, gpx11Doc.Routes)
 
 	// 1.) Copy if exists GPX00Tracks (gpx11Doc.Tracks) to gpxDoc.Tracks; 2.) If the gpxDoc.Name is empty the assign the track name (FIFO)
-	Converter00GPX00DocTracks(gpxDoc, gpx11Doc.Tracks, algorithm)
+	Converter00GPX00DocTracks(gpxDoc, gpx11Doc.Tracks)
 
 	return gpxDoc
 }
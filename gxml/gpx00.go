@@ -23,7 +23,7 @@ type GPX00GpxPoint struct {
 	Ele         generic.NullableFloat64 `xml:"ele,omitempty"`
 	Timestamp   string                  `xml:"time,omitempty"`
 	MagVar      string                  `xml:"magvar,omitempty"`
-	GeoIdHeight string                  `xml:"geoidheight,omitempty"`
+	GeoIdHeight generic.NullableFloat64 `xml:"geoidheight,omitempty"`
 	// Description info
 	Name  string         `xml:"name,omitempty"`
 	Cmt   string         `xml:"cmt,omitempty"`
@@ -40,6 +40,26 @@ type GPX00GpxPoint struct {
 	Pdop          *float64 `xml:"pdop,omitempty"`
 	AgeOfDGpsData *float64 `xml:"ageofdgpsdata,omitempty"`
 	DGpsID        *int     `xml:"dgpsid,omitempty"`
+	// Extensions
+	Extensions *GPX00GpxPointExtensions `xml:"extensions,omitempty"`
+}
+
+// TrackPointExtensionNs is the namespace of the Garmin TrackPointExtension
+const TrackPointExtensionNs = "http://www.garmin.com/xmlschemas/TrackPointExtension/v1"
+
+//GPX00GpxPointExtensions struct fields for the extensions of a point: The Garmin TrackPointExtension, the power (W) as written by Strava and the barometric altitude (m)
+type GPX00GpxPointExtensions struct {
+	TrackPointExtension *GPX00GpxTrackPointExtension `xml:"TrackPointExtension,omitempty"`
+	Power               *float64                     `xml:"power,omitempty"`
+	BarometricAltitude  *float64                     `xml:"baro,omitempty"` // e.g. <extensions><baro>312.4</baro></extensions>
+}
+
+//GPX00GpxTrackPointExtension struct fields for the Garmin TrackPointExtension (any namespace prefix like gpxtpx or ns3)
+type GPX00GpxTrackPointExtension struct {
+	XMLNs     string   `xml:"xmlns,attr,omitempty"`
+	Atemp     *float64 `xml:"atemp,omitempty"`
+	HeartRate *int     `xml:"hr,omitempty"`
+	Cadence   *int     `xml:"cad,omitempty"`
 }
 
 //GPX00GpxRte struct fields for a route
@@ -18,9 +18,10 @@ import (
 )
 
 const (
-	formattingTimelayout = "2006-01-02T15:04:05Z"
-	RFC3339Millis        = "2006-01-02T15:04:05.000Z" // forced microseconds -- https://github.com/tendermint/go-amino/pull/13/files
-	RFC3339Modified      = "2006-01-02T15:04:05Z"
+	formattingTimelayout     = "2006-01-02T15:04:05Z"
+	formattingTimelayoutNano = time.RFC3339Nano           // A time in UTC is formatted with "Z"
+	RFC3339Millis            = "2006-01-02T15:04:05.000Z" // forced microseconds -- https://github.com/tendermint/go-amino/pull/13/files
+	RFC3339Modified          = "2006-01-02T15:04:05Z"
 )
 
 // parsingTimelayouts defines a list of possible time formats
@@ -42,10 +43,23 @@ func init() {
 	*/
 }
 
+// TimePrecision defines the precision of the times in the xml
+type TimePrecision int
+
+const (
+	// TimePrecisionSeconds formats the times with whole seconds, e.g. "2006-01-02T15:04:05Z" (default)
+	TimePrecisionSeconds TimePrecision = iota
+	// TimePrecisionMilliseconds formats the times with milliseconds, e.g. "2006-01-02T15:04:05.000Z"
+	TimePrecisionMilliseconds
+	// TimePrecisionNanoseconds formats the times with the fraction of the second up to nanoseconds without trailing zeros (time.RFC3339Nano), e.g. "2006-01-02T15:04:05.1Z"
+	TimePrecisionNanoseconds
+)
+
 //ToXmlParams contains settings for xml transformation
 type ToXmlParams struct {
-	Version string
-	Indent  bool
+	Version       string
+	Indent        bool
+	TimePrecision TimePrecision // The precision of the times; sub-second recordings (e.g. 10 Hz) should use TimePrecisionMilliseconds or TimePrecisionNanoseconds
 }
 
 //ToXML returns the xml representation of the GPX object.
@@ -59,12 +73,12 @@ func ToXML(g *geo.GPX, params ToXmlParams) ([]byte, error) {
 
 	var gpxDoc interface{}
 	if version == "1.0" {
-		gpxDoc = convertToGpx10Models(g)
+		gpxDoc = convertToGpx10Models(g, params.TimePrecision)
 	} else if version == "1.1" {
-		gpxDoc = convertToGpx11Models(g)
+		gpxDoc = convertToGpx11Models(g, params.TimePrecision)
 	} else {
 		g.Version = "1.1"
-		gpxDoc = convertToGpx11Models(g)
+		gpxDoc = convertToGpx11Models(g, params.TimePrecision)
 	}
 
 	var buffer bytes.Buffer
@@ -112,15 +126,13 @@ func guessGPXVersion(bytes []byte) (string, error) {
 	return result, nil
 }
 
+// parseGPXTime parses the time in UTC with the fraction of the second (up to nanoseconds); a time zone offset like "+02:00" is converted to UTC
 func parseGPXTime(timestr string) (*time.Time, error) {
-	if strings.Contains(timestr, ".") {
-		// Probably seconds with milliseconds
-		timestr = strings.Split(timestr, ".")[0]
-	}
 	timestr = strings.Trim(timestr, " \t\n\r")
 	for _, timeLayout := range parsingTimelayouts {
 		t, err := time.Parse(timeLayout, timestr)
 		if err == nil {
+			t = t.UTC()
 			return &t, nil
 		}
 	}
@@ -128,7 +140,8 @@ func parseGPXTime(timestr string) (*time.Time, error) {
 	return nil, errors.New("Cannot parse " + timestr)
 }
 
-func formatGPXTime(time *time.Time) string {
+// formatGPXTime formats the time in UTC with the precision
+func formatGPXTime(time *time.Time, precision TimePrecision) string {
 	if time == nil {
 		return ""
 	}
@@ -136,7 +149,13 @@ func formatGPXTime(time *time.Time) string {
 		// Invalid date:
 		return ""
 	}
-	return time.Format(formattingTimelayout)
+	switch precision {
+	case TimePrecisionMilliseconds:
+		return time.UTC().Format(RFC3339Millis)
+	case TimePrecisionNanoseconds:
+		return time.UTC().Format(formattingTimelayoutNano)
+	}
+	return time.UTC().Format(formattingTimelayout)
 }
 
 //ParseFile parses a gpx file and returns a GPX object
@@ -171,7 +190,9 @@ func ParseBytes(bytes []byte, algorithm geo.Algorithm) (*geo.GPX, error) {
 			return nil, err
 		}
 
-		return convertFromGpx10Models(g, algorithm), nil
+		gpxDoc := convertFromGpx10Models(g)
+		geo.Recalculate(gpxDoc, algorithm)
+		return gpxDoc, nil
 	} else if version == "1.1" {
 		g := &GPX11Gpx{}
 		err := xml.Unmarshal(bytes, &g)
@@ -179,7 +200,9 @@ func ParseBytes(bytes []byte, algorithm geo.Algorithm) (*geo.GPX, error) {
 			return nil, err
 		}
 
-		return convertFromGpx11Models(g, algorithm), nil
+		gpxDoc := convertFromGpx11Models(g)
+		geo.Recalculate(gpxDoc, algorithm)
+		return gpxDoc, nil
 	} else {
 		return nil, errors.New("Invalid version:" + version)
 	}
@@ -0,0 +1,187 @@
+package gxml
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/mbecker/gpxs/geo"
+)
+
+// testConverterStats are the statistics of Guten_Morgen_Runde.gpx calculated by the converter before the statistics were moved to geo.Recalculate
+type testConverterStats struct {
+	distance, maxSpeed                 float64
+	movingDuration, movingDistance     float64
+	movingSpeed                        float64
+	stoppedDuration, stoppedDistance   float64
+	elevationGain, movingElevationGain float64
+	maxGrade                           float64
+	movingPoints                       int
+	sdX1                               float64
+}
+
+// testStats returns the statistics of the gpx
+func testStats(gpx *geo.GPX) testConverterStats {
+	stats := gpx.MovementStats
+	movingPoints := 0
+	for _, point := range gpx.Tracks[0].Segments[0].Points {
+		if point.IsMoving {
+			movingPoints++
+		}
+	}
+	return testConverterStats{
+		distance:            stats.OverallData.Distance,
+		maxSpeed:            stats.OverallData.MaxSpeed,
+		movingDuration:      stats.MovingData.Duration,
+		movingDistance:      stats.MovingData.Distance,
+		movingSpeed:         stats.MovingData.AverageSpeed,
+		stoppedDuration:     stats.StoppedData.Duration,
+		stoppedDistance:     stats.StoppedData.Distance,
+		elevationGain:       stats.OverallData.ElevationGain,
+		movingElevationGain: stats.MovingData.ElevationGain,
+		maxGrade:            stats.OverallData.MaxGrade,
+		movingPoints:        movingPoints,
+		sdX1:                gpx.Tracks[0].Segments[0].MovementStats.SD.X1,
+	}
+}
+
+// equal returns if the statistics are equal within a relative tolerance
+func (stats testConverterStats) equal(other testConverterStats) bool {
+	values := [][2]float64{
+		{stats.distance, other.distance}, {stats.maxSpeed, other.maxSpeed},
+		{stats.movingDuration, other.movingDuration}, {stats.movingDistance, other.movingDistance}, {stats.movingSpeed, other.movingSpeed},
+		{stats.stoppedDuration, other.stoppedDuration}, {stats.stoppedDistance, other.stoppedDistance},
+		{stats.elevationGain, other.elevationGain}, {stats.movingElevationGain, other.movingElevationGain},
+		{stats.maxGrade, other.maxGrade}, {stats.sdX1, other.sdX1},
+	}
+	for _, value := range values {
+		if math.Abs(value[0]-value[1]) > 1e-9*math.Max(1, math.Abs(value[1])) {
+			return false
+		}
+	}
+	return stats.movingPoints == other.movingPoints
+}
+
+func TestParseFileStatistics(t *testing.T) {
+	vincentySD := geo.NewVincenty("Vincenty SD", geo.EllipsoidWGS84)
+	vincentySD.ShouldStandardDeviationBeUsed = true
+	testCases := []struct {
+		algorithm geo.Algorithm
+		want      testConverterStats
+	}{
+		{geo.NewVincenty("Vincenty", geo.EllipsoidWGS84), testConverterStats{
+			7689.8051975595063, 16.44708599840715, 2202, 7589.416453271735, 3.4466014774167735,
+			224, 100.38874428777264, 60.2, 56.6, 0.18688510508921441, 1803, 0}},
+		{vincentySD, testConverterStats{
+			7689.8051975595063, 16.44708599840715, 2291, 7650.0667433360522, 3.3391823410458543,
+			135, 39.738454223455989, 60.2, 59.7, 0.18688510508921441, 1805, 0.57280175199370698}},
+		{geo.NewAlgorithmGpxgo("Gpxgo", geo.EllipsoidWGS84), testConverterStats{
+			7672.8073979246865, 16.417397487905344, 2202, 7572.658731947321, 3.4389912497490105,
+			224, 100.1486659773654, 60.2, 56.6, 0.18728662118622358, 1803, 0}},
+	}
+
+	gpx, err := ParseFile("../test/gpx_files/Guten_Morgen_Runde.gpx", testCases[0].algorithm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gpx.PointsCount != 1810 || gpx.Type != "9" {
+		t.Errorf("ParseFile: %d points of the type %q, want 1810 of the type 9", gpx.PointsCount, gpx.Type)
+	}
+	for _, tc := range testCases {
+		parsed, err := ParseFile("../test/gpx_files/Guten_Morgen_Runde.gpx", tc.algorithm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stats := testStats(parsed); !stats.equal(tc.want) {
+			t.Errorf("ParseFile with %v: %+v, want %+v", tc.algorithm, stats, tc.want)
+		}
+		// Recalculate the parsed gpx with another algorithm without parsing the file again
+		geo.Recalculate(gpx, tc.algorithm)
+		if stats := testStats(gpx); !stats.equal(tc.want) {
+			t.Errorf("Recalculate with %v: %+v, want %+v", tc.algorithm, stats, tc.want)
+		}
+	}
+}
+
+func TestParseGPXTime(t *testing.T) {
+	want := time.Date(2020, 6, 1, 8, 0, 0, 0, time.UTC)
+	for _, timestr := range []string{"2020-06-01T08:00:00Z", "2020-06-01T10:00:00+02:00", "2020-06-01T03:00:00-05:00", " 2020-06-01T08:00:00Z\n"} {
+		parsed, err := parseGPXTime(timestr)
+		if err != nil || !parsed.Equal(want) || parsed.Location() != time.UTC {
+			t.Errorf("parseGPXTime(%q): %v (%v), want %v", timestr, parsed, err, want)
+		}
+	}
+	local := time.Date(2020, 6, 1, 10, 0, 0, 0, time.FixedZone("CEST", 2*3600))
+	if formatted := formatGPXTime(&local, TimePrecisionSeconds); formatted != "2020-06-01T08:00:00Z" {
+		t.Errorf("formatGPXTime: %s, want 2020-06-01T08:00:00Z", formatted)
+	}
+}
+
+func TestParseLocalStartTime(t *testing.T) {
+	// The start at 08:00 UTC near Mainz is 10:00 in the time zone of the embedded boundaries (see geo.DefaultTimeZoneResolver)
+	gpx, err := ParseString(testExtensionsGPX, geo.NewKarney("Karney", geo.EllipsoidWGS84))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string]*geo.MovementData{"GPX": &gpx.MovementStats.OverallData, "Track": &gpx.Tracks[0].MovementStats.MovingData, "Segment": &gpx.Tracks[0].Segments[0].MovementStats.OverallData} {
+		if !data.LocalStartTime.Valid || data.LocalStartTime.Time.Format("15:04 MST") != "10:00 CEST" {
+			t.Errorf("%s: Local start time %v, want 10:00 CEST", name, data.LocalStartTime.Time)
+		}
+	}
+}
+
+func TestTimePrecision(t *testing.T) {
+	// The fraction of the second is kept
+	parsed, err := parseGPXTime("2020-06-01T10:00:00.1234+02:00")
+	if want := time.Date(2020, 6, 1, 8, 0, 0, 123400000, time.UTC); err != nil || !parsed.Equal(want) {
+		t.Errorf("parseGPXTime: %v (%v), want %v", parsed, err, want)
+	}
+	testCases := map[TimePrecision]string{
+		TimePrecisionSeconds:      "2020-06-01T08:00:00Z",
+		TimePrecisionMilliseconds: "2020-06-01T08:00:00.123Z",
+		TimePrecisionNanoseconds:  "2020-06-01T08:00:00.1234Z",
+	}
+	for precision, want := range testCases {
+		if formatted := formatGPXTime(parsed, precision); formatted != want {
+			t.Errorf("formatGPXTime(%v): %s, want %s", precision, formatted, want)
+		}
+	}
+	if formatted := formatGPXTime(&time.Time{}, TimePrecisionSeconds); formatted != "" {
+		t.Errorf("formatGPXTime of the zero time: %q, want empty", formatted)
+	}
+}
+
+func TestTimePrecisionRoundTrip(t *testing.T) {
+	// A 10 Hz recording
+	alg := geo.NewKarney("Karney", geo.EllipsoidWGS84)
+	builder := geo.NewGPX().Track("Sprint")
+	start := time.Date(2020, 6, 1, 8, 0, 0, 0, time.UTC)
+	for index := 0; index < 11; index++ {
+		builder.Point(50+float64(index)*0.00001, 8, 100, start.Add(time.Duration(index)*100*time.Millisecond))
+	}
+	gpx, err := builder.Build(alg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, version := range []string{"1.0", "1.1"} {
+		for _, precision := range []TimePrecision{TimePrecisionSeconds, TimePrecisionMilliseconds, TimePrecisionNanoseconds} {
+			bytes, err := ToXML(gpx, ToXmlParams{Version: version, TimePrecision: precision})
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed, err := ParseBytes(bytes, alg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			points := parsed.Tracks[0].Segments[0].Points
+			// With whole seconds the points of the same second have the same time
+			want := 0.1
+			if precision == TimePrecisionSeconds {
+				want = 0
+			}
+			if math.Abs(points[1].Duration-want) > 1e-9 || math.Abs(parsed.MovementStats.OverallData.Duration-1) > 1e-9 {
+				t.Errorf("Version %s, precision %v: Duration %f / %f, want %f / 1", version, precision, points[1].Duration, parsed.MovementStats.OverallData.Duration, want)
+			}
+		}
+	}
+}
@@ -33,9 +33,14 @@ func main() {
 		panic(err)
 	}
 
+	// Parse the files once; the statistics of each algorithm are recalculated and the table shows the recalculation time
+	start := time.Now()
+	gpxFiles := parseGPXFiles(fileDirectory, files, algorithms[0])
+	executionTime(start, "Parse files")
+	fmt.Println()
 	for _, alg := range algorithms {
-		parseFiles(fileDirectory, files, alg, tableData)
-		parseFilesForPlot(fileDirectory, fileDirectoryGraphs, files, alg)
+		parseFiles(gpxFiles, alg, tableData)
+		parseFilesForPlot(fileDirectoryGraphs, gpxFiles, alg)
 	}
 
 	table := tablewriter.NewWriter(os.Stdout)
@@ -63,31 +68,27 @@ var algorithms = []geo.Algorithm{
 	&geo.Vincenty{
 		ShouldStandardDeviationBeUsed: false,
 		SigmaMultiplier:               sigmaMultiplier, // ~90%
-		OneDegree:                     1000.0 * 10000.8 / 90.0,
-		EarthRadius:                   6378137, // WGS-84 ellipsoid; See https://en.wikipedia.org/wiki/World_Geodetic_System
-		Flattening:                    1 / 298.257223563,
-		SemiMinorAxisB:                6356752.314245,
-		Epsilon:                       1e-12,
-		MaxIterations:                 200,
+		OneDegree:                     geo.DefaultOneDegree,
+		Ellipsoid:                     geo.EllipsoidWGS84,
+		Epsilon:                       geo.DefaultEpsilon,
+		MaxIterations:                 geo.DefaultMaxIterations,
 		Name:                          "VincentySpeedThreshold",
 	},
 	&geo.Vincenty{
 		ShouldStandardDeviationBeUsed: true,
 		SigmaMultiplier:               sigmaMultiplier, // ~95%
-		OneDegree:                     1000.0 * 10000.8 / 90.0,
-		EarthRadius:                   6378137, // WGS-84 ellipsoid; See https://en.wikipedia.org/wiki/World_Geodetic_System
-		Flattening:                    1 / 298.257223563,
-		SemiMinorAxisB:                6356752.314245,
-		Epsilon:                       1e-12,
-		MaxIterations:                 200,
+		OneDegree:                     geo.DefaultOneDegree,
+		Ellipsoid:                     geo.EllipsoidWGS84,
+		Epsilon:                       geo.DefaultEpsilon,
+		MaxIterations:                 geo.DefaultMaxIterations,
 		Name:                          "VincentySD",
 	},
 	&geo.AlgorithmGpxgo{
 		ShouldStandardDeviationBeUsed: false,
 		SigmaMultiplier:               sigmaMultiplier, // ~95%
 		ShouldHaversine:               false,
-		OneDegree:                     1000.0 * 10000.8 / 90.0,
-		EarthRadius:                   6378137,
+		OneDegree:                     geo.DefaultOneDegree,
+		Ellipsoid:                     geo.EllipsoidWGS84,
 		Should3D:                      false,
 		Name:                          "gpxgoLength2d",
 	},
@@ -95,8 +96,8 @@ var algorithms = []geo.Algorithm{
 		ShouldStandardDeviationBeUsed: true,
 		SigmaMultiplier:               sigmaMultiplier, // ~95%
 		ShouldHaversine:               false,
-		OneDegree:                     1000.0 * 10000.8 / 90.0,
-		EarthRadius:                   6378137,
+		OneDegree:                     geo.DefaultOneDegree,
+		Ellipsoid:                     geo.EllipsoidWGS84,
 		Should3D:                      false,
 		Name:                          "gpxgoLength2dSD",
 	},
@@ -104,8 +105,8 @@ var algorithms = []geo.Algorithm{
 		ShouldStandardDeviationBeUsed: false,
 		SigmaMultiplier:               sigmaMultiplier, // ~95%
 		ShouldHaversine:               false,
-		OneDegree:                     1000.0 * 10000.8 / 90.0,
-		EarthRadius:                   6378137,
+		OneDegree:                     geo.DefaultOneDegree,
+		Ellipsoid:                     geo.EllipsoidWGS84,
 		Should3D:                      true,
 		Name:                          "gpxgoLength3d",
 	},
@@ -113,8 +114,8 @@ var algorithms = []geo.Algorithm{
 		ShouldStandardDeviationBeUsed: true,
 		SigmaMultiplier:               sigmaMultiplier, // ~95%
 		ShouldHaversine:               false,
-		OneDegree:                     1000.0 * 10000.8 / 90.0,
-		EarthRadius:                   6378137,
+		OneDegree:                     geo.DefaultOneDegree,
+		Ellipsoid:                     geo.EllipsoidWGS84,
 		Should3D:                      true,
 		Name:                          "gpxgoLength3dSD",
 	},
@@ -264,11 +265,36 @@ var tableData = [][]string{
 		"------",
 	},
 	[]string{
-		"Execution time",
+		"Recalculation time",
 	},
 }
 
-func parseFiles(fileDirectory string, files []os.FileInfo, alg geo.Algorithm, tableData [][]string) {
+// gpxFile is a parsed gpx file
+type gpxFile struct {
+	name string // The file name without the extension
+	gpx  *geo.GPX
+}
+
+// parseGPXFiles parses the gpx files of the directory with the algorithm
+func parseGPXFiles(fileDirectory string, files []os.FileInfo, alg geo.Algorithm) []gpxFile {
+	var gpxFiles []gpxFile
+	for _, file := range files {
+		if file.IsDir() == false && filepath.Ext(file.Name()) == ".gpx" {
+			gpxDoc, err := gpxs.ParseFile(filepath.Join(fileDirectory, file.Name()), alg)
+			if err != nil {
+				panic(err)
+			}
+			var extension = filepath.Ext(file.Name())
+			gpxFiles = append(gpxFiles, gpxFile{
+				name: file.Name()[0 : len(file.Name())-len(extension)],
+				gpx:  gpxDoc,
+			})
+		}
+	}
+	return gpxFiles
+}
+
+func parseFiles(gpxFiles []gpxFile, alg geo.Algorithm, tableData [][]string) {
 	start := time.Now()
 	var (
 		countFiles      int
@@ -306,59 +332,54 @@ func parseFiles(fileDirectory string, files []os.FileInfo, alg geo.Algorithm, ta
 		gpxDocs []*geo.GPX
 	)
 
-	for _, file := range files {
-
-		if file.IsDir() == false && filepath.Ext(file.Name()) == ".gpx" {
-			gpxDoc, err := gpxs.ParseFile(filepath.Join(fileDirectory, file.Name()), alg)
-			if err != nil {
-				panic(err)
-			}
-
-			gpxDocs = append(gpxDocs, gpxDoc)
-
-			// GPX
-			md := gpxDoc.MovementStats.OverallData
-			distance += md.Distance
-			duration += md.Duration
-			movingDistance += gpxDoc.MovementStats.MovingData.Distance
-			movingTime += gpxDoc.MovementStats.MovingData.Duration
-			stoppedDistance += gpxDoc.MovementStats.StoppedData.Distance
-			stoppedTime += gpxDoc.MovementStats.StoppedData.Duration
-			startTime = gpxDoc.MovementStats.OverallData.StartTime.Time
-			endTime = gpxDoc.MovementStats.OverallData.EndTime.Time
-
-			// Tracks
-			for _, track := range gpxDoc.Tracks {
-				trackMd := track.MovementStats.OverallData
-				trackDistance += trackMd.Distance
-				trackDuration += trackMd.Duration
-				trackMovingDistance += track.MovementStats.MovingData.Distance
-				trackMovingTime += track.MovementStats.MovingData.Duration
-				trackStoppedDistance += track.MovementStats.StoppedData.Distance
-				trackStoppedTime += track.MovementStats.StoppedData.Duration
-				trackStartTime = track.MovementStats.OverallData.StartTime.Time
-				trackEndTime = track.MovementStats.OverallData.EndTime.Time
-
-				// Segments
-				for _, segment := range track.Segments {
-					segmentMd := segment.MovementStats.OverallData
-					segmentDistance += segmentMd.Distance
-					segmentDuration += segmentMd.Duration
-					segmentMovingDistance += segment.MovementStats.MovingData.Distance
-					segmentMovingTime += segment.MovementStats.MovingData.Duration
-					segmentStoppedDistance += segment.MovementStats.StoppedData.Distance
-					segmentStoppedTime += segment.MovementStats.StoppedData.Duration
-					segmentStartTime = segment.MovementStats.OverallData.StartTime.Time
-					segmentEndTime = segment.MovementStats.OverallData.EndTime.Time
-					segmentMaxPace = segment.MovementStats.StoppedData.MaxPace
-					segmentAveragePace = segment.MovementStats.StoppedData.AveragePace
-					segmentMaxSpeed = segment.MovementStats.StoppedData.MaxSpeed
-					segmentAverageSpeed = segment.MovementStats.StoppedData.AverageSpeed
-				}
+	for _, file := range gpxFiles {
+		gpxDoc := file.gpx
+		geo.Recalculate(gpxDoc, alg)
+
+		gpxDocs = append(gpxDocs, gpxDoc)
+
+		// GPX
+		md := gpxDoc.MovementStats.OverallData
+		distance += md.Distance
+		duration += md.Duration
+		movingDistance += gpxDoc.MovementStats.MovingData.Distance
+		movingTime += gpxDoc.MovementStats.MovingData.Duration
+		stoppedDistance += gpxDoc.MovementStats.StoppedData.Distance
+		stoppedTime += gpxDoc.MovementStats.StoppedData.Duration
+		startTime = gpxDoc.MovementStats.OverallData.StartTime.Time
+		endTime = gpxDoc.MovementStats.OverallData.EndTime.Time
+
+		// Tracks
+		for _, track := range gpxDoc.Tracks {
+			trackMd := track.MovementStats.OverallData
+			trackDistance += trackMd.Distance
+			trackDuration += trackMd.Duration
+			trackMovingDistance += track.MovementStats.MovingData.Distance
+			trackMovingTime += track.MovementStats.MovingData.Duration
+			trackStoppedDistance += track.MovementStats.StoppedData.Distance
+			trackStoppedTime += track.MovementStats.StoppedData.Duration
+			trackStartTime = track.MovementStats.OverallData.StartTime.Time
+			trackEndTime = track.MovementStats.OverallData.EndTime.Time
+
+			// Segments
+			for _, segment := range track.Segments {
+				segmentMd := segment.MovementStats.OverallData
+				segmentDistance += segmentMd.Distance
+				segmentDuration += segmentMd.Duration
+				segmentMovingDistance += segment.MovementStats.MovingData.Distance
+				segmentMovingTime += segment.MovementStats.MovingData.Duration
+				segmentStoppedDistance += segment.MovementStats.StoppedData.Distance
+				segmentStoppedTime += segment.MovementStats.StoppedData.Duration
+				segmentStartTime = segment.MovementStats.OverallData.StartTime.Time
+				segmentEndTime = segment.MovementStats.OverallData.EndTime.Time
+				segmentMaxPace = segment.MovementStats.StoppedData.MaxPace
+				segmentAveragePace = segment.MovementStats.StoppedData.AveragePace
+				segmentMaxSpeed = segment.MovementStats.StoppedData.MaxSpeed
+				segmentAverageSpeed = segment.MovementStats.StoppedData.AverageSpeed
 			}
-
-			countFiles++
 		}
+
+		countFiles++
 	}
 	elapsed := time.Since(start)
 	var gpxDocsTypeString string